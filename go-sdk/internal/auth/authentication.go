@@ -26,13 +26,15 @@ type Auth struct {
 	jwt          *gocloak.JWT
 }
 
-func New(logger logr.Logger) *Auth {
-	user := viper.GetString(common.ConfigMinioClientUserKey)
-	password := viper.GetString(common.ConfigMinioClientPasswordKey)
-	authEndpoint := viper.GetString(common.ConfigAuthEndpointKey)
-	realm := viper.GetString(common.ConfigAuthRealmKey)
-	clientID := viper.GetString(common.ConfigAuthClientKey)
-	clientSecret := viper.GetString(common.ConfigAuthClientSecretKey)
+func New(logger logr.Logger, config *viper.Viper) *Auth {
+	config = common.ConfigOrGlobal(config)
+
+	user := config.GetString(common.ConfigMinioClientUserKey)
+	password := config.GetString(common.ConfigMinioClientPasswordKey)
+	authEndpoint := config.GetString(common.ConfigAuthEndpointKey)
+	realm := config.GetString(common.ConfigAuthRealmKey)
+	clientID := config.GetString(common.ConfigAuthClientKey)
+	clientSecret := config.GetString(common.ConfigAuthClientSecretKey)
 
 	return &Auth{
 		logger:       logger,
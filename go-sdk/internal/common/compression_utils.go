@@ -14,7 +14,7 @@ const (
 
 // IsCompressed check if the input string is compressed.
 func IsCompressed(data []byte) bool {
-	return data[0] == gzipID1 && data[1] == gzipID2
+	return len(data) > 1 && data[0] == gzipID1 && data[1] == gzipID2
 }
 
 // CompressData creates compressed []byte.
@@ -0,0 +1,22 @@
+package common
+
+import "github.com/spf13/viper"
+
+// ConfigOrGlobal returns the given configuration, or the global viper instance when it is nil.
+// It is resolved on every call so components built without an explicit configuration keep
+// following the global one.
+func ConfigOrGlobal(config *viper.Viper) *viper.Viper {
+	if config == nil {
+		return viper.GetViper()
+	}
+
+	return config
+}
+
+// IsEnabled reports whether the subsystem toggled by the given key is enabled. Subsystems are
+// enabled unless the key is explicitly set to false.
+func IsEnabled(config *viper.Viper, key string) bool {
+	config = ConfigOrGlobal(config)
+
+	return !config.IsSet(key) || config.GetBool(key)
+}
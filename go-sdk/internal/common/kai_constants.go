@@ -1,44 +1,99 @@
 package common
 
 const (
-	ConfigAppConfigPathKey                = "APP_CONFIG_PATH"
-	ConfigRunnerLoggerLevelKey            = "runner.logger.level"
-	ConfigRunnerLoggerOutputPathsKey      = "runner.logger.output_paths"
-	ConfigRunnerLoggerErrorOutputPathsKey = "runner.logger.error_output_paths"
-	ConfigRunnerLoggerEncodingKey         = "runner.logger.encoding"
-	ConfigRunnerSubscriberAckWaitTimeKey  = "runner.subscriber.ack_wait_time"
-	ConfigMetadataProductIDKey            = "metadata.product_id"
-	ConfigMetadataWorkflowIDKey           = "metadata.workflow_name"
-	ConfigMetadataWorkflowTypeKey         = "metadata.workflow_type"
-	ConfigMetadataProcessIDKey            = "metadata.process_name"
-	ConfigMetadataProcessTypeKey          = "metadata.process_type"
-	ConfigMetadataVersionIDKey            = "metadata.version_tag"
-	ConfigNatsURLKey                      = "nats.url"
-	ConfigNatsStreamKey                   = "nats.stream"
-	ConfigNatsOutputKey                   = "nats.output"
-	ConfigNatsInputsKey                   = "nats.inputs"
-	ConfigNatsEphemeralStorage            = "nats.object_store"
-	ConfigCcGlobalBucketKey               = "centralized_configuration.global.bucket"
-	ConfigCcProductBucketKey              = "centralized_configuration.product.bucket"
-	ConfigCcWorkflowBucketKey             = "centralized_configuration.workflow.bucket"
-	ConfigCcProcessBucketKey              = "centralized_configuration.process.bucket"
-	ConfigMinioEndpointKey                = "minio.endpoint"
-	ConfigMinioClientUserKey              = "minio.client_user"
-	ConfigMinioClientPasswordKey          = "minio.client_password" //nolint:gosec // False positive
-	ConfigMinioUseSslKey                  = "minio.ssl"
-	ConfigMinioBucketKey                  = "minio.bucket"
-	ConfigMinioInternalFolderKey          = "minio.internal_folder"
-	ConfigAuthEndpointKey                 = "auth.endpoint"
-	ConfigAuthClientKey                   = "auth.client"
-	ConfigAuthClientSecretKey             = "auth.client_secret" //nolint:gosec // False positive
-	ConfigAuthRealmKey                    = "auth.realm"
-	ConfigRedisEndpointKey                = "predictions.endpoint"
-	ConfigRedisUsernameKey                = "predictions.username"
-	ConfigRedisPasswordKey                = "predictions.password"
-	ConfigRedisIndexKey                   = "predictions.index"
-	ConfigModelFolderNameKey              = "model_registry.folder_name"
-	ConfigMeasurementsEndpointKey         = "measurements.endpoint"
-	ConfigMeasurementsInsecureKey         = "measurements.insecure"
-	ConfigMeasurementsTimeoutKey          = "measurements.timeout"
-	ConfigMeasurementsMetricsIntervalKey  = "measurements.metrics_interval"
+	ConfigAppConfigPathKey                       = "APP_CONFIG_PATH"
+	ConfigRunnerLoggerLevelKey                   = "runner.logger.level"
+	ConfigRunnerLoggerOutputPathsKey             = "runner.logger.output_paths"
+	ConfigRunnerLoggerErrorOutputPathsKey        = "runner.logger.error_output_paths"
+	ConfigRunnerLoggerEncodingKey                = "runner.logger.encoding"
+	ConfigRunnerSubscriberAckWaitTimeKey         = "runner.subscriber.ack_wait_time"
+	ConfigRunnerSubscriberMaxConcurrencyKey      = "runner.subscriber.max_concurrency"
+	ConfigRunnerSubscriberRetryMaxDeliveriesKey  = "runner.subscriber.retry.max_deliveries"
+	ConfigRunnerSubscriberRetryInitialBackoffKey = "runner.subscriber.retry.initial_backoff"
+	ConfigRunnerSubscriberRetryMaxBackoffKey     = "runner.subscriber.retry.max_backoff"
+	ConfigRunnerSubscriberHandlerTimeoutKey      = "runner.subscriber.handler_timeout"
+	ConfigRunnerSubscriberDeadLetterSubjectKey   = "runner.subscriber.dead_letter_subject"
+	ConfigRunnerSubscriberInactiveThresholdKey   = "runner.subscriber.inactive_threshold"
+	ConfigRunnerSubscriberIdempotencyEnabledKey  = "runner.subscriber.idempotency.enabled"
+	ConfigRunnerSubscriberIdempotencyBucketKey   = "runner.subscriber.idempotency.bucket"
+	ConfigRunnerSubscriberIdempotencyTTLKey      = "runner.subscriber.idempotency.ttl"
+	ConfigRunnerShutdownGracePeriodKey           = "runner.shutdown_grace_period"
+	ConfigRunnerHealthEnabledKey                 = "runner.health.enabled"
+	ConfigRunnerHealthAddressKey                 = "runner.health.address"
+	ConfigRunnerHealthTimeoutKey                 = "runner.health.timeout"
+	ConfigRunnerHealthMinioKey                   = "runner.health.checks.minio"
+	ConfigRunnerHealthRedisKey                   = "runner.health.checks.redis"
+	ConfigMetadataProductIDKey                   = "metadata.product_id"
+	ConfigMetadataWorkflowIDKey                  = "metadata.workflow_name"
+	ConfigMetadataWorkflowTypeKey                = "metadata.workflow_type"
+	ConfigMetadataProcessIDKey                   = "metadata.process_name"
+	ConfigMetadataProcessTypeKey                 = "metadata.process_type"
+	ConfigMetadataVersionIDKey                   = "metadata.version_tag"
+	ConfigNatsURLKey                             = "nats.url"
+	ConfigNatsStreamKey                          = "nats.stream"
+	ConfigNatsOutputKey                          = "nats.output"
+	ConfigNatsInputsKey                          = "nats.inputs"
+	ConfigNatsEphemeralStorage                   = "nats.object_store"
+	ConfigNatsClaimCheckEnabledKey               = "nats.claim_check.enabled"
+	ConfigNatsClaimCheckTTLKey                   = "nats.claim_check.ttl"
+	ConfigNatsClaimCheckSweepIntervalKey         = "nats.claim_check.sweep_interval"
+	ConfigNatsCompressionCodecKey                = "nats.compression.codec"
+	ConfigNatsCompressionLevelKey                = "nats.compression.level"
+	ConfigNatsCompressionThresholdKey            = "nats.compression.threshold"
+	ConfigNatsDeduplicationEnabledKey            = "nats.deduplication.enabled"
+	ConfigCcGlobalBucketKey                      = "centralized_configuration.global.bucket"
+	ConfigCcProductBucketKey                     = "centralized_configuration.product.bucket"
+	ConfigCcWorkflowBucketKey                    = "centralized_configuration.workflow.bucket"
+	ConfigCcProcessBucketKey                     = "centralized_configuration.process.bucket"
+	ConfigCcProcessConfigKey                     = "centralized_configuration.process.config"
+	ConfigMinioEnabledKey                        = "minio.enabled"
+	ConfigMinioEndpointKey                       = "minio.endpoint"
+	ConfigMinioClientUserKey                     = "minio.client_user"
+	ConfigMinioClientPasswordKey                 = "minio.client_password" //nolint:gosec // False positive
+	ConfigMinioUseSslKey                         = "minio.ssl"
+	ConfigMinioBucketKey                         = "minio.bucket"
+	ConfigMinioInternalFolderKey                 = "minio.internal_folder"
+	ConfigAuthEnabledKey                         = "auth.enabled"
+	ConfigAuthEndpointKey                        = "auth.endpoint"
+	ConfigAuthClientKey                          = "auth.client"
+	ConfigAuthClientSecretKey                    = "auth.client_secret" //nolint:gosec // False positive
+	ConfigAuthRealmKey                           = "auth.realm"
+	ConfigRedisEnabledKey                        = "predictions.enabled"
+	ConfigRedisEndpointKey                       = "predictions.endpoint"
+	ConfigRedisUsernameKey                       = "predictions.username"
+	ConfigRedisPasswordKey                       = "predictions.password"
+	ConfigRedisIndexKey                          = "predictions.index"
+	ConfigModelRegistryEnabledKey                = "model_registry.enabled"
+	ConfigModelFolderNameKey                     = "model_registry.folder_name"
+	ConfigMeasurementsEnabledKey                 = "measurements.enabled"
+	ConfigMeasurementsEndpointKey                = "measurements.endpoint"
+	ConfigMeasurementsInsecureKey                = "measurements.insecure"
+	ConfigMeasurementsTimeoutKey                 = "measurements.timeout"
+	ConfigMeasurementsMetricsIntervalKey         = "measurements.metrics_interval"
+	ConfigMeasurementsExporterKey                = "measurements.exporter"
+	ConfigMeasurementsPrometheusAddressKey       = "measurements.prometheus.address"
+	ConfigTracingEnabledKey                      = "tracing.enabled"
+	ConfigTracingExporterKey                     = "tracing.exporter"
+	ConfigTracingEndpointKey                     = "tracing.endpoint"
+	ConfigTracingInsecureKey                     = "tracing.insecure"
+	ConfigTracingTimeoutKey                      = "tracing.timeout"
+)
+
+const (
+	// DeadlineHeader holds the deadline of the request a message belongs to, in RFC 3339 format
+	// with nanoseconds, so every process of the workflow can stop processing it once it is exceeded.
+	DeadlineHeader = "Kai-Deadline"
+	// EndOfStreamHeader marks the last message sent for a request, which carries no payload.
+	EndOfStreamHeader = "Kai-End-Of-Stream"
+	// ClaimCheckHeader holds the name of the object of the ephemeral storage holding a message too
+	// big to be sent, which only carries its request ID, origin and type.
+	ClaimCheckHeader = "Kai-Claim-Check"
+	// ClaimCheckExpiresHeader holds the time, in RFC 3339 format, after which the object of a claim
+	// check is no longer resolved and can be removed.
+	ClaimCheckExpiresHeader = "Kai-Claim-Check-Expires"
+	// ClaimCheckObjectPrefix prefixes the names of the objects of the claim checks, which are hidden
+	// from the ephemeral storage.
+	ClaimCheckObjectPrefix = "kai-claim-check/"
+	// CompressionHeader holds the name of the codec a message is compressed with, if any.
+	CompressionHeader = "Kai-Content-Encoding"
 )
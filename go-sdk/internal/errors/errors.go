@@ -9,6 +9,7 @@ var (
 	ErrUndefinedEphemeralStorage = errors.New("the ephemeral storage does not exist")
 	ErrMessageToBig              = errors.New("compressed message exceeds maximum size allowed")
 	ErrMsgAck                    = "Error in message ack" //nolint:gochecknoglobals // This is a constant
+	ErrMsgNak                    = "Error in message nak" //nolint:gochecknoglobals // This is a constant
 	ErrEmptyPayload              = errors.New("the payload cannot be empty")
 	ErrEmptyModel                = errors.New("the model cannot be empty")
 	ErrModelNotFound             = errors.New("the given model does not exist")
@@ -18,6 +19,7 @@ var (
 	ErrInvalidKey                = errors.New("the key is not valid")
 	ErrEmptyName                 = errors.New("the name cannot be empty")
 	ErrObjectAlreadyExists       = errors.New("object already exists for the given key")
+	ErrSubsystemDisabled         = errors.New("the subsystem is disabled by configuration")
 )
 
 // Wrapper creates a function that returns errors starts with a given message.
@@ -17,23 +17,25 @@ const (
 
 type Storage struct {
 	logger logr.Logger
+	config *viper.Viper
 }
 
-func New(logger logr.Logger) *Storage {
+func New(logger logr.Logger, config *viper.Viper) *Storage {
 	return &Storage{
 		logger: logger,
+		config: common.ConfigOrGlobal(config),
 	}
 }
 
 func (s *Storage) GetStorageClient() (*minio.Client, error) {
-	endpoint := viper.GetString(common.ConfigMinioEndpointKey)
-	useSSL := viper.GetBool(common.ConfigMinioUseSslKey)
+	endpoint := s.config.GetString(common.ConfigMinioEndpointKey)
+	useSSL := s.config.GetBool(common.ConfigMinioUseSslKey)
 	url := ""
 
 	if useSSL {
-		url = fmt.Sprintf("%s://%s", "https", viper.GetString(common.ConfigMinioEndpointKey))
+		url = fmt.Sprintf("%s://%s", "https", endpoint)
 	} else {
-		url = fmt.Sprintf("%s://%s", "http", viper.GetString(common.ConfigMinioEndpointKey))
+		url = fmt.Sprintf("%s://%s", "http", endpoint)
 	}
 
 	minioCredentials, err := s.getClientCredentials(url)
@@ -56,11 +58,21 @@ func (s *Storage) GetStorageClient() (*minio.Client, error) {
 	return minioClient, nil
 }
 
+// getClientCredentials returns credentials issued by the authentication server, or the static
+// MinIO client credentials when authentication is disabled.
 func (s *Storage) getClientCredentials(url string) (*credentials.Credentials, error) {
+	if !common.IsEnabled(s.config, common.ConfigAuthEnabledKey) {
+		return credentials.NewStaticV4(
+			s.config.GetString(common.ConfigMinioClientUserKey),
+			s.config.GetString(common.ConfigMinioClientPasswordKey),
+			"",
+		), nil
+	}
+
 	minioCreds, err := credentials.NewSTSClientGrants(
 		url,
 		func() (*credentials.ClientGrantsToken, error) {
-			authClient := auth.New(s.logger)
+			authClient := auth.New(s.logger, s.config)
 
 			token, err := authClient.GetToken()
 			if err != nil {
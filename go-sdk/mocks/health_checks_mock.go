@@ -0,0 +1,103 @@
+// Code generated by mockery v2.32.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	health "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
+	mock "github.com/stretchr/testify/mock"
+)
+
+// HealthChecksMock is an autogenerated mock type for the healthChecks type
+type HealthChecksMock struct {
+	mock.Mock
+}
+
+type HealthChecksMock_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *HealthChecksMock) EXPECT() *HealthChecksMock_Expecter {
+	return &HealthChecksMock_Expecter{mock: &_m.Mock}
+}
+
+// RegisterLivenessCheck provides a mock function with given fields: name, check
+func (_m *HealthChecksMock) RegisterLivenessCheck(name string, check health.Check) {
+	_m.Called(name, check)
+}
+
+// HealthChecksMock_RegisterLivenessCheck_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterLivenessCheck'
+type HealthChecksMock_RegisterLivenessCheck_Call struct {
+	*mock.Call
+}
+
+// RegisterLivenessCheck is a helper method to define mock.On call
+//   - name string
+//   - check health.Check
+func (_e *HealthChecksMock_Expecter) RegisterLivenessCheck(name interface{}, check interface{}) *HealthChecksMock_RegisterLivenessCheck_Call {
+	return &HealthChecksMock_RegisterLivenessCheck_Call{Call: _e.mock.On("RegisterLivenessCheck", name, check)}
+}
+
+func (_c *HealthChecksMock_RegisterLivenessCheck_Call) Run(run func(name string, check health.Check)) *HealthChecksMock_RegisterLivenessCheck_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(health.Check))
+	})
+	return _c
+}
+
+func (_c *HealthChecksMock_RegisterLivenessCheck_Call) Return() *HealthChecksMock_RegisterLivenessCheck_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *HealthChecksMock_RegisterLivenessCheck_Call) RunAndReturn(run func(string, health.Check)) *HealthChecksMock_RegisterLivenessCheck_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RegisterReadinessCheck provides a mock function with given fields: name, check
+func (_m *HealthChecksMock) RegisterReadinessCheck(name string, check health.Check) {
+	_m.Called(name, check)
+}
+
+// HealthChecksMock_RegisterReadinessCheck_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RegisterReadinessCheck'
+type HealthChecksMock_RegisterReadinessCheck_Call struct {
+	*mock.Call
+}
+
+// RegisterReadinessCheck is a helper method to define mock.On call
+//   - name string
+//   - check health.Check
+func (_e *HealthChecksMock_Expecter) RegisterReadinessCheck(name interface{}, check interface{}) *HealthChecksMock_RegisterReadinessCheck_Call {
+	return &HealthChecksMock_RegisterReadinessCheck_Call{Call: _e.mock.On("RegisterReadinessCheck", name, check)}
+}
+
+func (_c *HealthChecksMock_RegisterReadinessCheck_Call) Run(run func(name string, check health.Check)) *HealthChecksMock_RegisterReadinessCheck_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(health.Check))
+	})
+	return _c
+}
+
+func (_c *HealthChecksMock_RegisterReadinessCheck_Call) Return() *HealthChecksMock_RegisterReadinessCheck_Call {
+	_c.Call.Return()
+	return _c
+}
+
+func (_c *HealthChecksMock_RegisterReadinessCheck_Call) RunAndReturn(run func(string, health.Check)) *HealthChecksMock_RegisterReadinessCheck_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewHealthChecksMock creates a new instance of HealthChecksMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewHealthChecksMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *HealthChecksMock {
+	mock := &HealthChecksMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	context "context"
+
 	mock "github.com/stretchr/testify/mock"
 	metric "go.opentelemetry.io/otel/metric"
 )
@@ -20,6 +22,48 @@ func (_m *MeasurementsMock) EXPECT() *MeasurementsMock_Expecter {
 	return &MeasurementsMock_Expecter{mock: &_m.Mock}
 }
 
+// Flush provides a mock function with given fields: ctx
+func (_m *MeasurementsMock) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MeasurementsMock_Flush_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Flush'
+type MeasurementsMock_Flush_Call struct {
+	*mock.Call
+}
+
+// Flush is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MeasurementsMock_Expecter) Flush(ctx interface{}) *MeasurementsMock_Flush_Call {
+	return &MeasurementsMock_Flush_Call{Call: _e.mock.On("Flush", ctx)}
+}
+
+func (_c *MeasurementsMock_Flush_Call) Run(run func(ctx context.Context)) *MeasurementsMock_Flush_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MeasurementsMock_Flush_Call) Return(_a0 error) *MeasurementsMock_Flush_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MeasurementsMock_Flush_Call) RunAndReturn(run func(context.Context) error) *MeasurementsMock_Flush_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetMetricsClient provides a mock function with given fields:
 func (_m *MeasurementsMock) GetMetricsClient() metric.Meter {
 	ret := _m.Called()
@@ -63,6 +107,48 @@ func (_c *MeasurementsMock_GetMetricsClient_Call) RunAndReturn(run func() metric
 	return _c
 }
 
+// Shutdown provides a mock function with given fields: ctx
+func (_m *MeasurementsMock) Shutdown(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MeasurementsMock_Shutdown_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Shutdown'
+type MeasurementsMock_Shutdown_Call struct {
+	*mock.Call
+}
+
+// Shutdown is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MeasurementsMock_Expecter) Shutdown(ctx interface{}) *MeasurementsMock_Shutdown_Call {
+	return &MeasurementsMock_Shutdown_Call{Call: _e.mock.On("Shutdown", ctx)}
+}
+
+func (_c *MeasurementsMock_Shutdown_Call) Run(run func(ctx context.Context)) *MeasurementsMock_Shutdown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MeasurementsMock_Shutdown_Call) Return(_a0 error) *MeasurementsMock_Shutdown_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MeasurementsMock_Shutdown_Call) RunAndReturn(run func(context.Context) error) *MeasurementsMock_Shutdown_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // NewMeasurementsMock creates a new instance of MeasurementsMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewMeasurementsMock(t interface {
@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	mock "github.com/stretchr/testify/mock"
+	context "context"
+
 	anypb "google.golang.org/protobuf/types/known/anypb"
 
+	mock "github.com/stretchr/testify/mock"
+
 	nats "github.com/nats-io/nats.go"
 
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
@@ -24,6 +27,48 @@ func (_m *MessagingMock) EXPECT() *MessagingMock_Expecter {
 	return &MessagingMock_Expecter{mock: &_m.Mock}
 }
 
+// Flush provides a mock function with given fields: ctx
+func (_m *MessagingMock) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessagingMock_Flush_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Flush'
+type MessagingMock_Flush_Call struct {
+	*mock.Call
+}
+
+// Flush is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *MessagingMock_Expecter) Flush(ctx interface{}) *MessagingMock_Flush_Call {
+	return &MessagingMock_Flush_Call{Call: _e.mock.On("Flush", ctx)}
+}
+
+func (_c *MessagingMock_Flush_Call) Run(run func(ctx context.Context)) *MessagingMock_Flush_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *MessagingMock_Flush_Call) Return(_a0 error) *MessagingMock_Flush_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessagingMock_Flush_Call) RunAndReturn(run func(context.Context) error) *MessagingMock_Flush_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetErrorMessage provides a mock function with given fields:
 func (_m *MessagingMock) GetErrorMessage() string {
 	ret := _m.Called()
@@ -200,7 +245,7 @@ func (_c *MessagingMock_IsMessageOK_Call) RunAndReturn(run func() bool) *Messagi
 }
 
 // SendAny provides a mock function with given fields: response, channelOpt
-func (_m *MessagingMock) SendAny(response *anypb.Any, channelOpt ...string) {
+func (_m *MessagingMock) SendAny(response *anypb.Any, channelOpt ...string) error {
 	_va := make([]interface{}, len(channelOpt))
 	for _i := range channelOpt {
 		_va[_i] = channelOpt[_i]
@@ -208,7 +253,16 @@ func (_m *MessagingMock) SendAny(response *anypb.Any, channelOpt ...string) {
 	var _ca []interface{}
 	_ca = append(_ca, response)
 	_ca = append(_ca, _va...)
-	_m.Called(_ca...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*anypb.Any, ...string) error); ok {
+		r0 = rf(response, channelOpt...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // MessagingMock_SendAny_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendAny'
@@ -237,18 +291,87 @@ func (_c *MessagingMock_SendAny_Call) Run(run func(response *anypb.Any, channelO
 	return _c
 }
 
-func (_c *MessagingMock_SendAny_Call) Return() *MessagingMock_SendAny_Call {
-	_c.Call.Return()
+func (_c *MessagingMock_SendAny_Call) Return(_a0 error) *MessagingMock_SendAny_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MessagingMock_SendAny_Call) RunAndReturn(run func(*anypb.Any, ...string)) *MessagingMock_SendAny_Call {
+func (_c *MessagingMock_SendAny_Call) RunAndReturn(run func(*anypb.Any, ...string) error) *MessagingMock_SendAny_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendAnyAsync provides a mock function with given fields: response, channelOpt
+func (_m *MessagingMock) SendAnyAsync(response *anypb.Any, channelOpt ...string) (nats.PubAckFuture, error) {
+	_va := make([]interface{}, len(channelOpt))
+	for _i := range channelOpt {
+		_va[_i] = channelOpt[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, response)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 nats.PubAckFuture
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*anypb.Any, ...string) (nats.PubAckFuture, error)); ok {
+		return rf(response, channelOpt...)
+	}
+	if rf, ok := ret.Get(0).(func(*anypb.Any, ...string) nats.PubAckFuture); ok {
+		r0 = rf(response, channelOpt...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(nats.PubAckFuture)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*anypb.Any, ...string) error); ok {
+		r1 = rf(response, channelOpt...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessagingMock_SendAnyAsync_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendAnyAsync'
+type MessagingMock_SendAnyAsync_Call struct {
+	*mock.Call
+}
+
+// SendAnyAsync is a helper method to define mock.On call
+//   - response *anypb.Any
+//   - channelOpt ...string
+func (_e *MessagingMock_Expecter) SendAnyAsync(response interface{}, channelOpt ...interface{}) *MessagingMock_SendAnyAsync_Call {
+	return &MessagingMock_SendAnyAsync_Call{Call: _e.mock.On("SendAnyAsync",
+		append([]interface{}{response}, channelOpt...)...)}
+}
+
+func (_c *MessagingMock_SendAnyAsync_Call) Run(run func(response *anypb.Any, channelOpt ...string)) *MessagingMock_SendAnyAsync_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(*anypb.Any), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MessagingMock_SendAnyAsync_Call) Return(_a0 nats.PubAckFuture, _a1 error) *MessagingMock_SendAnyAsync_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessagingMock_SendAnyAsync_Call) RunAndReturn(run func(*anypb.Any, ...string) (nats.PubAckFuture, error)) *MessagingMock_SendAnyAsync_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendAnyWithRequestID provides a mock function with given fields: response, requestID, channelOpt
-func (_m *MessagingMock) SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) {
+func (_m *MessagingMock) SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) error {
 	_va := make([]interface{}, len(channelOpt))
 	for _i := range channelOpt {
 		_va[_i] = channelOpt[_i]
@@ -256,7 +379,16 @@ func (_m *MessagingMock) SendAnyWithRequestID(response *anypb.Any, requestID str
 	var _ca []interface{}
 	_ca = append(_ca, response, requestID)
 	_ca = append(_ca, _va...)
-	_m.Called(_ca...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*anypb.Any, string, ...string) error); ok {
+		r0 = rf(response, requestID, channelOpt...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // MessagingMock_SendAnyWithRequestID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendAnyWithRequestID'
@@ -286,18 +418,73 @@ func (_c *MessagingMock_SendAnyWithRequestID_Call) Run(run func(response *anypb.
 	return _c
 }
 
-func (_c *MessagingMock_SendAnyWithRequestID_Call) Return() *MessagingMock_SendAnyWithRequestID_Call {
-	_c.Call.Return()
+func (_c *MessagingMock_SendAnyWithRequestID_Call) Return(_a0 error) *MessagingMock_SendAnyWithRequestID_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessagingMock_SendAnyWithRequestID_Call) RunAndReturn(run func(*anypb.Any, string, ...string) error) *MessagingMock_SendAnyWithRequestID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SendEndOfStream provides a mock function with given fields: channelOpt
+func (_m *MessagingMock) SendEndOfStream(channelOpt ...string) error {
+	_va := make([]interface{}, len(channelOpt))
+	for _i := range channelOpt {
+		_va[_i] = channelOpt[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...string) error); ok {
+		r0 = rf(channelOpt...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// MessagingMock_SendEndOfStream_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendEndOfStream'
+type MessagingMock_SendEndOfStream_Call struct {
+	*mock.Call
+}
+
+// SendEndOfStream is a helper method to define mock.On call
+//   - channelOpt ...string
+func (_e *MessagingMock_Expecter) SendEndOfStream(channelOpt ...interface{}) *MessagingMock_SendEndOfStream_Call {
+	return &MessagingMock_SendEndOfStream_Call{Call: _e.mock.On("SendEndOfStream",
+		append([]interface{}{}, channelOpt...)...)}
+}
+
+func (_c *MessagingMock_SendEndOfStream_Call) Run(run func(channelOpt ...string)) *MessagingMock_SendEndOfStream_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-0)
+		for i, a := range args[0:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(variadicArgs...)
+	})
 	return _c
 }
 
-func (_c *MessagingMock_SendAnyWithRequestID_Call) RunAndReturn(run func(*anypb.Any, string, ...string)) *MessagingMock_SendAnyWithRequestID_Call {
+func (_c *MessagingMock_SendEndOfStream_Call) Return(_a0 error) *MessagingMock_SendEndOfStream_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *MessagingMock_SendEndOfStream_Call) RunAndReturn(run func(...string) error) *MessagingMock_SendEndOfStream_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
 // SendError provides a mock function with given fields: errorMessage, channelOpt
-func (_m *MessagingMock) SendError(errorMessage string, channelOpt ...string) {
+func (_m *MessagingMock) SendError(errorMessage string, channelOpt ...string) error {
 	_va := make([]interface{}, len(channelOpt))
 	for _i := range channelOpt {
 		_va[_i] = channelOpt[_i]
@@ -305,7 +492,16 @@ func (_m *MessagingMock) SendError(errorMessage string, channelOpt ...string) {
 	var _ca []interface{}
 	_ca = append(_ca, errorMessage)
 	_ca = append(_ca, _va...)
-	_m.Called(_ca...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, ...string) error); ok {
+		r0 = rf(errorMessage, channelOpt...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
 }
 
 // MessagingMock_SendError_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendError'
@@ -334,12 +530,12 @@ func (_c *MessagingMock_SendError_Call) Run(run func(errorMessage string, channe
 	return _c
 }
 
-func (_c *MessagingMock_SendError_Call) Return() *MessagingMock_SendError_Call {
-	_c.Call.Return()
+func (_c *MessagingMock_SendError_Call) Return(_a0 error) *MessagingMock_SendError_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *MessagingMock_SendError_Call) RunAndReturn(run func(string, ...string)) *MessagingMock_SendError_Call {
+func (_c *MessagingMock_SendError_Call) RunAndReturn(run func(string, ...string) error) *MessagingMock_SendError_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -401,6 +597,75 @@ func (_c *MessagingMock_SendOutput_Call) RunAndReturn(run func(protoreflect.Prot
 	return _c
 }
 
+// SendOutputAsync provides a mock function with given fields: response, channelOpt
+func (_m *MessagingMock) SendOutputAsync(response protoreflect.ProtoMessage, channelOpt ...string) (nats.PubAckFuture, error) {
+	_va := make([]interface{}, len(channelOpt))
+	for _i := range channelOpt {
+		_va[_i] = channelOpt[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, response)
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 nats.PubAckFuture
+	var r1 error
+	if rf, ok := ret.Get(0).(func(protoreflect.ProtoMessage, ...string) (nats.PubAckFuture, error)); ok {
+		return rf(response, channelOpt...)
+	}
+	if rf, ok := ret.Get(0).(func(protoreflect.ProtoMessage, ...string) nats.PubAckFuture); ok {
+		r0 = rf(response, channelOpt...)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(nats.PubAckFuture)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(protoreflect.ProtoMessage, ...string) error); ok {
+		r1 = rf(response, channelOpt...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MessagingMock_SendOutputAsync_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendOutputAsync'
+type MessagingMock_SendOutputAsync_Call struct {
+	*mock.Call
+}
+
+// SendOutputAsync is a helper method to define mock.On call
+//   - response protoreflect.ProtoMessage
+//   - channelOpt ...string
+func (_e *MessagingMock_Expecter) SendOutputAsync(response interface{}, channelOpt ...interface{}) *MessagingMock_SendOutputAsync_Call {
+	return &MessagingMock_SendOutputAsync_Call{Call: _e.mock.On("SendOutputAsync",
+		append([]interface{}{response}, channelOpt...)...)}
+}
+
+func (_c *MessagingMock_SendOutputAsync_Call) Run(run func(response protoreflect.ProtoMessage, channelOpt ...string)) *MessagingMock_SendOutputAsync_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		variadicArgs := make([]string, len(args)-1)
+		for i, a := range args[1:] {
+			if a != nil {
+				variadicArgs[i] = a.(string)
+			}
+		}
+		run(args[0].(protoreflect.ProtoMessage), variadicArgs...)
+	})
+	return _c
+}
+
+func (_c *MessagingMock_SendOutputAsync_Call) Return(_a0 nats.PubAckFuture, _a1 error) *MessagingMock_SendOutputAsync_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MessagingMock_SendOutputAsync_Call) RunAndReturn(run func(protoreflect.ProtoMessage, ...string) (nats.PubAckFuture, error)) *MessagingMock_SendOutputAsync_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SendOutputWithRequestID provides a mock function with given fields: response, requestID, channelOpt
 func (_m *MessagingMock) SendOutputWithRequestID(response protoreflect.ProtoMessage, requestID string, channelOpt ...string) error {
 	_va := make([]interface{}, len(channelOpt))
@@ -0,0 +1,165 @@
+// Code generated by mockery v2.32.0. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	trace "go.opentelemetry.io/otel/trace"
+)
+
+// TracerMock is an autogenerated mock type for the tracer type
+type TracerMock struct {
+	mock.Mock
+}
+
+type TracerMock_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *TracerMock) EXPECT() *TracerMock_Expecter {
+	return &TracerMock_Expecter{mock: &_m.Mock}
+}
+
+// Flush provides a mock function with given fields: ctx
+func (_m *TracerMock) Flush(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TracerMock_Flush_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Flush'
+type TracerMock_Flush_Call struct {
+	*mock.Call
+}
+
+// Flush is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *TracerMock_Expecter) Flush(ctx interface{}) *TracerMock_Flush_Call {
+	return &TracerMock_Flush_Call{Call: _e.mock.On("Flush", ctx)}
+}
+
+func (_c *TracerMock_Flush_Call) Run(run func(ctx context.Context)) *TracerMock_Flush_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *TracerMock_Flush_Call) Return(_a0 error) *TracerMock_Flush_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TracerMock_Flush_Call) RunAndReturn(run func(context.Context) error) *TracerMock_Flush_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTracer provides a mock function with given fields:
+func (_m *TracerMock) GetTracer() trace.Tracer {
+	ret := _m.Called()
+
+	var r0 trace.Tracer
+	if rf, ok := ret.Get(0).(func() trace.Tracer); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(trace.Tracer)
+		}
+	}
+
+	return r0
+}
+
+// TracerMock_GetTracer_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTracer'
+type TracerMock_GetTracer_Call struct {
+	*mock.Call
+}
+
+// GetTracer is a helper method to define mock.On call
+func (_e *TracerMock_Expecter) GetTracer() *TracerMock_GetTracer_Call {
+	return &TracerMock_GetTracer_Call{Call: _e.mock.On("GetTracer")}
+}
+
+func (_c *TracerMock_GetTracer_Call) Run(run func()) *TracerMock_GetTracer_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *TracerMock_GetTracer_Call) Return(_a0 trace.Tracer) *TracerMock_GetTracer_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TracerMock_GetTracer_Call) RunAndReturn(run func() trace.Tracer) *TracerMock_GetTracer_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Shutdown provides a mock function with given fields: ctx
+func (_m *TracerMock) Shutdown(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TracerMock_Shutdown_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Shutdown'
+type TracerMock_Shutdown_Call struct {
+	*mock.Call
+}
+
+// Shutdown is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *TracerMock_Expecter) Shutdown(ctx interface{}) *TracerMock_Shutdown_Call {
+	return &TracerMock_Shutdown_Call{Call: _e.mock.On("Shutdown", ctx)}
+}
+
+func (_c *TracerMock_Shutdown_Call) Run(run func(ctx context.Context)) *TracerMock_Shutdown_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *TracerMock_Shutdown_Call) Return(_a0 error) *TracerMock_Shutdown_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TracerMock_Shutdown_Call) RunAndReturn(run func(context.Context) error) *TracerMock_Shutdown_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewTracerMock creates a new instance of TracerMock. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTracerMock(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TracerMock {
+	mock := &TracerMock{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,234 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	kaisdk "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
+)
+
+// ClaimCheckHeader holds the name of the object of the ephemeral storage holding a message too big
+// to be sent.
+const ClaimCheckHeader = internalCommon.ClaimCheckHeader
+
+var (
+	ErrClaimCheckNotFound = errors.New("claim check not found")
+	ErrClaimCheckExpired  = errors.New("claim check expired")
+)
+
+// ResolveClaimCheck returns the message referenced by the claim check of the given one, read from
+// the ephemeral storage, or the request message as is when it carries no claim check. Errors
+// reaching the storage are retryable, unlike missing or expired claim checks.
+func ResolveClaimCheck(js nats.JetStreamContext, config *viper.Viper, msg *nats.Msg,
+	requestMsg *kai.KaiNatsMessage,
+) (*kai.KaiNatsMessage, error) {
+	name := claimCheckName(msg)
+	if name == "" {
+		return requestMsg, nil
+	}
+
+	objectStore, err := getClaimCheckStore(js, config)
+	if err != nil {
+		return requestMsg, kaisdk.RetryableError(err)
+	}
+
+	info, err := objectStore.GetInfo(name)
+	if errors.Is(err, nats.ErrObjectNotFound) {
+		return requestMsg, fmt.Errorf("%w: %s", ErrClaimCheckNotFound, name)
+	}
+
+	if err != nil {
+		return requestMsg, kaisdk.RetryableError(fmt.Errorf("error getting claim check %s: %w", name, err))
+	}
+
+	if isClaimCheckExpired(info, time.Now()) {
+		return requestMsg, fmt.Errorf("%w: %s", ErrClaimCheckExpired, name)
+	}
+
+	data, err := objectStore.GetBytes(name)
+	if err != nil {
+		return requestMsg, kaisdk.RetryableError(fmt.Errorf("error reading claim check %s: %w", name, err))
+	}
+
+	data, err = compression.Decompress(info.Headers, data)
+	if err != nil {
+		return requestMsg, fmt.Errorf("error reading compressed claim check %s: %w", name, err)
+	}
+
+	resolvedMsg := &kai.KaiNatsMessage{}
+	if err := proto.Unmarshal(data, resolvedMsg); err != nil {
+		return requestMsg, fmt.Errorf("error parsing claim check %s: %w", name, err)
+	}
+
+	return resolvedMsg, nil
+}
+
+// ExpiredClaimChecks returns the names of the claim checks of the given ephemeral storage bucket
+// that expired.
+func ExpiredClaimChecks(js nats.JetStreamContext, bucket string) ([]string, error) {
+	objectStore, err := js.ObjectStore(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the ephemeral storage with name %s: %w", bucket, err)
+	}
+
+	return expiredClaimChecks(objectStore, time.Now())
+}
+
+// RemoveExpiredClaimChecks removes the claim checks returned by ExpiredClaimChecks and returns
+// their names. Claim checks removed by someone else in the meantime are ignored.
+func RemoveExpiredClaimChecks(js nats.JetStreamContext, bucket string) ([]string, error) {
+	objectStore, err := js.ObjectStore(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the ephemeral storage with name %s: %w", bucket, err)
+	}
+
+	names, err := expiredClaimChecks(objectStore, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(names))
+
+	var errs []error
+
+	for _, name := range names {
+		err := objectStore.Delete(name)
+		if errors.Is(err, nats.ErrObjectNotFound) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error removing claim check %s: %w", name, err))
+			continue
+		}
+
+		removed = append(removed, name)
+	}
+
+	return removed, errors.Join(errs...)
+}
+
+// StartClaimCheckSweeper removes the expired claim checks of the ephemeral storage of the runner,
+// if any, when it starts and then every nats.claim_check.sweep_interval, unless it is 0, until the
+// returned function is called. Errors are only logged.
+func StartClaimCheckSweeper(logger logr.Logger, js nats.JetStreamContext, config *viper.Viper) (stop func()) {
+	cfg := internalCommon.ConfigOrGlobal(config)
+
+	bucket := cfg.GetString(internalCommon.ConfigNatsEphemeralStorage)
+	if bucket == "" || js == nil {
+		return func() {}
+	}
+
+	removeExpiredClaimChecks(logger, js, bucket)
+
+	interval := cfg.GetDuration(internalCommon.ConfigNatsClaimCheckSweepIntervalKey)
+	if interval <= 0 {
+		return func() {}
+	}
+
+	stopped := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopped:
+				return
+			case <-ticker.C:
+				removeExpiredClaimChecks(logger, js, bucket)
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	// The sweep in progress, if any, finishes before the connection is closed.
+	return func() {
+		once.Do(func() {
+			close(stopped)
+			<-done
+		})
+	}
+}
+
+func removeExpiredClaimChecks(logger logr.Logger, js nats.JetStreamContext, bucket string) {
+	removed, err := RemoveExpiredClaimChecks(js, bucket)
+	if err != nil {
+		logger.Error(err, "Error removing expired claim checks")
+	}
+
+	if len(removed) > 0 {
+		logger.V(1).Info(fmt.Sprintf("Removed %d expired claim checks", len(removed)))
+	}
+}
+
+func claimCheckName(msg *nats.Msg) string {
+	if msg.Header == nil {
+		return ""
+	}
+
+	return msg.Header.Get(ClaimCheckHeader)
+}
+
+func getClaimCheckStore(js nats.JetStreamContext, config *viper.Viper) (nats.ObjectStore, error) {
+	bucket := internalCommon.ConfigOrGlobal(config).GetString(internalCommon.ConfigNatsEphemeralStorage)
+
+	objectStore, err := js.ObjectStore(bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error getting the ephemeral storage with name %s: %w", bucket, err)
+	}
+
+	return objectStore, nil
+}
+
+func expiredClaimChecks(objectStore nats.ObjectStore, now time.Time) ([]string, error) {
+	objects, err := objectStore.List()
+	if errors.Is(err, nats.ErrNoObjectsFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing claim checks: %w", err)
+	}
+
+	var names []string
+
+	for _, info := range objects {
+		if strings.HasPrefix(info.Name, internalCommon.ClaimCheckObjectPrefix) && isClaimCheckExpired(info, now) {
+			names = append(names, info.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// isClaimCheckExpired tells whether the claim check expired at the given time. Claim checks
+// without a valid expiration never expire.
+func isClaimCheckExpired(info *nats.ObjectInfo, now time.Time) bool {
+	if info.Headers == nil {
+		return false
+	}
+
+	expires, err := time.Parse(time.RFC3339Nano, info.Headers.Get(internalCommon.ClaimCheckExpiresHeader))
+	if err != nil {
+		return false
+	}
+
+	return !now.Before(expires)
+}
@@ -0,0 +1,210 @@
+//go:build unit
+
+package common_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const _claimCheckBucket = "ephemeral"
+
+type ClaimCheckTestSuite struct {
+	suite.Suite
+	server      *server.Server
+	nc          *nats.Conn
+	js          nats.JetStreamContext
+	objectStore nats.ObjectStore
+	config      *viper.Viper
+}
+
+func (s *ClaimCheckTestSuite) SetupTest() {
+	var err error
+
+	s.server, err = server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+
+	go s.server.Start()
+
+	s.Require().True(s.server.ReadyForConnections(5 * time.Second))
+
+	s.nc, err = nats.Connect(s.server.ClientURL())
+	s.Require().NoError(err)
+
+	s.js, err = s.nc.JetStream()
+	s.Require().NoError(err)
+
+	s.objectStore, err = s.js.CreateObjectStore(&nats.ObjectStoreConfig{Bucket: _claimCheckBucket})
+	s.Require().NoError(err)
+
+	s.config = viper.New()
+	s.config.Set(internalCommon.ConfigNatsEphemeralStorage, _claimCheckBucket)
+}
+
+func (s *ClaimCheckTestSuite) TearDownTest() {
+	s.nc.Close()
+	s.server.Shutdown()
+}
+
+func (s *ClaimCheckTestSuite) TestResolveClaimCheck_NoClaimCheck_ExpectSameMessage() {
+	// Given
+	requestMsg := &kai.KaiNatsMessage{RequestId: "request-id"}
+
+	// When
+	resolvedMsg, err := common.ResolveClaimCheck(s.js, s.config, nats.NewMsg("subject"), requestMsg)
+
+	// Then
+	s.Require().NoError(err)
+	s.Same(requestMsg, resolvedMsg)
+}
+
+func (s *ClaimCheckTestSuite) TestResolveClaimCheck_ExpectStoredMessage() {
+	// Given
+	storedMsg := &kai.KaiNatsMessage{RequestId: "request-id", FromNode: "task", Error: "stored"}
+	msg := s.newClaimCheckMessage("claim-check", storedMsg, time.Now().Add(time.Hour))
+
+	// When
+	resolvedMsg, err := common.ResolveClaimCheck(s.js, s.config, msg, &kai.KaiNatsMessage{RequestId: "request-id"})
+
+	// Then
+	s.Require().NoError(err)
+	s.True(proto.Equal(storedMsg, resolvedMsg))
+}
+
+func (s *ClaimCheckTestSuite) TestResolveClaimCheck_Missing_ExpectNotRetryableError() {
+	// Given
+	msg := nats.NewMsg("subject")
+	msg.Header.Set(common.ClaimCheckHeader, internalCommon.ClaimCheckObjectPrefix+"missing")
+
+	requestMsg := &kai.KaiNatsMessage{RequestId: "request-id"}
+
+	// When
+	resolvedMsg, err := common.ResolveClaimCheck(s.js, s.config, msg, requestMsg)
+
+	// Then
+	s.ErrorIs(err, common.ErrClaimCheckNotFound)
+	s.False(sdk.IsRetryable(err))
+	s.Same(requestMsg, resolvedMsg)
+}
+
+func (s *ClaimCheckTestSuite) TestResolveClaimCheck_Expired_ExpectError() {
+	// Given
+	msg := s.newClaimCheckMessage("claim-check", &kai.KaiNatsMessage{}, time.Now().Add(-time.Second))
+
+	// When
+	_, err := common.ResolveClaimCheck(s.js, s.config, msg, &kai.KaiNatsMessage{})
+
+	// Then
+	s.ErrorIs(err, common.ErrClaimCheckExpired)
+}
+
+func (s *ClaimCheckTestSuite) TestResolveClaimCheck_ResolvedTwice_ExpectObjectKept() {
+	// Given
+	requestMsg := &kai.KaiNatsMessage{RequestId: "request-id"}
+	msg := s.newClaimCheckMessage("claim-check", requestMsg, time.Now().Add(time.Hour))
+
+	_, err := common.ResolveClaimCheck(s.js, s.config, msg, &kai.KaiNatsMessage{})
+	s.Require().NoError(err)
+
+	// When
+	resolvedMsg, err := common.ResolveClaimCheck(s.js, s.config, msg, &kai.KaiNatsMessage{})
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal("request-id", resolvedMsg.GetRequestId())
+
+	_, err = s.objectStore.GetInfo(msg.Header.Get(common.ClaimCheckHeader))
+	s.NoError(err)
+}
+
+func (s *ClaimCheckTestSuite) TestRemoveExpiredClaimChecks_ExpectOnlyExpiredClaimChecksRemoved() {
+	// Given
+	expired := s.newClaimCheckMessage("expired", &kai.KaiNatsMessage{}, time.Now().Add(-time.Second))
+	s.newClaimCheckMessage("pending", &kai.KaiNatsMessage{}, time.Now().Add(time.Hour))
+
+	_, err := s.objectStore.PutBytes("user-object", []byte("data"))
+	s.Require().NoError(err)
+
+	// When
+	removed, err := common.RemoveExpiredClaimChecks(s.js, _claimCheckBucket)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal([]string{expired.Header.Get(common.ClaimCheckHeader)}, removed)
+
+	remaining, err := common.ExpiredClaimChecks(s.js, _claimCheckBucket)
+	s.Require().NoError(err)
+	s.Empty(remaining)
+
+	objects, err := s.objectStore.List()
+	s.Require().NoError(err)
+	s.Len(objects, 2)
+}
+
+func (s *ClaimCheckTestSuite) TestStartClaimCheckSweeper_ExpectClaimChecksRemovedOnceExpired() {
+	// Given
+	s.config.Set(internalCommon.ConfigNatsClaimCheckSweepIntervalKey, 50*time.Millisecond)
+
+	msg := s.newClaimCheckMessage("expiring", &kai.KaiNatsMessage{}, time.Now().Add(200*time.Millisecond))
+	name := msg.Header.Get(common.ClaimCheckHeader)
+
+	// When
+	stop := common.StartClaimCheckSweeper(logr.Discard(), s.js, s.config)
+	defer stop()
+
+	// Then
+	_, err := s.objectStore.GetInfo(name)
+	s.Require().NoError(err)
+
+	s.Eventually(func() bool {
+		_, err := s.objectStore.GetInfo(name)
+		return errors.Is(err, nats.ErrObjectNotFound)
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// newClaimCheckMessage stores the given message as a claim check expiring at the given time and
+// returns a message referencing it.
+func (s *ClaimCheckTestSuite) newClaimCheckMessage(id string, storedMsg *kai.KaiNatsMessage,
+	expires time.Time,
+) *nats.Msg {
+	data, err := proto.Marshal(storedMsg)
+	s.Require().NoError(err)
+
+	name := internalCommon.ClaimCheckObjectPrefix + id
+
+	_, err = s.objectStore.Put(&nats.ObjectMeta{
+		Name: name,
+		Headers: nats.Header{
+			internalCommon.ClaimCheckExpiresHeader: []string{expires.UTC().Format(time.RFC3339Nano)},
+		},
+	}, bytes.NewReader(data))
+	s.Require().NoError(err)
+
+	msg := nats.NewMsg("subject")
+	msg.Header.Set(common.ClaimCheckHeader, name)
+
+	return msg
+}
+
+func TestClaimCheckTestSuite(t *testing.T) {
+	suite.Run(t, new(ClaimCheckTestSuite))
+}
@@ -1,18 +1,39 @@
 package common
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"regexp"
+	"runtime/debug"
+	"strings"
+	"syscall"
 
+	"github.com/go-logr/logr"
 	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	kaisdk "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
 )
 
 const (
 	_processConfigLoggerName = "[CONFIG INITIALIZER]"
+	_typeURLPrefix           = "type.googleapis.com/"
 )
 
+var (
+	ErrHandlerTimeout = errors.New("handler timed out")
+	ErrHandlerPanic   = errors.New("handler panicked")
+	ErrPayloadType    = errors.New("unexpected payload type")
+)
+
+// _invalidKeyChars matches the characters not allowed in the tokens of the keys of a NATS KV bucket.
+var _invalidKeyChars = regexp.MustCompile(`[^-/_=a-zA-Z0-9]`)
+
 type Task func(sdk kaisdk.KaiSDK)
 
 type Initializer Task
@@ -21,8 +42,67 @@ type Finalizer Task
 
 type Handler func(sdk kaisdk.KaiSDK, response *anypb.Any) error
 
+// Middleware wraps a handler to run code before or after it, or instead of it.
+type Middleware func(next Handler) Handler
+
+// ChainMiddlewares wraps the handler with the given middlewares. The first middleware is the
+// outermost one, so it is the first to run.
+func ChainMiddlewares(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+
+	return handler
+}
+
+// TypedHandler adapts a handler receiving a specific protobuf message into a Handler. The payload
+// is unmarshalled into a new T before calling the handler, and an error wrapping ErrPayloadType
+// is returned without calling it when the payload holds a different message type.
+func TypedHandler[T proto.Message](handler func(sdk kaisdk.KaiSDK, payload T) error) Handler {
+	return func(sdk kaisdk.KaiSDK, response *anypb.Any) error {
+		var zero T
+
+		payload, ok := zero.ProtoReflect().New().Interface().(T)
+		if !ok {
+			return fmt.Errorf("%w: cannot create a %T", ErrPayloadType, zero)
+		}
+
+		if response == nil || !response.MessageIs(payload) {
+			return fmt.Errorf("%w: expected %s, got %q", ErrPayloadType,
+				payload.ProtoReflect().Descriptor().FullName(), response.GetTypeUrl())
+		}
+
+		if err := response.UnmarshalTo(payload); err != nil {
+			return fmt.Errorf("error unmarshalling payload: %w", err)
+		}
+
+		return handler(sdk, payload)
+	}
+}
+
+// TypeURL returns the type URL of the payloads holding the given message type.
+func TypeURL(message proto.Message) string {
+	return _typeURLPrefix + string(message.ProtoReflect().Descriptor().FullName())
+}
+
+// TypeName returns the full name of the message type referenced by the given type URL. Full
+// names are returned unchanged.
+func TypeName(typeURL string) string {
+	if i := strings.LastIndex(typeURL, "/"); i >= 0 {
+		return typeURL[i+1:]
+	}
+
+	return typeURL
+}
+
 func InitializeProcessConfiguration(sdk kaisdk.KaiSDK) {
-	values := viper.GetStringMapString("centralized_configuration.process.config")
+	InitializeProcessConfigurationWithConfig(sdk, nil)
+}
+
+// InitializeProcessConfigurationWithConfig stores the process configuration found in the given viper
+// instance, or in the global one when it is nil, into the process key-value store.
+func InitializeProcessConfigurationWithConfig(sdk kaisdk.KaiSDK, config *viper.Viper) {
+	values := internalCommon.ConfigOrGlobal(config).GetStringMapString(internalCommon.ConfigCcProcessConfigKey)
 
 	sdk.Logger.WithName(_processConfigLoggerName).V(1).Info("Initializing process configuration")
 
@@ -36,3 +116,36 @@ func InitializeProcessConfiguration(sdk kaisdk.KaiSDK) {
 
 	sdk.Logger.WithName(_processConfigLoggerName).V(1).Info("Process configuration initialized")
 }
+
+// RecoverPanic recovers from a panic of the calling goroutine, logs its stack trace and calls
+// onPanic with an error wrapping ErrHandlerPanic. It has to be deferred directly.
+func RecoverPanic(logger logr.Logger, onPanic func(err error)) {
+	if r := recover(); r != nil {
+		err := fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+		logger.Error(err, "Recovered from panic", "stack", string(debug.Stack()))
+		onPanic(err)
+	}
+}
+
+// WaitForShutdown blocks until a SIGINT or SIGTERM signal is received or the given context is done.
+func WaitForShutdown(ctx context.Context) {
+	termChan := make(chan os.Signal, 1)
+	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
+
+	defer signal.Stop(termChan)
+
+	select {
+	case <-termChan:
+	case <-ctx.Done():
+	}
+}
+
+// KeyToken turns the value into a single token of a NATS KV key, replacing the dots, spaces and
+// every other character not allowed in keys with underscores.
+func KeyToken(value string) string {
+	if value == "" {
+		return "_"
+	}
+
+	return _invalidKeyChars.ReplaceAllString(value, "_")
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/go-logr/logr/testr"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/mocks"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
@@ -62,6 +64,61 @@ func (s *RunnerCommonTestSuite) TestInitializeProcessConfiguration_WhenCalledWit
 	s.sdk.CentralizedConfig.(*mocks.CentralizedConfigMock).AssertNotCalled(s.T(), "SetConfig")
 }
 
+func (s *RunnerCommonTestSuite) TestTypedHandler_WhenPayloadMatches_ExpectUnmarshalledPayload() {
+	// Given
+	payload, err := anypb.New(wrapperspb.String("hello"))
+	s.Require().NoError(err)
+
+	var received string
+
+	handler := common.TypedHandler(func(_ sdk.KaiSDK, value *wrapperspb.StringValue) error {
+		received = value.GetValue()
+		return nil
+	})
+
+	// When
+	err = handler(s.sdk, payload)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal("hello", received)
+}
+
+func (s *RunnerCommonTestSuite) TestTypedHandler_WhenPayloadTypeMismatches_ExpectError() {
+	// Given
+	payload, err := anypb.New(wrapperspb.Int32(1))
+	s.Require().NoError(err)
+
+	called := false
+
+	handler := common.TypedHandler(func(_ sdk.KaiSDK, _ *wrapperspb.StringValue) error {
+		called = true
+		return nil
+	})
+
+	// When
+	err = handler(s.sdk, payload)
+
+	// Then
+	s.ErrorIs(err, common.ErrPayloadType)
+	s.Contains(err.Error(), "google.protobuf.StringValue")
+	s.Contains(err.Error(), "google.protobuf.Int32Value")
+	s.False(called)
+}
+
+func (s *RunnerCommonTestSuite) TestTypedHandler_WhenPayloadIsNil_ExpectError() {
+	// Given
+	handler := common.TypedHandler(func(_ sdk.KaiSDK, _ *wrapperspb.StringValue) error {
+		return nil
+	})
+
+	// When
+	err := handler(s.sdk, nil)
+
+	// Then
+	s.ErrorIs(err, common.ErrPayloadType)
+}
+
 func TestRunnerCommonTestSuite(t *testing.T) {
 	suite.Run(t, new(RunnerCommonTestSuite))
 }
@@ -0,0 +1,34 @@
+package common
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+)
+
+// DeadlineHeader holds the deadline of the request a message belongs to.
+const DeadlineHeader = internalCommon.DeadlineHeader
+
+var ErrRequestDeadlineExceeded = errors.New("request deadline exceeded")
+
+// MessageDeadline returns the deadline of the request the message belongs to, if it has a valid one.
+func MessageDeadline(msg *nats.Msg) (time.Time, bool) {
+	if msg.Header == nil {
+		return time.Time{}, false
+	}
+
+	value := msg.Header.Get(DeadlineHeader)
+	if value == "" {
+		return time.Time{}, false
+	}
+
+	deadline, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	return deadline, true
+}
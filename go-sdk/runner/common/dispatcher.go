@@ -0,0 +1,121 @@
+package common
+
+import (
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// _stoppedRedeliveryDelay is how long NATS waits before delivering again a message received once
+// the dispatcher is stopped, so it is not bounced back and forth while the runner shuts down.
+const _stoppedRedeliveryDelay = time.Second
+
+// Dispatcher runs message handlers on at most a given number of goroutines at the same time.
+// Once every worker is busy, the NATS callback blocks until one of them finishes, so no more
+// messages are taken from the subscription than can be processed.
+type Dispatcher struct {
+	workers chan struct{}
+	wg      sync.WaitGroup
+	// mu makes checking stopped and counting the message in wg atomic, so no message starts once
+	// Stop returned and Wait can never miss one.
+	mu      sync.Mutex
+	stopped bool
+}
+
+func NewDispatcher(maxConcurrency int) *Dispatcher {
+	if maxConcurrency < 1 {
+		maxConcurrency = 1
+	}
+
+	return &Dispatcher{
+		workers: make(chan struct{}, maxConcurrency),
+	}
+}
+
+// Dispatch wraps the given handler so every message is processed by one of the workers. With a
+// single worker, messages are processed in order on the NATS callback goroutine.
+func (d *Dispatcher) Dispatch(handler nats.MsgHandler) nats.MsgHandler {
+	if cap(d.workers) == 1 {
+		return func(msg *nats.Msg) {
+			if !d.start(msg) {
+				return
+			}
+
+			defer d.wg.Done()
+
+			handler(msg)
+		}
+	}
+
+	return func(msg *nats.Msg) {
+		d.workers <- struct{}{}
+
+		if !d.start(msg) {
+			<-d.workers
+			return
+		}
+
+		go func() {
+			defer func() {
+				<-d.workers
+				d.wg.Done()
+			}()
+
+			handler(msg)
+		}()
+	}
+}
+
+// Stop makes the dispatcher return every message received from now on to NATS, to be delivered
+// again, instead of processing it. The messages already dispatched are not affected.
+func (d *Dispatcher) Stop() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+}
+
+// Wait blocks until every dispatched message has been processed.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// WaitTimeout blocks until every dispatched message has been processed or the timeout expires, and
+// tells whether they were all processed.
+func (d *Dispatcher) WaitTimeout(timeout time.Duration) bool {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		d.wg.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+// start counts the message as dispatched, unless the dispatcher is stopped, in which case the
+// message is returned to NATS and false is returned.
+func (d *Dispatcher) start(msg *nats.Msg) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if !d.stopped {
+		d.wg.Add(1)
+		return true
+	}
+
+	// There is nothing left to do with the message if it cannot be returned, NATS delivers it
+	// again once its ack wait time expires.
+	_ = msg.NakWithDelay(_stoppedRedeliveryDelay)
+
+	return false
+}
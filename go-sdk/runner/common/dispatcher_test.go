@@ -0,0 +1,181 @@
+//go:build unit
+
+package common_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+)
+
+type DispatcherTestSuite struct {
+	suite.Suite
+}
+
+func (s *DispatcherTestSuite) TestDispatch_MoreMessagesThanWorkers_ExpectBoundedConcurrency() {
+	// Given
+	const (
+		maxConcurrency = 3
+		messages       = 10
+	)
+
+	var (
+		running    atomic.Int32
+		maxRunning atomic.Int32
+		processed  atomic.Int32
+	)
+
+	release := make(chan struct{})
+	started := make(chan struct{}, messages)
+
+	dispatcher := common.NewDispatcher(maxConcurrency)
+	handler := dispatcher.Dispatch(func(_ *nats.Msg) {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+
+		started <- struct{}{}
+		<-release
+
+		running.Add(-1)
+		processed.Add(1)
+	})
+
+	// When
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < messages; i++ {
+			handler(&nats.Msg{})
+		}
+	}()
+
+	for i := 0; i < maxConcurrency; i++ {
+		<-started
+	}
+
+	close(release)
+	wg.Wait()
+	dispatcher.Wait()
+
+	// Then
+	s.Equal(int32(messages), processed.Load())
+	s.Equal(int32(maxConcurrency), maxRunning.Load())
+}
+
+func (s *DispatcherTestSuite) TestDispatch_SingleWorker_ExpectProcessedInOrder() {
+	// Given
+	var processed []string
+
+	dispatcher := common.NewDispatcher(1)
+	handler := dispatcher.Dispatch(func(msg *nats.Msg) {
+		processed = append(processed, msg.Subject)
+	})
+
+	// When
+	handler(&nats.Msg{Subject: "first"})
+	handler(&nats.Msg{Subject: "second"})
+	dispatcher.Wait()
+
+	// Then
+	s.Equal([]string{"first", "second"}, processed)
+}
+
+func (s *DispatcherTestSuite) TestDispatch_Stopped_ExpectMessagesNotProcessed() {
+	for _, maxConcurrency := range []int{1, 3} {
+		// Given
+		var processed atomic.Int32
+
+		dispatcher := common.NewDispatcher(maxConcurrency)
+		handler := dispatcher.Dispatch(func(_ *nats.Msg) {
+			processed.Add(1)
+		})
+
+		// When
+		dispatcher.Stop()
+		handler(&nats.Msg{})
+		dispatcher.Wait()
+
+		// Then
+		s.Zero(processed.Load(), maxConcurrency)
+	}
+}
+
+func (s *DispatcherTestSuite) TestDispatch_StoppedWhileDispatching_ExpectNoMessageProcessedAfterWait() {
+	for _, maxConcurrency := range []int{1, 3} {
+		// Given
+		var (
+			waited        atomic.Bool
+			processedLate atomic.Int32
+			dispatching   sync.WaitGroup
+		)
+
+		dispatcher := common.NewDispatcher(maxConcurrency)
+		handler := dispatcher.Dispatch(func(_ *nats.Msg) {
+			if waited.Load() {
+				processedLate.Add(1)
+			}
+		})
+
+		for i := 0; i < 10; i++ {
+			dispatching.Add(1)
+
+			go func() {
+				defer dispatching.Done()
+
+				for j := 0; j < 100; j++ {
+					handler(&nats.Msg{})
+				}
+			}()
+		}
+
+		// When
+		dispatcher.Stop()
+		dispatcher.Wait()
+		waited.Store(true)
+
+		dispatching.Wait()
+
+		// Then
+		s.Zero(processedLate.Load(), maxConcurrency)
+	}
+}
+
+func (s *DispatcherTestSuite) TestWaitTimeout_ExpectFalseUntilMessagesProcessed() {
+	// Given
+	release := make(chan struct{})
+
+	dispatcher := common.NewDispatcher(2)
+	handler := dispatcher.Dispatch(func(_ *nats.Msg) {
+		<-release
+	})
+
+	handler(&nats.Msg{})
+
+	// When
+	timedOut := !dispatcher.WaitTimeout(10 * time.Millisecond)
+
+	close(release)
+
+	// Then
+	s.True(timedOut)
+	s.True(dispatcher.WaitTimeout(time.Second))
+}
+
+func TestDispatcherTestSuite(t *testing.T) {
+	suite.Run(t, new(DispatcherTestSuite))
+}
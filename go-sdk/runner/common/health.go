@@ -0,0 +1,133 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kaisdk "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
+)
+
+const (
+	_healthLoggerName    = "[HEALTH]"
+	_healthServerTimeout = 5 * time.Second
+	_natsCheckName       = "nats"
+)
+
+var ErrNatsDisconnected = errors.New("NATS connection not connected")
+
+// HealthServer serves the liveness and readiness endpoints of a runner when runner.health.enabled
+// is set. Otherwise, it only keeps the readiness state.
+type HealthServer struct {
+	logger   logr.Logger
+	health   *health.Health
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewHealthServer creates a HealthServer running the checks registered through the SDK besides the
+// one of the NATS connection.
+func NewHealthServer(logger logr.Logger, kaiSDK kaisdk.KaiSDK, nc *nats.Conn, config *viper.Viper) *HealthServer {
+	cfg := internalCommon.ConfigOrGlobal(config)
+
+	healthInst, ok := kaiSDK.Health.(*health.Health)
+	if !ok {
+		healthInst = health.New(cfg.GetDuration(internalCommon.ConfigRunnerHealthTimeoutKey))
+	}
+
+	healthInst.RegisterLivenessCheck(_natsCheckName, natsCheck(nc))
+
+	hs := &HealthServer{
+		logger: logger.WithName(_healthLoggerName),
+		health: healthInst,
+	}
+
+	if cfg.GetBool(internalCommon.ConfigRunnerHealthEnabledKey) {
+		hs.server = &http.Server{
+			Addr:              cfg.GetString(internalCommon.ConfigRunnerHealthAddressKey),
+			Handler:           healthInst.Handler(),
+			ReadHeaderTimeout: _healthServerTimeout,
+		}
+	}
+
+	return hs
+}
+
+// Start listens on the configured address and serves the endpoints in the background.
+func (hs *HealthServer) Start() error {
+	if hs.server == nil {
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", hs.server.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", hs.server.Addr, err)
+	}
+
+	hs.listener = listener
+
+	hs.logger.V(1).Info(fmt.Sprintf("Serving health endpoints on %s", listener.Addr()))
+
+	go func() {
+		if err := hs.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			hs.logger.Error(err, "Error serving health endpoints")
+		}
+	}()
+
+	return nil
+}
+
+// Addr returns the address the endpoints are served on, or an empty string when they are not.
+func (hs *HealthServer) Addr() string {
+	if hs.listener == nil {
+		return ""
+	}
+
+	return hs.listener.Addr().String()
+}
+
+// SetReady marks the runner as ready or not to take messages.
+func (hs *HealthServer) SetReady(ready bool) {
+	hs.health.SetReady(ready)
+
+	hs.logger.V(1).Info(fmt.Sprintf("Runner ready: %t", ready))
+}
+
+// Shutdown stops serving the endpoints. Errors are only logged.
+func (hs *HealthServer) Shutdown() {
+	hs.health.SetReady(false)
+
+	if hs.listener == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), _healthServerTimeout)
+	defer cancel()
+
+	if err := hs.server.Shutdown(ctx); err != nil {
+		hs.logger.Error(err, "Error stopping health endpoints")
+	}
+}
+
+func natsCheck(nc *nats.Conn) health.Check {
+	return func(_ context.Context) error {
+		if nc == nil {
+			return ErrNatsDisconnected
+		}
+
+		if !nc.IsConnected() {
+			return fmt.Errorf("%w: %s", ErrNatsDisconnected, nc.Status())
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,137 @@
+//go:build unit
+
+package common_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+)
+
+type HealthServerTestSuite struct {
+	suite.Suite
+	server *server.Server
+	nc     *nats.Conn
+	config *viper.Viper
+	fake   *sdktest.SDK
+}
+
+func (s *HealthServerTestSuite) SetupTest() {
+	var err error
+
+	s.server, err = server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT})
+	s.Require().NoError(err)
+
+	go s.server.Start()
+
+	s.Require().True(s.server.ReadyForConnections(5 * time.Second))
+
+	s.nc, err = nats.Connect(s.server.ClientURL())
+	s.Require().NoError(err)
+
+	s.config = viper.New()
+	s.config.Set(internalCommon.ConfigRunnerHealthEnabledKey, true)
+	s.config.Set(internalCommon.ConfigRunnerHealthAddressKey, "127.0.0.1:0")
+
+	s.fake = sdktest.New()
+}
+
+func (s *HealthServerTestSuite) TearDownTest() {
+	s.nc.Close()
+	s.server.Shutdown()
+}
+
+func (s *HealthServerTestSuite) TestStart_Disabled_ExpectNotServed() {
+	// Given
+	s.config.Set(internalCommon.ConfigRunnerHealthEnabledKey, false)
+	healthServer := common.NewHealthServer(testr.New(s.T()), s.fake.KaiSDK(), s.nc, s.config)
+
+	// When
+	err := healthServer.Start()
+	defer healthServer.Shutdown()
+
+	// Then
+	s.Require().NoError(err)
+	s.Empty(healthServer.Addr())
+}
+
+func (s *HealthServerTestSuite) TestStart_ExpectReadyOnlyOnceSet() {
+	// Given
+	healthServer := common.NewHealthServer(testr.New(s.T()), s.fake.KaiSDK(), s.nc, s.config)
+
+	s.Require().NoError(healthServer.Start())
+	defer healthServer.Shutdown()
+
+	// When
+	livenessStatus := s.get(healthServer, health.LivenessPath)
+	notReadyStatus := s.get(healthServer, health.ReadinessPath)
+
+	healthServer.SetReady(true)
+
+	readyStatus := s.get(healthServer, health.ReadinessPath)
+
+	// Then
+	s.Equal(http.StatusOK, livenessStatus)
+	s.Equal(http.StatusServiceUnavailable, notReadyStatus)
+	s.Equal(http.StatusOK, readyStatus)
+}
+
+func (s *HealthServerTestSuite) TestStart_NatsDisconnected_ExpectNotAlive() {
+	// Given
+	healthServer := common.NewHealthServer(testr.New(s.T()), s.fake.KaiSDK(), s.nc, s.config)
+
+	s.Require().NoError(healthServer.Start())
+	defer healthServer.Shutdown()
+
+	// When
+	s.nc.Close()
+
+	// Then
+	s.Equal(http.StatusServiceUnavailable, s.get(healthServer, health.LivenessPath))
+}
+
+func (s *HealthServerTestSuite) TestStart_CustomCheckFailing_ExpectNotReady() {
+	// Given
+	kaiSDK := s.fake.KaiSDK()
+	kaiSDK.Health.RegisterReadinessCheck("model", func(_ context.Context) error {
+		return errors.New("model not loaded")
+	})
+
+	healthServer := common.NewHealthServer(testr.New(s.T()), kaiSDK, s.nc, s.config)
+
+	s.Require().NoError(healthServer.Start())
+	defer healthServer.Shutdown()
+
+	// When
+	healthServer.SetReady(true)
+
+	// Then
+	s.Equal(http.StatusOK, s.get(healthServer, health.LivenessPath))
+	s.Equal(http.StatusServiceUnavailable, s.get(healthServer, health.ReadinessPath))
+}
+
+func (s *HealthServerTestSuite) get(healthServer *common.HealthServer, path string) int {
+	response, err := http.Get("http://" + healthServer.Addr() + path) //nolint:noctx // test server
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	return response.StatusCode
+}
+
+func TestHealthServerTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthServerTestSuite))
+}
@@ -0,0 +1,121 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+)
+
+const _idempotencyLoggerName = "[IDEMPOTENCY]"
+
+// IdempotencyGuard remembers the messages processed by a node in a NATS KV bucket, so those
+// delivered again, e.g. after a crash between publishing the outputs and acknowledging the
+// message, are skipped. Messages are forgotten once the TTL of the bucket expires. A guard that is
+// not enabled remembers nothing.
+type IdempotencyGuard struct {
+	logger  logr.Logger
+	kv      nats.KeyValue
+	process string
+}
+
+// NewIdempotencyGuard creates an IdempotencyGuard using the runner.subscriber.idempotency.bucket
+// KV bucket, created with the runner.subscriber.idempotency.ttl TTL when missing, if
+// runner.subscriber.idempotency.enabled is set.
+func NewIdempotencyGuard(logger logr.Logger, js nats.JetStreamContext, config *viper.Viper) (*IdempotencyGuard, error) {
+	cfg := internalCommon.ConfigOrGlobal(config)
+
+	guard := &IdempotencyGuard{
+		logger:  logger.WithName(_idempotencyLoggerName),
+		process: cfg.GetString(internalCommon.ConfigMetadataProcessIDKey),
+	}
+
+	if !cfg.GetBool(internalCommon.ConfigRunnerSubscriberIdempotencyEnabledKey) {
+		return guard, nil
+	}
+
+	bucket := cfg.GetString(internalCommon.ConfigRunnerSubscriberIdempotencyBucketKey)
+
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{
+			Bucket:      bucket,
+			Description: "Messages processed by the nodes of the workflow",
+			TTL:         cfg.GetDuration(internalCommon.ConfigRunnerSubscriberIdempotencyTTLKey),
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting the idempotency bucket with name %s: %w", bucket, err)
+	}
+
+	guard.kv = kv
+
+	return guard, nil
+}
+
+// IsProcessed tells whether the message was already processed by the node. Errors reading the
+// bucket are only logged, and the message is processed again.
+func (g *IdempotencyGuard) IsProcessed(msg *nats.Msg) bool {
+	key, ok := IdempotencyKey(g.process, msg)
+	if g.kv == nil || !ok {
+		return false
+	}
+
+	_, err := g.kv.Get(key)
+	if errors.Is(err, nats.ErrKeyNotFound) {
+		return false
+	}
+
+	if err != nil {
+		g.logger.Error(err, fmt.Sprintf("Error checking whether message %s was already processed", key))
+		return false
+	}
+
+	return true
+}
+
+// MarkProcessed remembers that the message was processed by the node. Errors are only logged.
+func (g *IdempotencyGuard) MarkProcessed(msg *nats.Msg) {
+	key, ok := IdempotencyKey(g.process, msg)
+	if g.kv == nil || !ok {
+		return
+	}
+
+	if _, err := g.kv.Put(key, []byte(time.Now().UTC().Format(time.RFC3339Nano))); err != nil {
+		g.logger.Error(err, fmt.Sprintf("Error marking message %s as processed", key))
+	}
+}
+
+// IdempotencyKey returns the key of the message in the bucket of the given node. Messages are
+// identified by their Nats-Msg-Id header, set by the SDK when deduplication is enabled, or by their
+// position in the stream otherwise. Messages with neither are not identified, and the returned
+// flag is false.
+func IdempotencyKey(process string, msg *nats.Msg) (string, bool) {
+	id := msg.Header.Get(nats.MsgIdHdr)
+	if id == "" {
+		id = HandledMsgID(msg)
+	}
+
+	if id == "" {
+		return "", false
+	}
+
+	return fmt.Sprintf("%s.%s", KeyToken(process), KeyToken(id)), true
+}
+
+// HandledMsgID identifies the message by its stream and sequence in it, which are kept when it is
+// delivered again, or returns an empty string when the message does not come from JetStream.
+func HandledMsgID(msg *nats.Msg) string {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%s-%d", metadata.Stream, metadata.Sequence.Stream)
+}
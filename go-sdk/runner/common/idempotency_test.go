@@ -0,0 +1,196 @@
+//go:build unit
+
+package common_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+)
+
+const _idempotencyBucket = "processed"
+
+type IdempotencyTestSuite struct {
+	suite.Suite
+	server *server.Server
+	nc     *nats.Conn
+	js     nats.JetStreamContext
+	config *viper.Viper
+}
+
+func (s *IdempotencyTestSuite) SetupTest() {
+	var err error
+
+	s.server, err = server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+
+	go s.server.Start()
+
+	s.Require().True(s.server.ReadyForConnections(5 * time.Second))
+
+	s.nc, err = nats.Connect(s.server.ClientURL())
+	s.Require().NoError(err)
+
+	s.js, err = s.nc.JetStream()
+	s.Require().NoError(err)
+
+	s.config = viper.New()
+	s.config.Set(internalCommon.ConfigMetadataProcessIDKey, "task.node")
+	s.config.Set(internalCommon.ConfigRunnerSubscriberIdempotencyEnabledKey, true)
+	s.config.Set(internalCommon.ConfigRunnerSubscriberIdempotencyBucketKey, _idempotencyBucket)
+	s.config.Set(internalCommon.ConfigRunnerSubscriberIdempotencyTTLKey, time.Hour)
+}
+
+func (s *IdempotencyTestSuite) TearDownTest() {
+	s.nc.Close()
+	s.server.Shutdown()
+}
+
+func (s *IdempotencyTestSuite) TestNewIdempotencyGuard_MissingBucket_ExpectBucketCreatedWithTTL() {
+	// When
+	_, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, s.config)
+
+	// Then
+	s.Require().NoError(err)
+
+	kv, err := s.js.KeyValue(_idempotencyBucket)
+	s.Require().NoError(err)
+
+	status, err := kv.Status()
+	s.Require().NoError(err)
+	s.Equal(time.Hour, status.TTL())
+}
+
+func (s *IdempotencyTestSuite) TestIdempotencyGuard_MarkProcessed_ExpectProcessedOnlyForThatMessage() {
+	// Given
+	guard, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, s.config)
+	s.Require().NoError(err)
+
+	msg := newIdempotentMessage("request-id/exit/ /0")
+	otherMsg := newIdempotentMessage("request-id/exit/ /1")
+
+	// When
+	guard.MarkProcessed(msg)
+
+	// Then
+	s.True(guard.IsProcessed(msg))
+	s.False(guard.IsProcessed(otherMsg))
+
+	kv, err := s.js.KeyValue(_idempotencyBucket)
+	s.Require().NoError(err)
+
+	_, err = kv.Get("task_node.request-id/exit/_/0")
+	s.NoError(err)
+}
+
+func (s *IdempotencyTestSuite) TestIdempotencyGuard_MarkProcessedByOtherNode_ExpectNotProcessed() {
+	// Given
+	otherConfig := viper.New()
+	s.Require().NoError(otherConfig.MergeConfigMap(s.config.AllSettings()))
+	otherConfig.Set(internalCommon.ConfigMetadataProcessIDKey, "other-node")
+
+	guard, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, s.config)
+	s.Require().NoError(err)
+
+	otherGuard, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, otherConfig)
+	s.Require().NoError(err)
+
+	msg := newIdempotentMessage("request-id/exit//0")
+
+	// When
+	otherGuard.MarkProcessed(msg)
+
+	// Then
+	s.True(otherGuard.IsProcessed(msg))
+	s.False(guard.IsProcessed(msg))
+}
+
+func (s *IdempotencyTestSuite) TestIdempotencyGuard_Disabled_ExpectNothingRemembered() {
+	// Given
+	s.config.Set(internalCommon.ConfigRunnerSubscriberIdempotencyEnabledKey, false)
+
+	guard, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, s.config)
+	s.Require().NoError(err)
+
+	msg := newIdempotentMessage("request-id/exit//0")
+
+	// When
+	guard.MarkProcessed(msg)
+
+	// Then
+	s.False(guard.IsProcessed(msg))
+
+	_, err = s.js.KeyValue(_idempotencyBucket)
+	s.ErrorIs(err, nats.ErrBucketNotFound)
+}
+
+func (s *IdempotencyTestSuite) TestIdempotencyGuard_MessageWithoutID_ExpectNothingRemembered() {
+	// Given
+	guard, err := common.NewIdempotencyGuard(testr.New(s.T()), s.js, s.config)
+	s.Require().NoError(err)
+
+	msg := nats.NewMsg("subject")
+
+	// When
+	guard.MarkProcessed(msg)
+
+	// Then
+	s.False(guard.IsProcessed(msg))
+
+	kv, err := s.js.KeyValue(_idempotencyBucket)
+	s.Require().NoError(err)
+
+	_, err = kv.Keys()
+	s.ErrorIs(err, nats.ErrNoKeysFound)
+}
+
+func (s *IdempotencyTestSuite) TestIdempotencyKey_ExpectMsgIDOrStreamPosition() {
+	// Given
+	_, err := s.js.AddStream(&nats.StreamConfig{Name: "stream", Subjects: []string{"subject"}})
+	s.Require().NoError(err)
+
+	sub, err := s.js.SubscribeSync("subject")
+	s.Require().NoError(err)
+
+	_, err = s.js.Publish("subject", nil)
+	s.Require().NoError(err)
+
+	streamMsg, err := sub.NextMsg(5 * time.Second)
+	s.Require().NoError(err)
+
+	// When
+	byMsgID, byMsgIDOk := common.IdempotencyKey("task node", newIdempotentMessage("request.id/exit//0"))
+	byPosition, byPositionOk := common.IdempotencyKey("task node", streamMsg)
+	_, withoutIDOk := common.IdempotencyKey("task node", nats.NewMsg("subject"))
+
+	// Then
+	s.True(byMsgIDOk)
+	s.Equal("task_node.request_id/exit//0", byMsgID)
+	s.True(byPositionOk)
+	s.Equal("task_node.stream-1", byPosition)
+	s.False(withoutIDOk)
+}
+
+func newIdempotentMessage(msgID string) *nats.Msg {
+	msg := nats.NewMsg("subject")
+	msg.Header.Set(nats.MsgIdHdr, msgID)
+
+	return msg
+}
+
+func TestIdempotencyTestSuite(t *testing.T) {
+	suite.Run(t, new(IdempotencyTestSuite))
+}
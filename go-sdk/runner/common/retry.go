@@ -0,0 +1,100 @@
+package common
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kaisdk "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+// Headers added to the messages republished to the dead-letter subject.
+const (
+	DeadLetterErrorHeader      = "Kai-Error"
+	DeadLetterSubjectHeader    = "Kai-Original-Subject"
+	DeadLetterProcessHeader    = "Kai-Process"
+	DeadLetterDeliveriesHeader = "Kai-Deliveries"
+)
+
+// RetryPolicy defines how a runner handles the messages whose processing failed. Errors marked
+// with sdk.RetryableError are redelivered, waiting an exponential backoff between deliveries,
+// until MaxDeliveries is reached. Any other error is terminal. Once a message is failed, it is
+// republished to DeadLetterSubject when one is set.
+type RetryPolicy struct {
+	MaxDeliveries     int
+	InitialBackoff    time.Duration
+	MaxBackoff        time.Duration
+	DeadLetterSubject string
+}
+
+// RetryPolicyFromConfig reads the retry policy from the runner.subscriber.retry configuration.
+func RetryPolicyFromConfig(config *viper.Viper) RetryPolicy {
+	config = internalCommon.ConfigOrGlobal(config)
+
+	return RetryPolicy{
+		MaxDeliveries:     config.GetInt(internalCommon.ConfigRunnerSubscriberRetryMaxDeliveriesKey),
+		InitialBackoff:    config.GetDuration(internalCommon.ConfigRunnerSubscriberRetryInitialBackoffKey),
+		MaxBackoff:        config.GetDuration(internalCommon.ConfigRunnerSubscriberRetryMaxBackoffKey),
+		DeadLetterSubject: config.GetString(internalCommon.ConfigRunnerSubscriberDeadLetterSubjectKey),
+	}
+}
+
+// ShouldRetry reports whether a message that failed with the given error on its numDelivered
+// delivery has to be redelivered.
+func (p RetryPolicy) ShouldRetry(err error, numDelivered uint64) bool {
+	return kaisdk.IsRetryable(err) && p.MaxDeliveries > 0 && numDelivered < uint64(p.MaxDeliveries)
+}
+
+// Backoff returns how long to wait before redelivering a message that failed on its numDelivered
+// delivery, doubling the initial backoff on every delivery up to the maximum backoff.
+func (p RetryPolicy) Backoff(numDelivered uint64) time.Duration {
+	backoff := p.InitialBackoff
+
+	for i := uint64(1); i < numDelivered; i++ {
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			break
+		}
+
+		backoff *= 2
+	}
+
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return backoff
+}
+
+// DeadLetterMessage returns a copy of the given message addressed to the dead-letter subject,
+// with headers describing the failure.
+func (p RetryPolicy) DeadLetterMessage(msg *nats.Msg, process, errMsg string, numDelivered uint64) *nats.Msg {
+	deadLetter := nats.NewMsg(p.DeadLetterSubject)
+	deadLetter.Data = msg.Data
+
+	for key, values := range msg.Header {
+		for _, value := range values {
+			deadLetter.Header.Add(key, value)
+		}
+	}
+
+	deadLetter.Header.Set(DeadLetterErrorHeader, errMsg)
+	deadLetter.Header.Set(DeadLetterSubjectHeader, msg.Subject)
+	deadLetter.Header.Set(DeadLetterProcessHeader, process)
+	deadLetter.Header.Set(DeadLetterDeliveriesHeader, strconv.FormatUint(numDelivered, 10))
+
+	return deadLetter
+}
+
+// DeliveryCount returns how many times the given message has been delivered, or 1 when the
+// message does not come from JetStream.
+func DeliveryCount(msg *nats.Msg) uint64 {
+	metadata, err := msg.Metadata()
+	if err != nil {
+		return 1
+	}
+
+	return metadata.NumDelivered
+}
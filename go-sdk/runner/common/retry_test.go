@@ -0,0 +1,123 @@
+//go:build unit
+
+package common_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+
+	internalCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type RetryPolicyTestSuite struct {
+	suite.Suite
+	retryPolicy common.RetryPolicy
+}
+
+func (s *RetryPolicyTestSuite) SetupTest() {
+	s.retryPolicy = common.RetryPolicy{
+		MaxDeliveries:     3,
+		InitialBackoff:    time.Second,
+		MaxBackoff:        3 * time.Second,
+		DeadLetterSubject: "dead-letter",
+	}
+}
+
+func (s *RetryPolicyTestSuite) TestShouldRetry_RetryableErrorBeforeMaxDeliveries_ExpectTrue() {
+	// Given
+	err := fmt.Errorf("wrapped: %w", sdk.RetryableError(errors.New("transient error")))
+
+	// When
+	shouldRetry := s.retryPolicy.ShouldRetry(err, 2)
+
+	// Then
+	s.True(shouldRetry)
+}
+
+func (s *RetryPolicyTestSuite) TestShouldRetry_RetryableErrorOnLastDelivery_ExpectFalse() {
+	// When
+	shouldRetry := s.retryPolicy.ShouldRetry(sdk.RetryableError(errors.New("transient error")), 3)
+
+	// Then
+	s.False(shouldRetry)
+}
+
+func (s *RetryPolicyTestSuite) TestShouldRetry_TerminalError_ExpectFalse() {
+	// When
+	shouldRetry := s.retryPolicy.ShouldRetry(errors.New("terminal error"), 1)
+
+	// Then
+	s.False(shouldRetry)
+}
+
+func (s *RetryPolicyTestSuite) TestBackoff_ExpectExponentialUpToMaxBackoff() {
+	// When
+	backoffs := []time.Duration{
+		s.retryPolicy.Backoff(1),
+		s.retryPolicy.Backoff(2),
+		s.retryPolicy.Backoff(3),
+		s.retryPolicy.Backoff(100),
+	}
+
+	// Then
+	s.Equal([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second, 3 * time.Second}, backoffs)
+}
+
+func (s *RetryPolicyTestSuite) TestDeadLetterMessage_ExpectOriginalDataWithFailureHeaders() {
+	// Given
+	msg := nats.NewMsg("input")
+	msg.Data = []byte("payload")
+	msg.Header.Set("Custom", "value")
+
+	// When
+	deadLetter := s.retryPolicy.DeadLetterMessage(msg, "process", "some error", 3)
+
+	// Then
+	s.Equal("dead-letter", deadLetter.Subject)
+	s.Equal(msg.Data, deadLetter.Data)
+	s.Equal("value", deadLetter.Header.Get("Custom"))
+	s.Equal("some error", deadLetter.Header.Get(common.DeadLetterErrorHeader))
+	s.Equal("input", deadLetter.Header.Get(common.DeadLetterSubjectHeader))
+	s.Equal("process", deadLetter.Header.Get(common.DeadLetterProcessHeader))
+	s.Equal("3", deadLetter.Header.Get(common.DeadLetterDeliveriesHeader))
+}
+
+func (s *RetryPolicyTestSuite) TestRetryPolicyFromConfig_ExpectConfiguredValues() {
+	// Given
+	config := viper.New()
+	config.Set(internalCommon.ConfigRunnerSubscriberRetryMaxDeliveriesKey, 5)
+	config.Set(internalCommon.ConfigRunnerSubscriberRetryInitialBackoffKey, "500ms")
+	config.Set(internalCommon.ConfigRunnerSubscriberRetryMaxBackoffKey, "10s")
+	config.Set(internalCommon.ConfigRunnerSubscriberDeadLetterSubjectKey, "dead-letter")
+
+	// When
+	retryPolicy := common.RetryPolicyFromConfig(config)
+
+	// Then
+	s.Equal(common.RetryPolicy{
+		MaxDeliveries:     5,
+		InitialBackoff:    500 * time.Millisecond,
+		MaxBackoff:        10 * time.Second,
+		DeadLetterSubject: "dead-letter",
+	}, retryPolicy)
+}
+
+func (s *RetryPolicyTestSuite) TestDeliveryCount_CoreNatsMessage_ExpectOne() {
+	// When
+	numDelivered := common.DeliveryCount(nats.NewMsg("input"))
+
+	// Then
+	s.Equal(uint64(1), numDelivered)
+}
+
+func TestRetryPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(RetryPolicyTestSuite))
+}
@@ -0,0 +1,96 @@
+package common
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const _drainPollPeriod = 10 * time.Millisecond
+
+// CancelledHandlersTimeout is how long runners wait, once the shutdown grace period expires, for the
+// handlers they cancelled to return. Handlers ignoring the cancellation are left behind, and the
+// outputs they send are rejected.
+const CancelledHandlersTimeout = 5 * time.Second
+
+// DrainConnection exports the metrics and spans recorded so far and stops their exporters, flushes
+// the messages published through the connection and drains it, waiting for it to be closed up to
+// its drain timeout. Errors are only logged, since nothing else can be done about them while
+// shutting down.
+func DrainConnection(logger logr.Logger, kaiSDK sdk.KaiSDK, nc *nats.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout(nc))
+	defer cancel()
+
+	shutdownExporters(ctx, logger, kaiSDK)
+
+	if nc == nil || nc.IsClosed() {
+		return
+	}
+
+	logger.V(1).Info("Draining NATS connection")
+
+	if err := nc.FlushWithContext(ctx); err != nil {
+		logger.Error(err, "Error flushing NATS connection")
+	}
+
+	if err := nc.Drain(); err != nil {
+		logger.Error(err, "Error draining NATS connection")
+		return
+	}
+
+	ticker := time.NewTicker(_drainPollPeriod)
+	defer ticker.Stop()
+
+	for !nc.IsClosed() {
+		select {
+		case <-ctx.Done():
+			logger.Info("NATS connection not drained before the timeout, closing it")
+			nc.Close()
+
+			return
+		case <-ticker.C:
+		}
+	}
+
+	logger.V(1).Info("NATS connection drained")
+}
+
+// FlushConnection exports the metrics and spans recorded so far and stops their exporters, and
+// flushes the messages published through the connection, leaving it open. It is used instead of
+// DrainConnection when the connection is shared with the caller of the runner.
+func FlushConnection(logger logr.Logger, kaiSDK sdk.KaiSDK, nc *nats.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout(nc))
+	defer cancel()
+
+	shutdownExporters(ctx, logger, kaiSDK)
+
+	if nc == nil || nc.IsClosed() {
+		return
+	}
+
+	if err := nc.FlushWithContext(ctx); err != nil {
+		logger.Error(err, "Error flushing NATS connection")
+	}
+}
+
+func drainTimeout(nc *nats.Conn) time.Duration {
+	if nc == nil {
+		return nats.DefaultDrainTimeout
+	}
+
+	return nc.Opts.DrainTimeout
+}
+
+func shutdownExporters(ctx context.Context, logger logr.Logger, kaiSDK sdk.KaiSDK) {
+	if err := kaiSDK.Measurements.Shutdown(ctx); err != nil {
+		logger.Error(err, "Error stopping metrics exporter")
+	}
+
+	if err := kaiSDK.Tracing.Shutdown(ctx); err != nil {
+		logger.Error(err, "Error stopping tracing exporter")
+	}
+}
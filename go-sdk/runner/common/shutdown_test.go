@@ -0,0 +1,80 @@
+//go:build unit
+
+package common_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+)
+
+type ShutdownTestSuite struct {
+	suite.Suite
+	server *server.Server
+	nc     *nats.Conn
+	fake   *sdktest.SDK
+}
+
+func (s *ShutdownTestSuite) SetupTest() {
+	var err error
+
+	s.server, err = server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT})
+	s.Require().NoError(err)
+
+	go s.server.Start()
+
+	s.Require().True(s.server.ReadyForConnections(5 * time.Second))
+
+	s.nc, err = nats.Connect(s.server.ClientURL())
+	s.Require().NoError(err)
+
+	s.fake = sdktest.New()
+}
+
+func (s *ShutdownTestSuite) TearDownTest() {
+	s.nc.Close()
+	s.server.Shutdown()
+}
+
+func (s *ShutdownTestSuite) TestDrainConnection_ExpectConnectionClosed() {
+	// When
+	common.DrainConnection(testr.New(s.T()), s.fake.KaiSDK(), s.nc)
+
+	// Then
+	s.True(s.nc.IsClosed())
+}
+
+func (s *ShutdownTestSuite) TestFlushConnection_ExpectMessagesDeliveredAndConnectionOpen() {
+	// Given
+	receiver, err := nats.Connect(s.server.ClientURL())
+	s.Require().NoError(err)
+
+	defer receiver.Close()
+
+	sub, err := receiver.SubscribeSync("subject")
+	s.Require().NoError(err)
+	s.Require().NoError(receiver.Flush())
+
+	s.Require().NoError(s.nc.Publish("subject", []byte("message")))
+
+	// When
+	common.FlushConnection(testr.New(s.T()), s.fake.KaiSDK(), s.nc)
+
+	// Then
+	s.False(s.nc.IsClosed())
+
+	msg, err := sub.NextMsg(time.Second)
+	s.Require().NoError(err)
+	s.Equal("message", string(msg.Data))
+}
+
+func TestShutdownTestSuite(t *testing.T) {
+	suite.Run(t, new(ShutdownTestSuite))
+}
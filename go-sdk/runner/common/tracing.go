@@ -0,0 +1,87 @@
+package common
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	traceNoop "go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	kaisdk "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+// Names of the spans of the phases of the processing of a message.
+const (
+	PreprocessorSpanName  = "preprocessor"
+	HandlerSpanName       = "handler"
+	PostprocessorSpanName = "postprocessor"
+)
+
+// StartMessageSpan starts the span of a message received by a runner. It is a child of the span
+// whose trace context the message carries, so every node of a request shares the same trace.
+func StartMessageSpan(ctx context.Context, kaiSDK kaisdk.KaiSDK, msg *nats.Msg,
+	requestMsg *kai.KaiNatsMessage,
+) (context.Context, trace.Span) {
+	ctx = tracing.Extract(ctx, msg.Header)
+
+	return getTracer(kaiSDK).Start(ctx, fmt.Sprintf("%s process", kaiSDK.Metadata.GetProcess()),
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("messaging.source.name", msg.Subject),
+			attribute.String("kai.request_id", requestMsg.GetRequestId()),
+			attribute.String("kai.from_node", requestMsg.GetFromNode()),
+			attribute.String("kai.process", kaiSDK.Metadata.GetProcess()),
+		),
+	)
+}
+
+// StartRequestSpan starts the span of a request sent by a trigger, the root of the spans of every
+// node processing it unless the given context already carries a trace.
+func StartRequestSpan(ctx context.Context, kaiSDK kaisdk.KaiSDK, requestID string) (context.Context, trace.Span) {
+	return getTracer(kaiSDK).Start(ctx, fmt.Sprintf("%s request", kaiSDK.Metadata.GetProcess()),
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "nats"),
+			attribute.String("kai.request_id", requestID),
+			attribute.String("kai.process", kaiSDK.Metadata.GetProcess()),
+		),
+	)
+}
+
+// TracePhase runs a phase of the processing of a message, such as the preprocessor, in a span of
+// its own. The SDK passed to the handler carries the span, so the messages it sends and the
+// storage, model registry and prediction calls it makes belong to it.
+func TracePhase(kaiSDK kaisdk.KaiSDK, name string, handler Handler, payload *anypb.Any) error {
+	ctx, span := getTracer(kaiSDK).Start(kaiSDK.Context(), name)
+	defer span.End()
+
+	err := handler(kaiSDK.WithContext(ctx), payload)
+	RecordError(span, err)
+
+	return err
+}
+
+// RecordError marks the span as failed with the given error, if any.
+func RecordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+func getTracer(kaiSDK kaisdk.KaiSDK) trace.Tracer {
+	if kaiSDK.Tracing == nil {
+		return traceNoop.NewTracerProvider().Tracer(tracing.TracerName)
+	}
+
+	return kaiSDK.Tracing.GetTracer()
+}
@@ -0,0 +1,108 @@
+//go:build unit
+
+package common_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	fake *sdktest.SDK
+}
+
+func (s *TracingTestSuite) SetupTest() {
+	s.fake = sdktest.New()
+}
+
+func (s *TracingTestSuite) TestStartMessageSpan_ExpectChildOfMessageTrace() {
+	// Given
+	parent := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+
+	msg := nats.NewMsg("test.subject")
+	tracing.Inject(trace.ContextWithSpanContext(context.Background(), parent), msg.Header)
+
+	requestMsg := &kai.KaiNatsMessage{RequestId: "request-id", FromNode: "trigger"}
+
+	// When
+	_, span := common.StartMessageSpan(context.Background(), s.fake.KaiSDK(), msg, requestMsg)
+	span.End()
+
+	// Then
+	spans := s.fake.Tracing.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal(parent.TraceID(), spans[0].SpanContext.TraceID())
+	s.Equal(parent.SpanID(), spans[0].Parent.SpanID())
+	s.Equal(trace.SpanKindConsumer, spans[0].SpanKind)
+	s.Equal(fmt.Sprintf("%s process", s.fake.Metadata.GetProcess()), spans[0].Name)
+}
+
+func (s *TracingTestSuite) TestTracePhase_ExpectHandlerRunInChildSpan() {
+	// Given
+	kaiSDK := s.fake.KaiSDK()
+	ctx, parent := kaiSDK.Tracing.GetTracer().Start(context.Background(), "parent")
+	kaiSDK = kaiSDK.WithContext(ctx)
+
+	var handlerSpan trace.SpanContext
+
+	handler := func(hSdk sdk.KaiSDK, _ *anypb.Any) error {
+		handlerSpan = trace.SpanContextFromContext(hSdk.Context())
+		return nil
+	}
+
+	// When
+	err := common.TracePhase(kaiSDK, common.HandlerSpanName, handler, &anypb.Any{})
+	parent.End()
+
+	// Then
+	s.Require().NoError(err)
+
+	spans := s.fake.Tracing.Spans()
+	s.Require().Len(spans, 2)
+	s.Equal(common.HandlerSpanName, spans[0].Name)
+	s.Equal(handlerSpan.SpanID(), spans[0].SpanContext.SpanID())
+	s.Equal(parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+}
+
+func (s *TracingTestSuite) TestTracePhase_HandlerError_ExpectSpanFailed() {
+	// Given
+	handlerErr := errors.New("handler error")
+
+	handler := func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return handlerErr
+	}
+
+	// When
+	err := common.TracePhase(s.fake.KaiSDK(), common.PreprocessorSpanName, handler, &anypb.Any{})
+
+	// Then
+	s.ErrorIs(err, handlerErr)
+
+	spans := s.fake.Tracing.Spans()
+	s.Require().Len(spans, 1)
+	s.Equal(codes.Error, spans[0].Status.Code)
+	s.Equal(handlerErr.Error(), spans[0].Status.Description)
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
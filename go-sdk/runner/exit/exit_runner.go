@@ -1,7 +1,10 @@
 package exit
 
 import (
+	"context"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
@@ -9,6 +12,7 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
 )
 
 const _exitLoggerName = "[EXIT]"
@@ -19,29 +23,66 @@ type Handler common.Handler
 
 type Postprocessor common.Handler
 
+// typeRoute identifies the handler of a payload type, optionally restricted to a node.
+type typeRoute struct {
+	node        string
+	messageType string
+}
+
 type Runner struct {
 	sdk              sdk.KaiSDK
+	config           *viper.Viper
+	ctx              context.Context
+	cancel           context.CancelFunc
+	handlersCtx      context.Context
+	cancelHandlers   context.CancelFunc
 	nats             *nats.Conn
 	jetstream        nats.JetStreamContext
 	responseHandlers map[string]Handler
+	typeHandlers     map[typeRoute]Handler
 	initializer      common.Initializer
 	preprocessor     Preprocessor
 	postprocessor    Postprocessor
 	finalizer        common.Finalizer
 	messagesMetric   metric.Int64Histogram
+	panicsMetric     metric.Int64Counter
+	concurrency      int
+	retryPolicy      *common.RetryPolicy
+	handlerTimeout   time.Duration
+	gracePeriod      time.Duration
+	sharedConnection bool
+	health           *common.HealthServer
+	idempotency      *common.IdempotencyGuard
+	middlewares      []common.Middleware
 }
 
 func NewExitRunner(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext) *Runner {
+	return NewExitRunnerWithConfig(logger, ns, js, nil)
+}
+
+// NewExitRunnerWithConfig creates a Runner reading its configuration from the given viper instance
+// instead of the global one.
+func NewExitRunnerWithConfig(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	return NewExitRunnerWithSDK(sdk.NewKaiSDKWithConfig(logger, ns, js, config), ns, js, config)
+}
+
+// NewExitRunnerWithSDK creates a Runner using an already initialized KaiSDK, so the errors
+// initializing it can be handled by the caller.
+func NewExitRunnerWithSDK(kaiSDK sdk.KaiSDK, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	kaiSDK.Logger = kaiSDK.Logger.WithName(_exitLoggerName)
+
 	return &Runner{
-		sdk:              sdk.NewKaiSDK(logger.WithName(_exitLoggerName), ns, js),
+		sdk:              kaiSDK,
+		config:           config,
 		nats:             ns,
 		jetstream:        js,
 		responseHandlers: make(map[string]Handler),
+		typeHandlers:     make(map[typeRoute]Handler),
 	}
 }
 
 func (er *Runner) WithInitializer(initializer common.Initializer) *Runner {
-	er.initializer = composeInitializer(initializer)
+	er.initializer = composeInitializer(initializer, er.config)
 	return er
 }
 
@@ -60,6 +101,32 @@ func (er *Runner) WithCustomHandler(subject string, handler Handler) *Runner {
 	return er
 }
 
+// WithTypeHandler sets the handler of the messages whose payload holds the given message type,
+// given as a type URL or a full name, wherever they come from. See common.TypeURL.
+//
+// Handlers are chosen in this order: the one set for both the node and the payload type, the one
+// set for the payload type, the one set for the node and, finally, the default handler.
+func (er *Runner) WithTypeHandler(messageType string, handler Handler) *Runner {
+	er.typeHandlers[typeRoute{messageType: common.TypeName(messageType)}] = composeHandler(handler)
+	return er
+}
+
+// WithCustomTypeHandler sets the handler of the messages coming from the given node whose payload
+// holds the given message type. It takes precedence over every other handler.
+func (er *Runner) WithCustomTypeHandler(subject, messageType string, handler Handler) *Runner {
+	route := typeRoute{node: strings.ToLower(subject), messageType: common.TypeName(messageType)}
+	er.typeHandlers[route] = composeHandler(handler)
+
+	return er
+}
+
+// WithMiddleware adds middlewares wrapping every handler, including the custom ones. Middlewares
+// run in the order they are added, so the first one is the outermost.
+func (er *Runner) WithMiddleware(middlewares ...common.Middleware) *Runner {
+	er.middlewares = append(er.middlewares, middlewares...)
+	return er
+}
+
 func (er *Runner) WithPostprocessor(postprocessor Postprocessor) *Runner {
 	er.postprocessor = composePostprocessor(postprocessor)
 	return er
@@ -70,22 +137,97 @@ func (er *Runner) WithFinalizer(finalizer common.Finalizer) *Runner {
 	return er
 }
 
+// WithConcurrency sets how many messages are processed at the same time, overriding the
+// runner.subscriber.max_concurrency configuration. Each message is still acknowledged once
+// its handler finishes.
+func (er *Runner) WithConcurrency(maxConcurrency int) *Runner {
+	er.concurrency = maxConcurrency
+	return er
+}
+
+// WithHandlerTimeout sets how long a message can be processed before it is failed with
+// common.ErrHandlerTimeout, overriding the runner.subscriber.handler_timeout configuration.
+// The context of the SDK given to the handler is cancelled at that moment.
+func (er *Runner) WithHandlerTimeout(timeout time.Duration) *Runner {
+	er.handlerTimeout = timeout
+	return er
+}
+
+// WithShutdownGracePeriod sets how long the messages in process can take to finish once the runner
+// starts shutting down, overriding the runner.shutdown_grace_period configuration. The context of
+// the SDK given to the handler is cancelled when it expires.
+func (er *Runner) WithShutdownGracePeriod(gracePeriod time.Duration) *Runner {
+	er.gracePeriod = gracePeriod
+	return er
+}
+
+// WithSharedConnection tells the runner that its NATS connection is also used by the caller, so on
+// shutdown it is only flushed instead of drained and closed.
+func (er *Runner) WithSharedConnection() *Runner {
+	er.sharedConnection = true
+	return er
+}
+
+// WithRetryPolicy sets how failed messages are retried and dead-lettered, overriding the
+// runner.subscriber.retry and runner.subscriber.dead_letter_subject configuration.
+func (er *Runner) WithRetryPolicy(retryPolicy common.RetryPolicy) *Runner {
+	er.retryPolicy = &retryPolicy
+	return er
+}
+
 func (er *Runner) Run() {
+	er.RunContext(context.Background())
+}
+
+// RunContext runs the ExitRunner until a shutdown signal is received or the given context is done.
+func (er *Runner) RunContext(ctx context.Context) {
+	er.ctx, er.cancel = context.WithCancel(ctx)
+	defer er.cancel()
+
+	// Handlers are only cancelled once the shutdown grace period expires.
+	er.handlersCtx, er.cancelHandlers = context.WithCancel(context.WithoutCancel(er.ctx))
+	defer er.cancelHandlers()
+
 	if er.responseHandlers["default"] == nil {
 		panic("Undefined default handler")
 	}
 
 	if er.initializer == nil {
-		er.initializer = composeInitializer(nil)
+		er.initializer = composeInitializer(nil, er.config)
 	}
 
 	if er.finalizer == nil {
 		er.finalizer = composeFinalizer(nil)
 	}
 
+	er.health = common.NewHealthServer(er.sdk.Logger, er.sdk, er.nats, er.config)
+	if err := er.health.Start(); err != nil {
+		er.sdk.Logger.Error(err, "Error starting health server")
+		os.Exit(1)
+	}
+	defer er.health.Shutdown()
+
+	idempotency, err := common.NewIdempotencyGuard(er.sdk.Logger, er.jetstream, er.config)
+	if err != nil {
+		er.sdk.Logger.Error(err, "Error initializing idempotency guard")
+		os.Exit(1)
+	}
+
+	er.idempotency = idempotency
+
+	stopClaimCheckSweeper := common.StartClaimCheckSweeper(er.sdk.Logger, er.jetstream, er.config)
+
 	er.initializer(er.sdk)
 
 	er.startSubscriber()
 
+	stopClaimCheckSweeper()
+
+	if er.sharedConnection {
+		common.FlushConnection(er.sdk.Logger, er.sdk, er.nats)
+	} else {
+		common.DrainConnection(er.sdk.Logger, er.sdk, er.nats)
+	}
+
 	er.finalizer(er.sdk)
 }
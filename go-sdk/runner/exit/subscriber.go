@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -20,7 +18,9 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	runnerCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
 )
 
 const _subscriberLoggerName = "[SUBSCRIBER]"
@@ -29,8 +29,12 @@ func (er *Runner) getLoggerWithName() logr.Logger {
 	return er.sdk.Logger.WithName(_subscriberLoggerName)
 }
 
+func (er *Runner) getConfig() *viper.Viper {
+	return common.ConfigOrGlobal(er.config)
+}
+
 func (er *Runner) startSubscriber() {
-	inputSubjects := viper.GetStringSlice(common.ConfigNatsInputsKey)
+	inputSubjects := er.getConfig().GetStringSlice(common.ConfigNatsInputsKey)
 
 	if len(inputSubjects) == 0 {
 		er.getLoggerWithName().Info("Undefined input subjects")
@@ -49,6 +53,17 @@ func (er *Runner) startSubscriber() {
 		os.Exit(1)
 	}
 
+	er.panicsMetric, err = er.sdk.Measurements.GetMetricsClient().Int64Counter(
+		"runner-handler-panics-metric",
+		metric.WithDescription("How many panics were recovered while processing messages."),
+	)
+	if err != nil {
+		er.getLoggerWithName().Error(err, "Error initializing metric")
+		os.Exit(1)
+	}
+
+	dispatcher := runnerCommon.NewDispatcher(er.getConcurrency())
+
 	subscriptions := make([]*nats.Subscription, 0, len(inputSubjects))
 
 	for _, subject := range inputSubjects {
@@ -60,11 +75,11 @@ func (er *Runner) startSubscriber() {
 		s, err := er.jetstream.QueueSubscribe(
 			subject,
 			consumerName,
-			er.processMessage,
+			dispatcher.Dispatch(er.processMessage),
 			nats.DeliverNew(),
 			nats.Durable(consumerName),
 			nats.ManualAck(),
-			nats.AckWait(viper.GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
+			nats.AckWait(er.getConfig().GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
 		)
 		if err != nil {
 			er.getLoggerWithName().Error(err, fmt.Sprintf("Error subscribing to subject %s", subject))
@@ -78,14 +93,34 @@ func (er *Runner) startSubscriber() {
 
 	er.getLoggerWithName().V(1).Info("Subscribed to all subjects successfully")
 
-	// Handle sigterm and await termChan signal
-	termChan := make(chan os.Signal, 1)
-	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
-	<-termChan
+	er.health.SetReady(true)
+
+	// Handle sigterm or context cancellation
+	runnerCommon.WaitForShutdown(er.ctx)
 
 	// Handle shutdown
 	er.getLoggerWithName().Info("Shutdown signal received")
 
+	// Stop getting new work while the messages in process finish.
+	er.health.SetReady(false)
+
+	// Messages received from now on are returned to NATS, to be processed by another replica.
+	dispatcher.Stop()
+
+	gracePeriod := er.getShutdownGracePeriod()
+
+	er.getLoggerWithName().V(1).Info(fmt.Sprintf("Waiting up to %s for the messages in process", gracePeriod))
+
+	if !dispatcher.WaitTimeout(gracePeriod) {
+		er.getLoggerWithName().Info("Shutdown grace period expired, cancelling the messages in process")
+	}
+
+	er.cancelHandlers()
+
+	if !dispatcher.WaitTimeout(runnerCommon.CancelledHandlersTimeout) {
+		er.getLoggerWithName().Info("Cancelled handlers still running, shutting down without them")
+	}
+
 	er.getLoggerWithName().V(1).Info("Unsubscribing from all subjects")
 
 	for _, s := range subscriptions {
@@ -94,19 +129,58 @@ func (er *Runner) startSubscriber() {
 		err := s.Unsubscribe()
 		if err != nil {
 			er.getLoggerWithName().Error(err, fmt.Sprintf("Error unsubscribing from the subject %s", s.Subject))
-			os.Exit(1)
 		}
 	}
 
 	er.getLoggerWithName().Info("Unsubscribed from all subjects")
+
+	er.cancel()
+}
+
+func (er *Runner) getConcurrency() int {
+	if er.concurrency > 0 {
+		return er.concurrency
+	}
+
+	return er.getConfig().GetInt(common.ConfigRunnerSubscriberMaxConcurrencyKey)
 }
 
 func (er *Runner) processMessage(msg *nats.Msg) {
-	requestMsg, err := er.newRequestMessage(msg.Data)
+	var requestMsg *kai.KaiNatsMessage
+
+	defer runnerCommon.RecoverPanic(er.getLoggerWithName(), func(err error) {
+		er.countPanic(requestMsg.GetRequestId())
+
+		errMsg := fmt.Sprintf("Error in node %q processing message from subject %s: %s",
+			er.sdk.Metadata.GetProcess(), msg.Subject, err)
+		er.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+	})
+
+	requestMsg, err := er.newRequestMessage(msg)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error parsing msg.data coming from subject %s because is not a valid protobuf: %s",
 			msg.Subject, err)
-		er.processRunnerError(msg, errMsg, requestMsg.RequestId)
+		er.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+
+		return
+	}
+
+	requestMsg, err = runnerCommon.ResolveClaimCheck(er.jetstream, er.getConfig(), msg, requestMsg)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error resolving the claim check of the message from subject %s: %s", msg.Subject, err)
+		er.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+
+		return
+	}
+
+	if er.idempotency.IsProcessed(msg) {
+		er.getLoggerWithName().Info(fmt.Sprintf("Skipping message from subject %s already processed for request id %s",
+			msg.Subject, requestMsg.GetRequestId()))
+
+		ackErr := msg.Ack()
+		if ackErr != nil {
+			er.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+		}
 
 		return
 	}
@@ -124,56 +198,216 @@ func (er *Runner) processMessage(msg *nats.Msg) {
 	er.getLoggerWithName().Info(fmt.Sprintf("New message received with subject %s",
 		msg.Subject))
 
-	handler := er.getResponseHandler(strings.ToLower(requestMsg.FromNode))
+	handler := er.getResponseHandler(strings.ToLower(requestMsg.FromNode), requestMsg.GetPayload().GetTypeUrl())
 	if handler == nil {
-		errMsg := fmt.Sprintf("Error missing handler for node %q", requestMsg.FromNode)
-		er.processRunnerError(msg, errMsg, requestMsg.RequestId)
+		errMsg := fmt.Sprintf("Error missing handler for node %q and payload type %q",
+			requestMsg.FromNode, requestMsg.GetPayload().GetTypeUrl())
+		er.processRunnerError(msg, nil, errMsg, requestMsg.RequestId)
 
 		return
 	}
 
+	ctx, cancel := er.newMessageContext(msg)
+	defer cancel()
+
+	ctx, span := runnerCommon.StartMessageSpan(ctx, er.sdk, msg, requestMsg)
+	defer span.End()
+
 	// Make a shallow copy of the sdk object to set inside the request msg.
 	hSdk := sdk.ShallowCopyWithRequest(&er.sdk, requestMsg)
+	hSdk = hSdk.WithContext(ctx)
+	hSdk = hSdk.WithHandledMsgID(runnerCommon.HandledMsgID(msg))
+
+	result := make(chan handlerResult, 1)
+
+	go func() {
+		result <- er.executeHandler(hSdk, handler, requestMsg)
+	}()
+
+	select {
+	case res := <-result:
+		if res.err != nil {
+			runnerCommon.RecordError(span, res.err)
+			er.processRunnerError(msg, res.err, res.errMsg, requestMsg.RequestId)
+			return
+		}
+	case <-ctx.Done():
+		runnerCommon.RecordError(span, ctx.Err())
+		er.processCancelledMessage(msg, requestMsg)
+
+		// The handler keeps its worker until it returns, so handlers ignoring the cancellation
+		// never run beyond the maximum concurrency.
+		<-result
+
+		return
+	}
+
+	er.idempotency.MarkProcessed(msg)
+
+	// Tell NATS we don't need to receive the message anymore, and we are done processing it.
+	ackErr := msg.Ack()
+	if ackErr != nil {
+		er.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+	}
+}
+
+type handlerResult struct {
+	errMsg string
+	err    error
+}
+
+// executeHandler runs the preprocessor, handler and postprocessor of a message. A panic in any of
+// them is recovered and returned as an error.
+func (er *Runner) executeHandler(hSdk sdk.KaiSDK, handler Handler, requestMsg *kai.KaiNatsMessage) (result handlerResult) {
+	defer runnerCommon.RecoverPanic(er.getLoggerWithName(), func(err error) {
+		er.countPanic(requestMsg.GetRequestId())
+
+		result = handlerResult{
+			errMsg: fmt.Sprintf("Error in node %q executing handler for node %q: %s",
+				er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err),
+			err: err,
+		}
+	})
 
 	if er.preprocessor != nil {
-		err := er.preprocessor(hSdk, requestMsg.Payload)
+		err := runnerCommon.TracePhase(hSdk, runnerCommon.PreprocessorSpanName,
+			runnerCommon.Handler(er.preprocessor), requestMsg.Payload)
 		if err != nil {
 			errMsg := fmt.Sprintf("Error in node %q executing handler preprocessor for node %q: %s",
 				er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-			er.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-			return
+			return handlerResult{errMsg: errMsg, err: err}
 		}
 	}
 
-	err = handler(hSdk, requestMsg.Payload)
+	err := runnerCommon.TracePhase(hSdk, runnerCommon.HandlerSpanName, runnerCommon.Handler(handler), requestMsg.Payload)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error in node %q executing handler for node %q: %s",
 			er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-		er.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-		return
+		return handlerResult{errMsg: errMsg, err: err}
 	}
 
 	if er.postprocessor != nil {
-		err := er.postprocessor(hSdk, requestMsg.Payload)
+		err := runnerCommon.TracePhase(hSdk, runnerCommon.PostprocessorSpanName,
+			runnerCommon.Handler(er.postprocessor), requestMsg.Payload)
 		if err != nil {
 			errMsg := fmt.Sprintf("Error in node %q executing handler postprocessor for node %q: %s",
 				er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-			er.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-			return
+			return handlerResult{errMsg: errMsg, err: err}
 		}
 	}
 
-	// Tell NATS we don't need to receive the message anymore, and we are done processing it.
-	ackErr := msg.Ack()
-	if ackErr != nil {
-		er.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+	// The outputs sent asynchronously must be delivered before the message is acknowledged.
+	if err := hSdk.Messaging.Flush(hSdk.Context()); err != nil {
+		errMsg := fmt.Sprintf("Error in node %q delivering the outputs of the handler for node %q: %s",
+			er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
+
+		return handlerResult{errMsg: errMsg, err: err}
+	}
+
+	return handlerResult{}
+}
+
+// newMessageContext returns the context of a message, cancelled when the shutdown grace period of
+// the runner expires or, if a handler timeout is set, when it expires.
+func (er *Runner) newMessageContext(msg *nats.Msg) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if timeout := er.getHandlerTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(er.handlersCtx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(er.handlersCtx)
+	}
+
+	deadline, ok := runnerCommon.MessageDeadline(msg)
+	if !ok {
+		return ctx, cancel
+	}
+
+	deadlineCtx, deadlineCancel := context.WithDeadline(ctx, deadline)
+
+	return deadlineCtx, func() {
+		deadlineCancel()
+		cancel()
+	}
+}
+
+// processCancelledMessage handles a message whose context was cancelled before its handler
+// finished. Once the shutdown grace period expires, the message is returned to NATS to be
+// delivered again. On timeout, it is failed with a retryable ErrHandlerTimeout, unless the deadline
+// of its request was exceeded, which is not retried. The handler keeps running until it returns,
+// but its result is discarded and the outputs it sends from then on are rejected.
+func (er *Runner) processCancelledMessage(msg *nats.Msg, requestMsg *kai.KaiNatsMessage) {
+	if er.handlersCtx.Err() != nil {
+		er.getLoggerWithName().Info("Shutting down before the handler finished, returning message to NATS")
+
+		nakErr := msg.Nak()
+		if nakErr != nil {
+			er.getLoggerWithName().Error(nakErr, errors.ErrMsgNak)
+		}
+
+		return
 	}
+
+	err := sdk.RetryableError(fmt.Errorf("%w after %s", runnerCommon.ErrHandlerTimeout, er.getHandlerTimeout()))
+	if deadline, ok := runnerCommon.MessageDeadline(msg); ok && !time.Now().Before(deadline) {
+		err = fmt.Errorf("%w at %s", runnerCommon.ErrRequestDeadlineExceeded, deadline.Format(time.RFC3339Nano))
+	}
+
+	errMsg := fmt.Sprintf("Error in node %q executing handler for node %q: %s",
+		er.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
+	er.processRunnerError(msg, err, errMsg, requestMsg.RequestId)
+}
+
+func (er *Runner) countPanic(requestID string) {
+	er.panicsMetric.Add(context.Background(), 1, metric.WithAttributeSet(er.getMetricAttributes(requestID)))
+}
+
+func (er *Runner) getShutdownGracePeriod() time.Duration {
+	if er.gracePeriod > 0 {
+		return er.gracePeriod
+	}
+
+	return er.getConfig().GetDuration(common.ConfigRunnerShutdownGracePeriodKey)
 }
 
-func (er *Runner) processRunnerError(msg *nats.Msg, errMsg, requestID string) {
+func (er *Runner) getHandlerTimeout() time.Duration {
+	if er.handlerTimeout > 0 {
+		return er.handlerTimeout
+	}
+
+	return er.getConfig().GetDuration(common.ConfigRunnerSubscriberHandlerTimeoutKey)
+}
+
+// processRunnerError redelivers the message when the error is retryable and the retry policy
+// allows it. Otherwise, the message is dead-lettered if configured, acknowledged, and the error
+// is published to the output subject.
+func (er *Runner) processRunnerError(msg *nats.Msg, err error, errMsg, requestID string) {
+	retryPolicy := er.getRetryPolicy()
+	numDelivered := runnerCommon.DeliveryCount(msg)
+
+	if retryPolicy.ShouldRetry(err, numDelivered) {
+		delay := retryPolicy.Backoff(numDelivered)
+
+		er.getLoggerWithName().V(1).Info(fmt.Sprintf("%s, retrying in %s", errMsg, delay))
+
+		nakErr := msg.NakWithDelay(delay)
+		if nakErr != nil {
+			er.getLoggerWithName().Error(nakErr, errors.ErrMsgNak)
+		}
+
+		return
+	}
+
+	if retryPolicy.DeadLetterSubject != "" {
+		er.publishDeadLetter(retryPolicy, msg, errMsg, numDelivered)
+	}
+
 	ackErr := msg.Ack()
 	if ackErr != nil {
 		er.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
@@ -183,16 +417,32 @@ func (er *Runner) processRunnerError(msg *nats.Msg, errMsg, requestID string) {
 	er.publishError(requestID, errMsg)
 }
 
-func (er *Runner) newRequestMessage(data []byte) (*kai.KaiNatsMessage, error) {
+func (er *Runner) publishDeadLetter(retryPolicy runnerCommon.RetryPolicy, msg *nats.Msg, errMsg string, numDelivered uint64) {
+	deadLetter := retryPolicy.DeadLetterMessage(msg, er.sdk.Metadata.GetProcess(), errMsg, numDelivered)
+
+	er.getLoggerWithName().V(1).Info(fmt.Sprintf("Publishing failed message to dead-letter subject %s", deadLetter.Subject))
+
+	_, err := er.jetstream.PublishMsg(deadLetter)
+	if err != nil {
+		er.getLoggerWithName().Error(err, "Error publishing message to the dead-letter subject")
+	}
+}
+
+func (er *Runner) getRetryPolicy() runnerCommon.RetryPolicy {
+	if er.retryPolicy != nil {
+		return *er.retryPolicy
+	}
+
+	return runnerCommon.RetryPolicyFromConfig(er.getConfig())
+}
+
+func (er *Runner) newRequestMessage(msg *nats.Msg) (*kai.KaiNatsMessage, error) {
 	requestMsg := &kai.KaiNatsMessage{}
 
-	var err error
-	if common.IsCompressed(data) {
-		data, err = common.UncompressData(data)
-		if err != nil {
-			er.getLoggerWithName().Error(err, "Error reading compressed message")
-			return nil, err
-		}
+	data, err := compression.Decompress(msg.Header, msg.Data)
+	if err != nil {
+		er.getLoggerWithName().Error(err, "Error reading compressed message")
+		return nil, err
 	}
 
 	err = proto.Unmarshal(data, requestMsg)
@@ -204,7 +454,7 @@ func (er *Runner) publishError(requestID, errMsg string) {
 	responseMsg := &kai.KaiNatsMessage{
 		RequestId:   requestID,
 		Error:       errMsg,
-		FromNode:    viper.GetString(common.ConfigMetadataProcessIDKey),
+		FromNode:    er.getConfig().GetString(common.ConfigMetadataProcessIDKey),
 		MessageType: kai.MessageType_ERROR,
 	}
 	er.publishResponse(responseMsg, "")
@@ -220,7 +470,7 @@ func (er *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 		return
 	}
 
-	outputMsg, err = er.prepareOutputMessage(outputMsg)
+	outputMsg, codec, err := er.prepareOutputMessage(outputMsg)
 	if err != nil {
 		er.getLoggerWithName().Error(err, "Error preparing output message")
 		return
@@ -228,14 +478,18 @@ func (er *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 
 	er.getLoggerWithName().V(1).Info(fmt.Sprintf("Publishing response with subject %s", outputSubject))
 
-	_, err = er.jetstream.Publish(outputSubject, outputMsg)
+	pubMsg := nats.NewMsg(outputSubject)
+	pubMsg.Data = outputMsg
+	pubMsg.Header = compression.SetHeader(pubMsg.Header, codec)
+
+	_, err = er.jetstream.PublishMsg(pubMsg)
 	if err != nil {
 		er.getLoggerWithName().Error(err, "Error publishing output")
 	}
 }
 
 func (er *Runner) getOutputSubject(channel string) string {
-	outputSubject := viper.GetString(common.ConfigNatsOutputKey)
+	outputSubject := er.getConfig().GetString(common.ConfigNatsOutputKey)
 	if channel != "" {
 		return fmt.Sprintf("%s.%s", outputSubject, channel)
 	}
@@ -243,48 +497,72 @@ func (er *Runner) getOutputSubject(channel string) string {
 	return outputSubject
 }
 
-// prepareOutputMessage will check the length of the message and compress it if necessary.
-// Fails on compressed messages bigger than the threshold.
-func (er *Runner) prepareOutputMessage(msg []byte) ([]byte, error) {
+// prepareOutputMessage compresses the message with the configured codec when it exceeds the maximum
+// size of the stream or the compression threshold, and returns it along with the codec used, if any.
+// Fails on compressed messages bigger than the maximum size.
+func (er *Runner) prepareOutputMessage(msg []byte) ([]byte, string, error) {
 	maxSize, err := er.getMaxMessageSize()
 	if err != nil {
-		return nil, fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
+		return nil, "", fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
 	}
 
+	options := compression.OptionsFromConfig(er.getConfig())
+
 	lenMsg := int64(len(msg))
-	if lenMsg <= maxSize {
-		return msg, nil
+	if lenMsg <= maxSize && !options.ShouldCompress(lenMsg) {
+		return msg, "", nil
 	}
 
-	outMsg, err := common.CompressData(msg)
+	outMsg, codec, err := options.Compress(msg)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	lenOutMsg := int64(len(outMsg))
 	if lenOutMsg > maxSize {
-		er.getLoggerWithName().V(1).Info("Compressed message size %s "+
-			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize))
-		return nil, errors.ErrMessageToBig
+		er.getLoggerWithName().V(1).Info(fmt.Sprintf("Compressed message size %s "+
+			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize)))
+		return nil, "", errors.ErrMessageToBig
 	}
 
 	er.getLoggerWithName().Info(fmt.Sprintf("Message prepared with original size %s "+
 		"and compressed size %s", sizeInMB(lenMsg), sizeInMB(lenOutMsg)))
 
-	return outMsg, nil
+	return outMsg, codec, nil
+}
+
+func (er *Runner) getResponseHandler(subject, typeURL string) Handler {
+	responseHandler := er.routeResponseHandler(subject, typeURL)
+
+	if responseHandler == nil || len(er.middlewares) == 0 {
+		return responseHandler
+	}
+
+	return Handler(runnerCommon.ChainMiddlewares(runnerCommon.Handler(responseHandler), er.middlewares...))
 }
 
-func (er *Runner) getResponseHandler(subject string) Handler {
+// routeResponseHandler returns the handler set for the node and the payload type, the payload
+// type, the node or the default one, in that order, or nil if none of them exists.
+func (er *Runner) routeResponseHandler(subject, typeURL string) Handler {
+	messageType := runnerCommon.TypeName(typeURL)
+
+	if responseHandler, ok := er.typeHandlers[typeRoute{node: subject, messageType: messageType}]; ok {
+		return responseHandler
+	}
+
+	if responseHandler, ok := er.typeHandlers[typeRoute{messageType: messageType}]; ok {
+		return responseHandler
+	}
+
 	if responseHandler, ok := er.responseHandlers[subject]; ok {
 		return responseHandler
 	}
 
-	// returns the default response handler, or nil if it doesn't exist
 	return er.responseHandlers["default"]
 }
 
 func (er *Runner) getMaxMessageSize() (int64, error) {
-	streamInfo, err := er.jetstream.StreamInfo(viper.GetString(common.ConfigNatsStreamKey))
+	streamInfo, err := er.jetstream.StreamInfo(er.getConfig().GetString(common.ConfigNatsStreamKey))
 	if err != nil {
 		return 0, fmt.Errorf("error getting stream's max message size: %w", err)
 	}
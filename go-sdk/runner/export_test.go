@@ -7,11 +7,19 @@ import (
 )
 
 func NewTestRunner(ns *nats.Conn, js nats.JetStreamContext) *Runner {
-	initializeConfiguration()
+	if err := initializeConfiguration(); err != nil {
+		panic(err)
+	}
+
+	logger, err := getLogger(nil)
+	if err != nil {
+		panic(err)
+	}
 
-	return &Runner{
-		logger:    getLogger(),
-		nats:      ns,
-		jetstream: js,
+	runner, err := newRunner(logger, ns, js, nil)
+	if err != nil {
+		panic(err)
 	}
+
+	return runner
 }
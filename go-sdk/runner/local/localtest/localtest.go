@@ -0,0 +1,345 @@
+// Package localtest runs workflows in the local simulator for the tests of the runners, stopping
+// and closing the simulator once the test is over.
+//
+// A typical runner test looks like:
+//
+//	sim := localtest.New(t, localtest.Pipeline(t, ""))
+//	sim.TaskRunner("transformer").WithHandler(myHandler)
+//	sim.ForwardExit()
+//	response, err := sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+//
+// The helpers sending requests and handling their responses expect the trigger and the exit of the
+// workflow to be named entrypoint and exit, as in Pipeline.
+package localtest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+	"gopkg.in/yaml.v3"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/exit"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const (
+	// Timeout bounds every run of a simulator.
+	Timeout = 10 * time.Second
+	// StreamName is the stream of the workflows built by Workflow.
+	StreamName = "test-product-v1-0-0-test-workflow"
+	// DeadLetterSubject is a subject of the stream retry policies can send dead letters to.
+	DeadLetterSubject = StreamName + ".dead-letter"
+	// EphemeralBucket is the object store holding the claim checks of the workflow.
+	EphemeralBucket = StreamName + "-ephemeral"
+
+	_entrypoint = "entrypoint"
+	_exit       = "exit"
+)
+
+// Subject returns the subject the given process sends its outputs to.
+func Subject(process string) string {
+	return StreamName + "." + process
+}
+
+// Consumer returns the durable consumer the given process reads the outputs of upstream with.
+func Consumer(upstream, process string) string {
+	return StreamName + "-" + upstream + "-" + process
+}
+
+// Trigger returns a trigger process subscribed to the given processes.
+func Trigger(name string, subscriptions ...string) local.Process {
+	return local.Process{Name: name, Type: local.TriggerProcess, Subscriptions: subscriptions}
+}
+
+// Task returns a task process subscribed to the given processes.
+func Task(name string, subscriptions ...string) local.Process {
+	return local.Process{Name: name, Type: local.TaskProcess, Subscriptions: subscriptions}
+}
+
+// Exit returns an exit process subscribed to the given processes.
+func Exit(name string, subscriptions ...string) local.Process {
+	return local.Process{Name: name, Type: local.ExitProcess, Subscriptions: subscriptions}
+}
+
+// Workflow returns a workflow of the test product made of the given processes. The settings,
+// written in YAML, are merged into the configuration of every process.
+func Workflow(t testing.TB, settings string, processes ...local.Process) *local.Workflow {
+	t.Helper()
+
+	workflow := &local.Workflow{
+		Product:   "test-product",
+		Version:   "v1.0.0",
+		Name:      "test-workflow",
+		Processes: processes,
+	}
+
+	require.NoError(t, yaml.Unmarshal([]byte(settings), &workflow.Settings))
+	require.NoError(t, workflow.Validate())
+
+	return workflow
+}
+
+// Pipeline returns the workflow most tests run: an entrypoint trigger sending requests to a
+// transformer task, whose outputs go to an exit answering the trigger.
+func Pipeline(t testing.TB, settings string) *local.Workflow {
+	t.Helper()
+
+	return Workflow(t, settings,
+		Trigger(_entrypoint, _exit),
+		Task("transformer", _entrypoint),
+		Exit(_exit, "transformer"),
+	)
+}
+
+// Simulator wraps a local.Simulator, failing the test on errors instead of returning them.
+type Simulator struct {
+	t   testing.TB
+	sim *local.Simulator
+	nc  *nats.Conn
+	js  nats.JetStreamContext
+}
+
+// New starts the NATS server of a simulator of the workflow, closed once the test is over.
+func New(t testing.TB, workflow *local.Workflow) *Simulator {
+	t.Helper()
+
+	sim, err := local.New(testr.NewWithInterface(t, testr.Options{Verbosity: 1}), workflow)
+	require.NoError(t, err)
+
+	t.Cleanup(sim.Close)
+
+	return &Simulator{t: t, sim: sim}
+}
+
+func (s *Simulator) URL() string {
+	return s.sim.URL()
+}
+
+func (s *Simulator) TriggerRunner(name string) *trigger.Runner {
+	s.t.Helper()
+
+	runner, err := s.sim.TriggerRunner(name)
+	require.NoError(s.t, err)
+
+	return runner
+}
+
+func (s *Simulator) TaskRunner(name string) *task.Runner {
+	s.t.Helper()
+
+	runner, err := s.sim.TaskRunner(name)
+	require.NoError(s.t, err)
+
+	return runner
+}
+
+func (s *Simulator) ExitRunner(name string) *exit.Runner {
+	s.t.Helper()
+
+	runner, err := s.sim.ExitRunner(name)
+	require.NoError(s.t, err)
+
+	return runner
+}
+
+// Conn returns a connection to the NATS server of the simulator.
+func (s *Simulator) Conn() *nats.Conn {
+	s.t.Helper()
+
+	if s.nc == nil {
+		nc, err := nats.Connect(s.sim.URL())
+		require.NoError(s.t, err)
+
+		s.t.Cleanup(nc.Close)
+
+		s.nc = nc
+	}
+
+	return s.nc
+}
+
+// JetStream returns a JetStream context on the NATS server of the simulator.
+func (s *Simulator) JetStream() nats.JetStreamContext {
+	s.t.Helper()
+
+	if s.js == nil {
+		js, err := s.Conn().JetStream()
+		require.NoError(s.t, err)
+
+		s.js = js
+	}
+
+	return s.js
+}
+
+// Start runs the simulator in the background for at most Timeout. The returned function, also
+// called once the test is over, stops it and checks it returned no error.
+func (s *Simulator) Start() (stop func()) {
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- s.sim.Run(ctx)
+	}()
+
+	var once sync.Once
+
+	stop = func() {
+		once.Do(func() {
+			cancel()
+			require.NoError(s.t, <-done)
+		})
+	}
+
+	s.t.Cleanup(stop)
+
+	return stop
+}
+
+// SendRequest registers an entrypoint trigger sending a single request with the given payload.
+func (s *Simulator) SendRequest(request proto.Message) {
+	s.TriggerRunner(_entrypoint).WithRunner(func(_ *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		_ = kaiSDK.Messaging.SendOutputWithRequestID(request, uuid.New().String())
+	})
+}
+
+// ForwardExit registers an exit answering the trigger with every output and error it receives.
+func (s *Simulator) ForwardExit() {
+	s.ExitRunner(_exit).WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		if kaiSDK.Messaging.IsMessageError() {
+			return kaiSDK.Messaging.SendError(kaiSDK.Messaging.GetErrorMessage())
+		}
+
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+}
+
+// DiscardExit registers an exit dropping every message it receives.
+func (s *Simulator) DiscardExit() {
+	s.ExitRunner(_exit).WithHandler(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+}
+
+// Request runs the simulator until the trigger gets the response of a single request, made with
+// trigger.Runner.Request and the given timeout.
+func (s *Simulator) Request(request proto.Message, timeout time.Duration) (*anypb.Any, error) {
+	s.t.Helper()
+
+	type result struct {
+		response *anypb.Any
+		err      error
+	}
+
+	results := make(chan result, 1)
+
+	s.TriggerRunner(_entrypoint).WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		response, err := tr.Request(ctx, request)
+		results <- result{response: response, err: err}
+	})
+
+	stop := s.Start()
+	defer stop()
+
+	select {
+	case res := <-results:
+		return res.response, res.err
+	case <-time.After(Timeout):
+		return nil, context.DeadlineExceeded
+	}
+}
+
+// RequestString sends the value as a request and returns the string value it is answered with.
+func (s *Simulator) RequestString(value string) string {
+	s.t.Helper()
+
+	response, err := s.Request(wrapperspb.String(value), Timeout)
+	require.NoError(s.t, err)
+
+	answer := &wrapperspb.StringValue{}
+	require.NoError(s.t, response.UnmarshalTo(answer))
+
+	return answer.GetValue()
+}
+
+// Stream runs the simulator until the stream of a single request, opened with
+// trigger.Runner.Stream and the given timeout, is closed, and returns the messages received.
+func (s *Simulator) Stream(request proto.Message, timeout time.Duration) []trigger.StreamMessage {
+	s.t.Helper()
+
+	results := make(chan []trigger.StreamMessage, 1)
+
+	s.TriggerRunner(_entrypoint).WithRunner(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		requestID := uuid.New().String()
+		stream, stop := tr.Stream(ctx, requestID)
+
+		defer stop()
+
+		if err := kaiSDK.Messaging.SendOutputWithRequestID(request, requestID); err != nil {
+			results <- nil
+			return
+		}
+
+		var messages []trigger.StreamMessage
+		for message := range stream {
+			messages = append(messages, message)
+		}
+
+		results <- messages
+	})
+
+	stop := s.Start()
+	defer stop()
+
+	select {
+	case messages := <-results:
+		return messages
+	case <-time.After(Timeout):
+		return nil
+	}
+}
+
+// RunUntilDeadLetter runs the simulator until a message is published to DeadLetterSubject, and
+// returns it.
+func (s *Simulator) RunUntilDeadLetter() *nats.Msg {
+	s.t.Helper()
+
+	deadLetters, err := s.Conn().SubscribeSync(DeadLetterSubject)
+	require.NoError(s.t, err)
+
+	stop := s.Start()
+	defer stop()
+
+	deadLetter, err := deadLetters.NextMsg(Timeout)
+	require.NoError(s.t, err)
+
+	return deadLetter
+}
+
+// CountStoredMessages returns the number of messages stored in the stream of the workflow for the subject.
+func (s *Simulator) CountStoredMessages(subject string) uint64 {
+	s.t.Helper()
+
+	info, err := s.JetStream().StreamInfo(StreamName, &nats.StreamInfoRequest{SubjectsFilter: subject})
+	require.NoError(s.t, err)
+
+	return info.State.Subjects[subject]
+}
@@ -0,0 +1,453 @@
+// Package local runs a whole KAI workflow, trigger, tasks and exit, inside a single Go process
+// against an embedded NATS JetStream server, so processes can be tested end to end without the
+// KAI platform.
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/exit"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const (
+	_simulatorLoggerName = "[LOCAL SIMULATOR]"
+	_serverReadyTimeout  = 10 * time.Second
+	_consumersTimeout    = 10 * time.Second
+	_consumersPollPeriod = 10 * time.Millisecond
+	_defaultAckWaitTime  = 22 * time.Hour
+)
+
+type runContextFunc func(ctx context.Context)
+
+type localRunner struct {
+	run    runContextFunc
+	runner any
+}
+
+// Simulator hosts the embedded NATS server and the runners of every process of a workflow.
+type Simulator struct {
+	logger      logr.Logger
+	workflow    *Workflow
+	storeDir    string
+	server      *server.Server
+	connections []*nats.Conn
+
+	mu      sync.Mutex
+	runners map[string]*localRunner
+}
+
+// New starts an embedded NATS JetStream server and creates the stream, key-value stores and
+// object store the workflow processes expect.
+func New(logger logr.Logger, workflow *Workflow) (*Simulator, error) {
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	storeDir, err := os.MkdirTemp("", "kai-local-")
+	if err != nil {
+		return nil, fmt.Errorf("error creating JetStream store directory: %w", err)
+	}
+
+	sim := &Simulator{
+		logger:   logger.WithName(_simulatorLoggerName),
+		workflow: workflow,
+		storeDir: storeDir,
+		runners:  make(map[string]*localRunner),
+	}
+
+	if err := sim.startServer(); err != nil {
+		sim.Close()
+		return nil, err
+	}
+
+	if err := sim.createInfrastructure(); err != nil {
+		sim.Close()
+		return nil, err
+	}
+
+	return sim, nil
+}
+
+// URL returns the client URL of the embedded NATS server.
+func (s *Simulator) URL() string {
+	return s.server.ClientURL()
+}
+
+// TriggerRunner returns the runner of the given trigger process, creating it on the first call.
+func (s *Simulator) TriggerRunner(name string) (*trigger.Runner, error) {
+	r, err := s.getRunner(name, TriggerProcess, func(kaiSDK sdk.KaiSDK, nc *nats.Conn, js nats.JetStreamContext,
+		config *viper.Viper,
+	) (any, runContextFunc) {
+		tr := trigger.NewTriggerRunnerWithSDK(kaiSDK, nc, js, config)
+		return tr, tr.RunContext
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*trigger.Runner), nil
+}
+
+// TaskRunner returns the runner of the given task process, creating it on the first call.
+func (s *Simulator) TaskRunner(name string) (*task.Runner, error) {
+	r, err := s.getRunner(name, TaskProcess, func(kaiSDK sdk.KaiSDK, nc *nats.Conn, js nats.JetStreamContext,
+		config *viper.Viper,
+	) (any, runContextFunc) {
+		tr := task.NewTaskRunnerWithSDK(kaiSDK, nc, js, config)
+		return tr, tr.RunContext
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*task.Runner), nil
+}
+
+// ExitRunner returns the runner of the given exit process, creating it on the first call.
+func (s *Simulator) ExitRunner(name string) (*exit.Runner, error) {
+	r, err := s.getRunner(name, ExitProcess, func(kaiSDK sdk.KaiSDK, nc *nats.Conn, js nats.JetStreamContext,
+		config *viper.Viper,
+	) (any, runContextFunc) {
+		er := exit.NewExitRunnerWithSDK(kaiSDK, nc, js, config)
+		return er, er.RunContext
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.(*exit.Runner), nil
+}
+
+// Run runs every process of the workflow until the given context is done. Tasks and exits are
+// started first, and triggers once every task and exit is subscribed to its inputs, so no
+// message published by a trigger is lost.
+func (s *Simulator) Run(ctx context.Context) error {
+	runners, err := s.getRunners()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+
+	start := func(r *localRunner) {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			r.run(ctx)
+		}()
+	}
+
+	expectedConsumers := 0
+
+	for _, process := range s.workflow.Processes {
+		if process.Type != TriggerProcess {
+			start(runners[process.Name])
+
+			expectedConsumers += len(process.Subscriptions)
+		}
+	}
+
+	err = s.waitForConsumers(ctx, expectedConsumers)
+	if err != nil {
+		cancel()
+	} else {
+		for _, process := range s.workflow.Processes {
+			if process.Type == TriggerProcess {
+				start(runners[process.Name])
+			}
+		}
+	}
+
+	wg.Wait()
+
+	return err
+}
+
+func (s *Simulator) getRunners() (map[string]*localRunner, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var missing []string
+
+	runners := make(map[string]*localRunner, len(s.runners))
+
+	for _, process := range s.workflow.Processes {
+		r, ok := s.runners[process.Name]
+		if !ok {
+			missing = append(missing, process.Name)
+			continue
+		}
+
+		runners[process.Name] = r
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingRunner, strings.Join(missing, ", "))
+	}
+
+	return runners, nil
+}
+
+// Close closes the NATS connections, shuts down the embedded server and removes its storage.
+func (s *Simulator) Close() {
+	for _, nc := range s.connections {
+		nc.Close()
+	}
+
+	if s.server != nil {
+		s.server.Shutdown()
+		s.server.WaitForShutdown()
+	}
+
+	if err := os.RemoveAll(s.storeDir); err != nil {
+		s.logger.Error(err, "Error removing JetStream store directory")
+	}
+}
+
+func (s *Simulator) startServer() error {
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  s.storeDir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating embedded NATS server: %w", err)
+	}
+
+	s.server = srv
+
+	go srv.Start()
+
+	if !srv.ReadyForConnections(_serverReadyTimeout) {
+		return fmt.Errorf("embedded NATS server not ready after %s", _serverReadyTimeout) //nolint:goerr113 // dynamic error
+	}
+
+	s.logger.V(1).Info(fmt.Sprintf("Embedded NATS server listening on %s", srv.ClientURL()))
+
+	return nil
+}
+
+func (s *Simulator) createInfrastructure() error {
+	_, js, err := s.connect()
+	if err != nil {
+		return err
+	}
+
+	streamName := s.workflow.streamName()
+
+	_, err = js.AddStream(&nats.StreamConfig{
+		Name:     streamName,
+		Subjects: []string{s.workflow.subject(">")},
+	})
+	if err != nil {
+		return fmt.Errorf("error creating stream %s: %w", streamName, err)
+	}
+
+	buckets := []string{
+		s.workflow.bucketName("global"),
+		s.workflow.bucketName("product"),
+		s.workflow.bucketName("workflow"),
+	}
+
+	for _, process := range s.workflow.Processes {
+		buckets = append(buckets, s.workflow.bucketName("process-"+process.Name))
+	}
+
+	for _, bucket := range buckets {
+		if _, err := js.CreateKeyValue(&nats.KeyValueConfig{Bucket: bucket}); err != nil {
+			return fmt.Errorf("error creating key-value store %s: %w", bucket, err)
+		}
+	}
+
+	objectStore := s.workflow.bucketName("ephemeral")
+	if _, err := js.CreateObjectStore(&nats.ObjectStoreConfig{Bucket: objectStore}); err != nil {
+		return fmt.Errorf("error creating object store %s: %w", objectStore, err)
+	}
+
+	s.logger.V(1).Info(fmt.Sprintf("Created stream %s, key-value stores and object store", streamName))
+
+	return nil
+}
+
+func (s *Simulator) connect() (*nats.Conn, nats.JetStreamContext, error) {
+	nc, err := nats.Connect(s.server.ClientURL())
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to the embedded NATS server: %w", err)
+	}
+
+	s.connections = append(s.connections, nc)
+
+	js, err := nc.JetStream()
+	if err != nil {
+		return nil, nil, fmt.Errorf("error connecting to JetStream: %w", err)
+	}
+
+	return nc, js, nil
+}
+
+type runnerFactory func(kaiSDK sdk.KaiSDK, nc *nats.Conn, js nats.JetStreamContext, config *viper.Viper) (any, runContextFunc)
+
+func (s *Simulator) getRunner(name string, processType ProcessType, newRunner runnerFactory) (any, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	process, err := s.workflow.getProcess(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if process.Type != processType {
+		return nil, fmt.Errorf("%w: %s is a %s process", ErrUnknownProcess, name, process.Type)
+	}
+
+	if r, ok := s.runners[name]; ok {
+		return r.runner, nil
+	}
+
+	nc, js, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := s.processConfig(process)
+	if err != nil {
+		return nil, err
+	}
+
+	kaiSDK, err := sdk.New(
+		sdk.WithLogger(s.logger.WithName(fmt.Sprintf("[%s]", name))),
+		sdk.WithNatsConnection(nc),
+		sdk.WithJetStream(js),
+		sdk.WithViper(config),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing SDK of process %q: %w", name, err)
+	}
+
+	runner, run := newRunner(kaiSDK, nc, js, config)
+
+	s.runners[name] = &localRunner{
+		run:    run,
+		runner: runner,
+	}
+
+	return runner, nil
+}
+
+func (s *Simulator) processConfig(process Process) (*viper.Viper, error) {
+	wf := s.workflow
+	config := viper.New()
+
+	inputs := make([]string, 0, len(process.Subscriptions))
+	for _, subscription := range process.Subscriptions {
+		inputs = append(inputs, wf.subject(subscription))
+	}
+
+	values := map[string]any{
+		common.ConfigMetadataProductIDKey:           wf.Product,
+		common.ConfigMetadataVersionIDKey:           wf.Version,
+		common.ConfigMetadataWorkflowIDKey:          wf.Name,
+		common.ConfigMetadataWorkflowTypeKey:        wf.Type,
+		common.ConfigMetadataProcessIDKey:           process.Name,
+		common.ConfigMetadataProcessTypeKey:         string(process.Type),
+		common.ConfigNatsURLKey:                     s.server.ClientURL(),
+		common.ConfigNatsStreamKey:                  wf.streamName(),
+		common.ConfigNatsOutputKey:                  wf.subject(process.Name),
+		common.ConfigNatsInputsKey:                  inputs,
+		common.ConfigNatsEphemeralStorage:           wf.bucketName("ephemeral"),
+		common.ConfigCcGlobalBucketKey:              wf.bucketName("global"),
+		common.ConfigCcProductBucketKey:             wf.bucketName("product"),
+		common.ConfigCcWorkflowBucketKey:            wf.bucketName("workflow"),
+		common.ConfigCcProcessBucketKey:             wf.bucketName("process-" + process.Name),
+		common.ConfigCcProcessConfigKey:             process.Config,
+		common.ConfigRunnerSubscriberAckWaitTimeKey: _defaultAckWaitTime,
+		// Services outside NATS are disabled. Enabling them through the workflow settings points
+		// them at local endpoints unless those are overridden as well.
+		common.ConfigMinioEnabledKey:                false,
+		common.ConfigAuthEnabledKey:                 false,
+		common.ConfigRedisEnabledKey:                false,
+		common.ConfigModelRegistryEnabledKey:        false,
+		common.ConfigMeasurementsEnabledKey:         false,
+		common.ConfigMinioEndpointKey:               "localhost:9000",
+		common.ConfigMinioClientUserKey:             "kai",
+		common.ConfigMinioClientPasswordKey:         "kai",
+		common.ConfigMinioUseSslKey:                 false,
+		common.ConfigMinioBucketKey:                 sanitizeName(wf.Product),
+		common.ConfigMinioInternalFolderKey:         ".kai",
+		common.ConfigModelFolderNameKey:             ".models",
+		common.ConfigAuthEndpointKey:                "http://localhost:8080",
+		common.ConfigAuthClientKey:                  "kai",
+		common.ConfigAuthClientSecretKey:            "kai",
+		common.ConfigAuthRealmKey:                   "kai",
+		common.ConfigRedisEndpointKey:               "localhost:6379",
+		common.ConfigRedisUsernameKey:               "",
+		common.ConfigRedisPasswordKey:               "",
+		common.ConfigRedisIndexKey:                  "predictionsIdx",
+		common.ConfigMeasurementsEndpointKey:        "localhost:4317",
+		common.ConfigMeasurementsInsecureKey:        true,
+		common.ConfigMeasurementsTimeoutKey:         5,
+		common.ConfigMeasurementsMetricsIntervalKey: 3600,
+	}
+
+	for key, value := range values {
+		config.SetDefault(key, value)
+	}
+
+	if err := config.MergeConfigMap(wf.Settings); err != nil {
+		return nil, fmt.Errorf("error merging workflow settings: %w", err)
+	}
+
+	return config, nil
+}
+
+func (s *Simulator) waitForConsumers(ctx context.Context, expected int) error {
+	_, js, err := s.connect()
+	if err != nil {
+		return err
+	}
+
+	timeout := time.After(_consumersTimeout)
+
+	ticker := time.NewTicker(_consumersPollPeriod)
+	defer ticker.Stop()
+
+	for {
+		info, err := js.StreamInfo(s.workflow.streamName())
+		if err != nil {
+			return fmt.Errorf("error getting stream info: %w", err)
+		}
+
+		if info.State.Consumers >= expected {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-timeout:
+			return fmt.Errorf("processes not subscribed after %s", _consumersTimeout) //nolint:goerr113 // dynamic error
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,226 @@
+//go:build unit
+
+package local_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+type SimulatorTestSuite struct {
+	suite.Suite
+	logger   logr.Logger
+	workflow *local.Workflow
+}
+
+func (s *SimulatorTestSuite) SetupSuite() {
+	s.logger = testr.NewWithOptions(s.T(), testr.Options{Verbosity: 1})
+}
+
+func (s *SimulatorTestSuite) SetupTest() {
+	workflow, err := local.LoadWorkflow("testdata/workflow.yaml")
+	s.Require().NoError(err)
+
+	s.workflow = workflow
+}
+
+func (s *SimulatorTestSuite) TestRun_TriggerTaskAndExit_ExpectResponse() {
+	// Given
+	sim := localtest.New(s.T(), s.workflow)
+	sim.ForwardExit()
+
+	sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		value := &wrapperspb.StringValue{}
+		if err := payload.UnmarshalTo(value); err != nil {
+			return err
+		}
+
+		suffix, err := kaiSDK.CentralizedConfig.GetConfig("suffix")
+		if err != nil {
+			return err
+		}
+
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(strings.ToUpper(value.GetValue()) + suffix))
+	})
+
+	// When
+	response := sim.RequestString("hello")
+
+	// Then
+	s.Equal("HELLO!", response)
+}
+
+func (s *SimulatorTestSuite) TestRequest_TracingEnabled_ExpectSpansOfEveryNodeInSameTrace() {
+	// Given
+	tracing.MemoryExporter().Reset()
+
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), "tracing: {enabled: true, exporter: memory}"))
+	sim.ForwardExit()
+	sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String("traced"))
+	})
+
+	// When
+	_, err := sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().NoError(err)
+
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range tracing.MemoryExporter().GetSpans() {
+		spans[span.Name] = span
+	}
+
+	request, ok := spans["entrypoint request"]
+	s.Require().True(ok)
+
+	for _, name := range []string{"transformer process", "exit process", "entrypoint process", common.HandlerSpanName} {
+		span, ok := spans[name]
+		s.Require().True(ok, name)
+		s.Equal(request.SpanContext.TraceID(), span.SpanContext.TraceID(), name)
+	}
+}
+
+func (s *SimulatorTestSuite) TestRequest_OversizedPayload_ExpectClaimCheckResolvedAndKept() {
+	// Given
+	sim := localtest.New(s.T(), s.workflow)
+	sim.ForwardExit()
+
+	payload := make([]byte, 2*1024*1024)
+	_, err := rand.Read(payload)
+	s.Require().NoError(err)
+
+	sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		return kaiSDK.Messaging.SendOutput(&wrapperspb.BytesValue{Value: payload})
+	})
+
+	// When
+	response, err := sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().NoError(err)
+
+	value := &wrapperspb.BytesValue{}
+	s.Require().NoError(response.UnmarshalTo(value))
+	s.Equal(payload, value.GetValue())
+
+	objectStore, err := sim.JetStream().ObjectStore(localtest.EphemeralBucket)
+	s.Require().NoError(err)
+
+	// The claim checks of the outputs of the transformer and exit are kept until they expire.
+	objects, err := objectStore.List()
+	s.Require().NoError(err)
+	s.Len(objects, 2)
+}
+
+func (s *SimulatorTestSuite) TestRequest_CompressionCodecConfigured_ExpectPayloadDecoded() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), "nats: {compression: {codec: zstd, threshold: 1KB}}"))
+	sim.ForwardExit()
+
+	payload := bytes.Repeat([]byte("compressible payload "), 1024)
+
+	sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		return kaiSDK.Messaging.SendOutput(&wrapperspb.BytesValue{Value: payload})
+	})
+
+	// When
+	response, err := sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().NoError(err)
+
+	value := &wrapperspb.BytesValue{}
+	s.Require().NoError(response.UnmarshalTo(value))
+	s.Equal(payload, value.GetValue())
+}
+
+func (s *SimulatorTestSuite) TestRun_MissingRunner_ExpectError() {
+	// Given
+	sim, err := local.New(s.logger, s.workflow)
+	s.Require().NoError(err)
+
+	defer sim.Close()
+
+	_, err = sim.TaskRunner("transformer")
+	s.Require().NoError(err)
+
+	// When
+	err = sim.Run(context.Background())
+
+	// Then
+	s.ErrorIs(err, local.ErrMissingRunner)
+}
+
+func (s *SimulatorTestSuite) TestRunner_WrongProcessType_ExpectError() {
+	// Given
+	sim, err := local.New(s.logger, s.workflow)
+	s.Require().NoError(err)
+
+	defer sim.Close()
+
+	// When
+	_, taskErr := sim.TaskRunner("exit")
+	_, unknownErr := sim.ExitRunner("unknown")
+
+	// Then
+	s.ErrorIs(taskErr, local.ErrUnknownProcess)
+	s.ErrorIs(unknownErr, local.ErrUnknownProcess)
+}
+
+func (s *SimulatorTestSuite) TestParseWorkflow_UnknownSubscription_ExpectError() {
+	// Given
+	data := []byte(`
+product: test-product
+version: v1.0.0
+name: test-workflow
+processes:
+  - name: transformer
+    type: task
+    subscriptions: [missing]
+`)
+
+	// When
+	_, err := local.ParseWorkflow(data)
+
+	// Then
+	s.ErrorIs(err, local.ErrInvalidWorkflow)
+}
+
+func (s *SimulatorTestSuite) TestParseWorkflow_TaskWithoutSubscriptions_ExpectError() {
+	// Given
+	data := []byte(`
+product: test-product
+version: v1.0.0
+name: test-workflow
+processes:
+  - name: transformer
+    type: task
+`)
+
+	// When
+	_, err := local.ParseWorkflow(data)
+
+	// Then
+	s.ErrorIs(err, local.ErrInvalidWorkflow)
+}
+
+func TestSimulatorTestSuite(t *testing.T) {
+	suite.Run(t, new(SimulatorTestSuite))
+}
@@ -0,0 +1,168 @@
+package local
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidWorkflow = errors.New("invalid workflow")
+	ErrUnknownProcess  = errors.New("unknown process")
+	ErrMissingRunner   = errors.New("missing runner for process")
+)
+
+type ProcessType string
+
+const (
+	TriggerProcess ProcessType = "trigger"
+	TaskProcess    ProcessType = "task"
+	ExitProcess    ProcessType = "exit"
+)
+
+var _validName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Workflow describes the processes simulated in a single Go process.
+//
+//	product: my-product
+//	version: v1.0.0
+//	name: my-workflow
+//	type: data
+//	processes:
+//	  - name: entrypoint
+//	    type: trigger
+//	    subscriptions: [exit]
+//	  - name: transformer
+//	    type: task
+//	    subscriptions: [entrypoint]
+//	    config:
+//	      some-key: some-value
+//	  - name: exit
+//	    type: exit
+//	    subscriptions: [transformer]
+//
+// Subscriptions name the processes, optionally followed by a channel ("transformer.channel"),
+// whose output a process listens to. Process config is loaded into the process centralized
+// configuration. Settings are merged into the configuration of every process, so external
+// services such as MinIO or Redis can be pointed at real endpoints when a test needs them.
+type Workflow struct {
+	Product   string         `yaml:"product"`
+	Version   string         `yaml:"version"`
+	Name      string         `yaml:"name"`
+	Type      string         `yaml:"type"`
+	Settings  map[string]any `yaml:"settings"`
+	Processes []Process      `yaml:"processes"`
+}
+
+type Process struct {
+	Name          string            `yaml:"name"`
+	Type          ProcessType       `yaml:"type"`
+	Subscriptions []string          `yaml:"subscriptions"`
+	Config        map[string]string `yaml:"config"`
+}
+
+// LoadWorkflow reads and validates the workflow described in the given YAML file.
+func LoadWorkflow(path string) (*Workflow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading workflow file: %w", err)
+	}
+
+	return ParseWorkflow(data)
+}
+
+// ParseWorkflow parses and validates a workflow described in YAML.
+func ParseWorkflow(data []byte) (*Workflow, error) {
+	workflow := &Workflow{}
+
+	if err := yaml.Unmarshal(data, workflow); err != nil {
+		return nil, fmt.Errorf("error parsing workflow: %w", err)
+	}
+
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+func (w *Workflow) Validate() error {
+	wrapErr := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidWorkflow, fmt.Sprintf(format, args...))
+	}
+
+	if w.Product == "" || w.Version == "" || w.Name == "" {
+		return wrapErr("product, version and name are required")
+	}
+
+	if len(w.Processes) == 0 {
+		return wrapErr("at least one process is required")
+	}
+
+	processes := make(map[string]bool, len(w.Processes))
+
+	for _, process := range w.Processes {
+		if !_validName.MatchString(process.Name) {
+			return wrapErr("invalid process name %q", process.Name)
+		}
+
+		if processes[process.Name] {
+			return wrapErr("duplicated process %q", process.Name)
+		}
+
+		processes[process.Name] = true
+	}
+
+	for _, process := range w.Processes {
+		switch process.Type {
+		case TriggerProcess:
+		case TaskProcess, ExitProcess:
+			if len(process.Subscriptions) == 0 {
+				return wrapErr("process %q must subscribe to at least one process", process.Name)
+			}
+		default:
+			return wrapErr("process %q has invalid type %q", process.Name, process.Type)
+		}
+
+		for _, subscription := range process.Subscriptions {
+			node, _, _ := strings.Cut(subscription, ".")
+			if !processes[node] {
+				return wrapErr("process %q subscribes to unknown process %q", process.Name, node)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *Workflow) getProcess(name string) (Process, error) {
+	for _, process := range w.Processes {
+		if process.Name == name {
+			return process, nil
+		}
+	}
+
+	return Process{}, fmt.Errorf("%w: %s", ErrUnknownProcess, name)
+}
+
+func (w *Workflow) streamName() string {
+	return sanitizeName(fmt.Sprintf("%s-%s-%s", w.Product, w.Version, w.Name))
+}
+
+func (w *Workflow) subject(subscription string) string {
+	return fmt.Sprintf("%s.%s", w.streamName(), subscription)
+}
+
+func (w *Workflow) bucketName(scope string) string {
+	return fmt.Sprintf("%s-%s", w.streamName(), scope)
+}
+
+var _invalidNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+func sanitizeName(name string) string {
+	return _invalidNameChars.ReplaceAllString(name, "-")
+}
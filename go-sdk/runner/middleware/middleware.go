@@ -0,0 +1,158 @@
+// Package middleware provides middlewares for the handlers of task and exit runners.
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const (
+	_middlewareLoggerName = "[MIDDLEWARE]"
+
+	_handlerDurationMetric = "runner-handler-duration"
+	_handlerStatusOK       = "ok"
+	_handlerStatusError    = "error"
+)
+
+var (
+	ErrUnauthorized   = errors.New("unauthorized message")
+	ErrInvalidPayload = errors.New("invalid payload")
+)
+
+// Recovery turns a panic in the handler into an error wrapping common.ErrHandlerPanic, logging
+// its stack trace.
+func Recovery() common.Middleware {
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) (err error) {
+			defer common.RecoverPanic(kaiSDK.Logger.WithName(_middlewareLoggerName), func(panicErr error) {
+				err = panicErr
+			})
+
+			return next(kaiSDK, payload)
+		}
+	}
+}
+
+// Logging logs every message handled and the error returned by the handler, if any.
+func Logging() common.Middleware {
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			logger := kaiSDK.Logger.WithName(_middlewareLoggerName)
+
+			logger.Info("Handling message", "payload_type", payload.GetTypeUrl())
+
+			err := next(kaiSDK, payload)
+			if err != nil {
+				logger.Error(err, "Error handling message", "payload_type", payload.GetTypeUrl())
+				return err
+			}
+
+			logger.Info("Message handled", "payload_type", payload.GetTypeUrl())
+
+			return nil
+		}
+	}
+}
+
+// Timing logs how long the handler takes.
+func Timing() common.Middleware {
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			start := time.Now()
+
+			defer func() {
+				kaiSDK.Logger.WithName(_middlewareLoggerName).
+					Info(fmt.Sprintf("Handler execution time: %d ms", time.Since(start).Milliseconds()))
+			}()
+
+			return next(kaiSDK, payload)
+		}
+	}
+}
+
+// Metrics records how long the handler takes in the runner-handler-duration histogram, with a
+// status attribute telling whether it returned an error. The histogram is created once, from the
+// metrics client of the first message handled.
+func Metrics() common.Middleware {
+	var (
+		once         sync.Once
+		histogram    metric.Int64Histogram
+		histogramErr error
+	)
+
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			start := time.Now()
+
+			err := next(kaiSDK, payload)
+
+			once.Do(func() {
+				histogram, histogramErr = kaiSDK.Measurements.GetMetricsClient().Int64Histogram(
+					_handlerDurationMetric,
+					metric.WithDescription("How long the handler takes to process a message."),
+					metric.WithUnit("ms"),
+				)
+			})
+
+			if histogramErr != nil {
+				kaiSDK.Logger.WithName(_middlewareLoggerName).Error(histogramErr, "Error initializing metric")
+				return err
+			}
+
+			status := _handlerStatusOK
+			if err != nil {
+				status = _handlerStatusError
+			}
+
+			histogram.Record(kaiSDK.Context(), time.Since(start).Milliseconds(),
+				metric.WithAttributes(
+					attribute.String("process", kaiSDK.Metadata.GetProcess()),
+					attribute.String("status", status),
+				),
+			)
+
+			return err
+		}
+	}
+}
+
+// Authorize runs the given function before the handler, which is skipped when the function
+// returns an error. The error returned wraps ErrUnauthorized.
+func Authorize(authorize func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error) common.Middleware {
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			if err := authorize(kaiSDK, payload); err != nil {
+				return fmt.Errorf("%w: %w", ErrUnauthorized, err)
+			}
+
+			return next(kaiSDK, payload)
+		}
+	}
+}
+
+// ValidatePayload checks the payload with the given function before the handler, which is
+// skipped when the payload is missing or invalid. The error returned wraps ErrInvalidPayload.
+func ValidatePayload(validate func(payload *anypb.Any) error) common.Middleware {
+	return func(next common.Handler) common.Handler {
+		return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			if payload == nil {
+				return fmt.Errorf("%w: the payload is empty", ErrInvalidPayload)
+			}
+
+			if err := validate(payload); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidPayload, err)
+			}
+
+			return next(kaiSDK, payload)
+		}
+	}
+}
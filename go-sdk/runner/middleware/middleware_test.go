@@ -0,0 +1,208 @@
+//go:build unit
+
+package middleware_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/middleware"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	fake    *sdktest.SDK
+	kaiSDK  sdk.KaiSDK
+	payload *anypb.Any
+}
+
+func (s *MiddlewareTestSuite) SetupTest() {
+	s.fake = sdktest.New()
+	s.kaiSDK = s.fake.KaiSDK()
+	s.kaiSDK.Logger = testr.NewWithOptions(s.T(), testr.Options{Verbosity: 1})
+
+	payload, err := anypb.New(&wrappers.StringValue{Value: "test"})
+	s.Require().NoError(err)
+
+	s.payload = payload
+}
+
+func (s *MiddlewareTestSuite) TestChainMiddlewares_ExpectFirstMiddlewareOutermost() {
+	// Given
+	var calls []string
+
+	record := func(name string) common.Middleware {
+		return func(next common.Handler) common.Handler {
+			return func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+				calls = append(calls, name+" before")
+				err := next(kaiSDK, payload)
+				calls = append(calls, name+" after")
+
+				return err
+			}
+		}
+	}
+
+	handler := common.ChainMiddlewares(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record("first"), record("second"))
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal([]string{"first before", "second before", "handler", "second after", "first after"}, calls)
+}
+
+func (s *MiddlewareTestSuite) TestRecovery_HandlerPanics_ExpectError() {
+	// Given
+	handler := middleware.Recovery()(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		panic("something went wrong")
+	})
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.ErrorIs(err, common.ErrHandlerPanic)
+	s.ErrorContains(err, "something went wrong")
+}
+
+func (s *MiddlewareTestSuite) TestAuthorize_Unauthorized_ExpectHandlerSkipped() {
+	// Given
+	called := false
+	handler := middleware.Authorize(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return errors.New("missing permission")
+	})(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		called = true
+		return nil
+	})
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.ErrorIs(err, middleware.ErrUnauthorized)
+	s.False(called)
+}
+
+func (s *MiddlewareTestSuite) TestValidatePayload_EmptyPayload_ExpectHandlerSkipped() {
+	// Given
+	called := false
+	handler := middleware.ValidatePayload(func(_ *anypb.Any) error {
+		return nil
+	})(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		called = true
+		return nil
+	})
+
+	// When
+	err := handler(s.kaiSDK, nil)
+
+	// Then
+	s.ErrorIs(err, middleware.ErrInvalidPayload)
+	s.False(called)
+}
+
+func (s *MiddlewareTestSuite) TestValidatePayload_ValidPayload_ExpectHandlerCalled() {
+	// Given
+	called := false
+	handler := middleware.ValidatePayload(func(payload *anypb.Any) error {
+		if !payload.MessageIs(&wrappers.StringValue{}) {
+			return errors.New("unexpected type")
+		}
+
+		return nil
+	})(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		called = true
+		return nil
+	})
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.Require().NoError(err)
+	s.True(called)
+}
+
+func (s *MiddlewareTestSuite) TestMetrics_ExpectDurationRecordedWithStatus() {
+	// Given
+	handler := middleware.Metrics()(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return errors.New("handler error")
+	})
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.Require().Error(err)
+
+	rm, err := s.fake.Measurements.Collect(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(rm.ScopeMetrics, 1)
+	s.Require().Len(rm.ScopeMetrics[0].Metrics, 1)
+	s.Equal("runner-handler-duration", rm.ScopeMetrics[0].Metrics[0].Name)
+
+	histogram, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[int64])
+	s.Require().True(ok)
+	s.Require().Len(histogram.DataPoints, 1)
+
+	status, ok := histogram.DataPoints[0].Attributes.Value("status")
+	s.Require().True(ok)
+	s.Equal("error", status.AsString())
+}
+
+func (s *MiddlewareTestSuite) TestMetrics_SeveralMessages_ExpectOneHistogram() {
+	// Given
+	metrics := middleware.Metrics()
+	handler := func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	}
+
+	// When
+	for i := 0; i < 3; i++ {
+		s.Require().NoError(common.ChainMiddlewares(handler, metrics)(s.kaiSDK, s.payload))
+	}
+
+	// Then
+	rm, err := s.fake.Measurements.Collect(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(rm.ScopeMetrics, 1)
+	s.Require().Len(rm.ScopeMetrics[0].Metrics, 1)
+
+	histogram, ok := rm.ScopeMetrics[0].Metrics[0].Data.(metricdata.Histogram[int64])
+	s.Require().True(ok)
+	s.Require().Len(histogram.DataPoints, 1)
+	s.Equal(uint64(3), histogram.DataPoints[0].Count)
+}
+
+func (s *MiddlewareTestSuite) TestLoggingAndTiming_ExpectHandlerErrorReturned() {
+	// Given
+	expectedErr := errors.New("handler error")
+	handler := common.ChainMiddlewares(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return expectedErr
+	}, middleware.Logging(), middleware.Timing())
+
+	// When
+	err := handler(s.kaiSDK, s.payload)
+
+	// Then
+	s.ErrorIs(err, expectedErr)
+}
+
+func TestMiddlewareTestSuite(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
@@ -0,0 +1,56 @@
+package runner
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+// Option configures how New creates a Runner.
+type Option func(*options)
+
+type options struct {
+	logger logr.Logger
+	nats   *nats.Conn
+	config *viper.Viper
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithLogger sets the logger of the runner. By default, a logger is built from the runner configuration.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithNatsConnection sets an existing NATS connection. Without it, the runner connects to the
+// configured NATS URL.
+func WithNatsConnection(nc *nats.Conn) Option {
+	return func(o *options) {
+		o.nats = nc
+	}
+}
+
+// WithViper sets the viper instance the runner reads its configuration from, instead of loading
+// the environment and the configuration files into the global one.
+func WithViper(config *viper.Viper) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+// WithConfig sets the configuration of the runner from a Config.
+func WithConfig(config sdk.Config) Option {
+	return WithViper(config.Viper())
+}
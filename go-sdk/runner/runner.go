@@ -1,6 +1,7 @@
 package runner
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,42 +12,124 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/exit"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/measurement"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 	"github.com/nats-io/nats.go"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 	"golang.org/x/exp/slices"
 )
 
+var ErrMissingConfigKey = errors.New("missing mandatory configuration key")
+
 type Runner struct {
 	logger    logr.Logger
 	nats      *nats.Conn
 	jetstream nats.JetStreamContext
+	config    *viper.Viper
+	sdk       sdk.KaiSDK
+	// sharedNats tells whether the NATS connection was given by the caller, who keeps owning it.
+	sharedNats bool
 }
 
+// NewRunner creates a Runner loading the configuration from the environment and the configuration
+// files. It panics when the runner cannot be initialized, use New to handle the error instead.
 func NewRunner() *Runner {
-	initializeConfiguration()
+	runner, err := New()
+	if err != nil {
+		panic(fmt.Errorf("fatal error initializing runner: %w", err))
+	}
+
+	return runner
+}
+
+// New creates a Runner with the given options. Unless a viper instance or a Config is given, the
+// configuration is loaded from the environment and the configuration files into the global viper.
+func New(opts ...Option) (*Runner, error) {
+	options := newOptions(opts...)
+
+	config := options.config
+
+	var err error
 
-	logger := getLogger()
+	if config == nil {
+		err = initializeConfiguration()
+	} else {
+		err = initializeConfigurationWithConfig(config)
+	}
 
-	nc, err := getNatsConnection(logger)
 	if err != nil {
-		panic(fmt.Errorf("fatal error connecting to NATS: %w", err))
+		return nil, err
+	}
+
+	logger := options.logger
+	if logger.GetSink() == nil {
+		logger, err = getLogger(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	nc := options.nats
+	if nc == nil {
+		nc, err = getNatsConnection(logger, config)
+		if err != nil {
+			return nil, fmt.Errorf("error connecting to NATS: %w", err)
+		}
+	}
+
+	// The connection dialed here is closed if the runner cannot be created, an injected one is
+	// left to its owner.
+	closeOwnConnection := func() {
+		if options.nats == nil {
+			nc.Close()
+		}
 	}
 
 	js, err := getJetStreamConnection(logger, nc)
 	if err != nil {
-		panic(fmt.Errorf("fatal error connecting to JetStream: %w", err))
+		closeOwnConnection()
+		return nil, fmt.Errorf("error connecting to JetStream: %w", err)
+	}
+
+	runner, err := newRunner(logger, nc, js, config)
+	if err != nil {
+		closeOwnConnection()
+		return nil, err
+	}
+
+	runner.sharedNats = options.nats != nil
+
+	return runner, nil
+}
+
+func newRunner(logger logr.Logger, nc *nats.Conn, js nats.JetStreamContext, config *viper.Viper) (*Runner, error) {
+	kaiSDK, err := sdk.New(
+		sdk.WithLogger(logger),
+		sdk.WithNatsConnection(nc),
+		sdk.WithJetStream(js),
+		sdk.WithViper(config),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing SDK: %w", err)
 	}
 
 	return &Runner{
 		logger:    logger,
 		nats:      nc,
 		jetstream: js,
-	}
+		config:    config,
+		sdk:       kaiSDK,
+	}, nil
 }
 
-func validateConfig(keys []string) {
-	var mandatoryConfigKeys = []string{
+// validateConfig checks that every mandatory key is set and that the compression codec is
+// registered. The keys of the persistent storage, model registry, authentication, predictions,
+// measurements, tracing and idempotency guard are only mandatory when those subsystems are enabled.
+func validateConfig(config *viper.Viper) error {
+	mandatoryConfigKeys := []string{
 		common.ConfigMetadataProductIDKey,
 		common.ConfigMetadataVersionIDKey,
 		common.ConfigMetadataWorkflowIDKey,
@@ -60,33 +143,98 @@ func validateConfig(keys []string) {
 		common.ConfigCcProductBucketKey,
 		common.ConfigCcWorkflowBucketKey,
 		common.ConfigCcProcessBucketKey,
-		common.ConfigMinioEndpointKey,
-		common.ConfigMinioClientUserKey,
-		common.ConfigMinioClientPasswordKey,
-		common.ConfigMinioUseSslKey,
-		common.ConfigMinioBucketKey,
-		common.ConfigAuthEndpointKey,
-		common.ConfigAuthClientKey,
-		common.ConfigAuthClientSecretKey,
-		common.ConfigAuthRealmKey,
-		common.ConfigRedisUsernameKey,
-		common.ConfigRedisPasswordKey,
-		common.ConfigRedisEndpointKey,
-		common.ConfigRedisIndexKey,
-		common.ConfigMeasurementsEndpointKey,
-		common.ConfigMeasurementsInsecureKey,
-		common.ConfigMeasurementsTimeoutKey,
-		common.ConfigMeasurementsMetricsIntervalKey,
 	}
 
+	minioEnabled := common.IsEnabled(config, common.ConfigMinioEnabledKey) ||
+		common.IsEnabled(config, common.ConfigModelRegistryEnabledKey)
+
+	if minioEnabled {
+		mandatoryConfigKeys = append(mandatoryConfigKeys,
+			common.ConfigMinioEndpointKey,
+			common.ConfigMinioClientUserKey,
+			common.ConfigMinioClientPasswordKey,
+			common.ConfigMinioUseSslKey,
+			common.ConfigMinioBucketKey,
+		)
+	}
+
+	if minioEnabled && common.IsEnabled(config, common.ConfigAuthEnabledKey) {
+		mandatoryConfigKeys = append(mandatoryConfigKeys,
+			common.ConfigAuthEndpointKey,
+			common.ConfigAuthClientKey,
+			common.ConfigAuthClientSecretKey,
+			common.ConfigAuthRealmKey,
+		)
+	}
+
+	if common.IsEnabled(config, common.ConfigRedisEnabledKey) {
+		mandatoryConfigKeys = append(mandatoryConfigKeys,
+			common.ConfigRedisUsernameKey,
+			common.ConfigRedisPasswordKey,
+			common.ConfigRedisEndpointKey,
+			common.ConfigRedisIndexKey,
+		)
+	}
+
+	if common.IsEnabled(config, common.ConfigMeasurementsEnabledKey) {
+		mandatoryConfigKeys = append(mandatoryConfigKeys, getMeasurementsConfigKeys(config)...)
+	}
+
+	if config.GetBool(common.ConfigTracingEnabledKey) {
+		mandatoryConfigKeys = append(mandatoryConfigKeys, getTracingConfigKeys(config)...)
+	}
+
+	if config.GetBool(common.ConfigRunnerSubscriberIdempotencyEnabledKey) {
+		mandatoryConfigKeys = append(mandatoryConfigKeys, common.ConfigRunnerSubscriberIdempotencyBucketKey)
+	}
+
+	keys := config.AllKeys()
+
+	var errs []error
+
 	for _, key := range mandatoryConfigKeys {
 		if !slices.Contains(keys, key) {
-			panic(fmt.Sprintf("missing mandatory configuration key: %s", key))
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingConfigKey, key))
 		}
 	}
+
+	if _, err := compression.Get(compression.OptionsFromConfig(config).Codec); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
 }
 
-func initializeConfiguration() {
+// getMeasurementsConfigKeys returns the mandatory keys of the configured metrics exporter. Only the
+// OTLP exporters push to an endpoint, and the Prometheus and none exporters export nothing periodically.
+func getMeasurementsConfigKeys(config *viper.Viper) []string {
+	switch config.GetString(common.ConfigMeasurementsExporterKey) {
+	case measurement.ExporterPrometheus, measurement.ExporterNone:
+		return nil
+	case measurement.ExporterStdout:
+		return []string{common.ConfigMeasurementsMetricsIntervalKey}
+	default:
+		return []string{
+			common.ConfigMeasurementsEndpointKey,
+			common.ConfigMeasurementsInsecureKey,
+			common.ConfigMeasurementsTimeoutKey,
+			common.ConfigMeasurementsMetricsIntervalKey,
+		}
+	}
+}
+
+// getTracingConfigKeys returns the mandatory keys of the configured tracing exporter. Only the OTLP
+// exporters need an endpoint.
+func getTracingConfigKeys(config *viper.Viper) []string {
+	switch config.GetString(common.ConfigTracingExporterKey) {
+	case tracing.ExporterStdout, tracing.ExporterMemory, tracing.ExporterNone:
+		return nil
+	default:
+		return []string{common.ConfigTracingEndpointKey}
+	}
+}
+
+func initializeConfiguration() error {
 	// Load environment variables
 	viper.SetEnvPrefix("KAI")
 	viper.AutomaticEnv()
@@ -113,23 +261,42 @@ func initializeConfiguration() {
 
 	keys := viper.AllKeys()
 	if len(keys) == 0 {
-		panic(fmt.Errorf("configuration could not be loaded: %w", err))
+		return fmt.Errorf("configuration could not be loaded: %w", err)
 	}
 
-	validateConfig(keys)
+	return initializeConfigurationWithConfig(viper.GetViper())
+}
+
+func initializeConfigurationWithConfig(config *viper.Viper) error {
+	if err := validateConfig(config); err != nil {
+		return err
+	}
 
 	// Set viper default values
-	viper.SetDefault(common.ConfigRunnerSubscriberAckWaitTimeKey, 22*time.Hour)
-	viper.SetDefault(common.ConfigRunnerLoggerLevelKey, "InfoLevel")
-	viper.SetDefault(common.ConfigRunnerLoggerEncodingKey, "json")
-	viper.SetDefault(common.ConfigRunnerLoggerOutputPathsKey, []string{"stdout"})
-	viper.SetDefault(common.ConfigRunnerLoggerErrorOutputPathsKey, []string{"stderr"})
-	viper.SetDefault(common.ConfigMinioInternalFolderKey, ".kai")
-	viper.SetDefault(common.ConfigModelFolderNameKey, ".models")
+	config.SetDefault(common.ConfigRunnerSubscriberAckWaitTimeKey, 22*time.Hour)
+	config.SetDefault(common.ConfigRunnerSubscriberMaxConcurrencyKey, 1)
+	config.SetDefault(common.ConfigRunnerSubscriberRetryMaxDeliveriesKey, 1)
+	config.SetDefault(common.ConfigRunnerSubscriberRetryInitialBackoffKey, time.Second)
+	config.SetDefault(common.ConfigRunnerSubscriberRetryMaxBackoffKey, time.Minute)
+	config.SetDefault(common.ConfigRunnerSubscriberInactiveThresholdKey, time.Hour)
+	config.SetDefault(common.ConfigRunnerSubscriberIdempotencyTTLKey, 24*time.Hour)
+	config.SetDefault(common.ConfigRunnerShutdownGracePeriodKey, 20*time.Second)
+	config.SetDefault(common.ConfigNatsClaimCheckTTLKey, 24*time.Hour)
+	config.SetDefault(common.ConfigNatsClaimCheckSweepIntervalKey, time.Hour)
+	config.SetDefault(common.ConfigRunnerHealthAddressKey, ":8081")
+	config.SetDefault(common.ConfigRunnerHealthTimeoutKey, 5*time.Second)
+	config.SetDefault(common.ConfigRunnerLoggerLevelKey, "InfoLevel")
+	config.SetDefault(common.ConfigRunnerLoggerEncodingKey, "json")
+	config.SetDefault(common.ConfigRunnerLoggerOutputPathsKey, []string{"stdout"})
+	config.SetDefault(common.ConfigRunnerLoggerErrorOutputPathsKey, []string{"stderr"})
+	config.SetDefault(common.ConfigMinioInternalFolderKey, ".kai")
+	config.SetDefault(common.ConfigModelFolderNameKey, ".models")
+
+	return nil
 }
 
-func getNatsConnection(logger logr.Logger) (*nats.Conn, error) {
-	nc, err := nats.Connect(viper.GetString(common.ConfigNatsURLKey))
+func getNatsConnection(logger logr.Logger, config *viper.Viper) (*nats.Conn, error) {
+	nc, err := nats.Connect(common.ConfigOrGlobal(config).GetString(common.ConfigNatsURLKey))
 	if err != nil {
 		logger.Error(err, "Error connecting to NATS")
 
@@ -149,24 +316,25 @@ func getJetStreamConnection(logger logr.Logger, nc *nats.Conn) (nats.JetStreamCo
 	return js, nil
 }
 
-func getLogger() logr.Logger {
+func getLogger(cfg *viper.Viper) (logr.Logger, error) {
 	var log logr.Logger
 
+	cfg = common.ConfigOrGlobal(cfg)
 	config := zap.NewProductionConfig()
 
-	logLevel, err := zap.ParseAtomicLevel(viper.GetString(common.ConfigRunnerLoggerLevelKey))
+	logLevel, err := zap.ParseAtomicLevel(cfg.GetString(common.ConfigRunnerLoggerLevelKey))
 	if err != nil {
 		logLevel = zap.NewAtomicLevelAt(zap.InfoLevel)
 	}
 
 	config.Level = zap.NewAtomicLevelAt(logLevel.Level())
-	config.OutputPaths = viper.GetStringSlice(common.ConfigRunnerLoggerOutputPathsKey)
-	config.ErrorOutputPaths = viper.GetStringSlice(common.ConfigRunnerLoggerErrorOutputPathsKey)
-	config.Encoding = viper.GetString(common.ConfigRunnerLoggerEncodingKey)
+	config.OutputPaths = cfg.GetStringSlice(common.ConfigRunnerLoggerOutputPathsKey)
+	config.ErrorOutputPaths = cfg.GetStringSlice(common.ConfigRunnerLoggerErrorOutputPathsKey)
+	config.Encoding = cfg.GetString(common.ConfigRunnerLoggerEncodingKey)
 
 	logger, err := config.Build()
 	if err != nil {
-		panic("The logger could not be initialized")
+		return logr.Logger{}, fmt.Errorf("the logger could not be initialized: %w", err)
 	}
 
 	defer logger.Sync() //nolint:errcheck // Ignore error
@@ -175,17 +343,32 @@ func getLogger() logr.Logger {
 
 	log.WithName("[RUNNER CONFIG]").V(1).Info(fmt.Sprintf("Logger initialized with level %s", logLevel.String()))
 
-	return log
+	return log, nil
 }
 
 func (rn Runner) TriggerRunner() *trigger.Runner {
-	return trigger.NewTriggerRunner(rn.logger, rn.nats, rn.jetstream)
+	triggerRunner := trigger.NewTriggerRunnerWithSDK(rn.sdk, rn.nats, rn.jetstream, rn.config)
+	if rn.sharedNats {
+		triggerRunner.WithSharedConnection()
+	}
+
+	return triggerRunner
 }
 
 func (rn Runner) TaskRunner() *task.Runner {
-	return task.NewTaskRunner(rn.logger, rn.nats, rn.jetstream)
+	taskRunner := task.NewTaskRunnerWithSDK(rn.sdk, rn.nats, rn.jetstream, rn.config)
+	if rn.sharedNats {
+		taskRunner.WithSharedConnection()
+	}
+
+	return taskRunner
 }
 
 func (rn Runner) ExitRunner() *exit.Runner {
-	return exit.NewExitRunner(rn.logger, rn.nats, rn.jetstream)
+	exitRunner := exit.NewExitRunnerWithSDK(rn.sdk, rn.nats, rn.jetstream, rn.config)
+	if rn.sharedNats {
+		exitRunner.WithSharedConnection()
+	}
+
+	return exitRunner
 }
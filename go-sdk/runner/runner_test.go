@@ -4,8 +4,13 @@ package runner_test
 
 import (
 	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
@@ -13,6 +18,7 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/mocks"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
 )
 
 type SdkRunnerTestSuite struct {
@@ -123,6 +129,183 @@ func (s *SdkRunnerTestSuite) TestNewRunner_MissingMandatoryKey() {
 	viper.Set(common.ConfigNatsURLKey, natsURL)
 }
 
+func (s *SdkRunnerTestSuite) TestNew_MissingMandatoryKeys_ExpectError() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigMetadataProductIDKey, "test-product")
+
+	// When
+	_, err := runner.New(runner.WithViper(config))
+
+	// Then
+	s.ErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, common.ConfigNatsURLKey)
+	s.ErrorContains(err, common.ConfigMinioEndpointKey)
+}
+
+func (s *SdkRunnerTestSuite) TestNew_DisabledSubsystemsWithoutKeys_ExpectNoMissingKeys() {
+	// Given
+	config := viper.New()
+	for _, key := range viper.AllKeys() {
+		if !strings.HasPrefix(key, "minio.") && !strings.HasPrefix(key, "auth.") &&
+			!strings.HasPrefix(key, "predictions.") && !strings.HasPrefix(key, "measurements.") {
+			config.Set(key, viper.Get(key))
+		}
+	}
+
+	config.Set(common.ConfigNatsURLKey, "nats://127.0.0.1:1")
+	config.Set(common.ConfigMinioEnabledKey, false)
+	config.Set(common.ConfigModelRegistryEnabledKey, false)
+	config.Set(common.ConfigRedisEnabledKey, false)
+	config.Set(common.ConfigMeasurementsEnabledKey, false)
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.NotErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, "error connecting to NATS")
+}
+
+func (s *SdkRunnerTestSuite) TestNew_PrometheusExporterWithoutEndpoint_ExpectNoMissingKeys() {
+	// Given
+	config := viper.New()
+	for _, key := range viper.AllKeys() {
+		if !strings.HasPrefix(key, "measurements.") {
+			config.Set(key, viper.Get(key))
+		}
+	}
+
+	config.Set(common.ConfigNatsURLKey, "nats://127.0.0.1:1")
+	config.Set(common.ConfigMeasurementsExporterKey, "prometheus")
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.NotErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, "error connecting to NATS")
+}
+
+func (s *SdkRunnerTestSuite) TestNew_TracingEnabledWithoutEndpoint_ExpectMissingKey() {
+	// Given
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigTracingEnabledKey, true)
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.ErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, common.ConfigTracingEndpointKey)
+}
+
+func (s *SdkRunnerTestSuite) TestNew_TracingStdoutExporterWithoutEndpoint_ExpectNoMissingKeys() {
+	// Given
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigNatsURLKey, "nats://127.0.0.1:1")
+	config.Set(common.ConfigTracingEnabledKey, true)
+	config.Set(common.ConfigTracingExporterKey, "stdout")
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.NotErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, "error connecting to NATS")
+}
+
+func (s *SdkRunnerTestSuite) TestNew_IdempotencyEnabledWithoutBucket_ExpectMissingKey() {
+	// Given
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigRunnerSubscriberIdempotencyEnabledKey, true)
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.ErrorIs(err, runner.ErrMissingConfigKey)
+	s.ErrorContains(err, common.ConfigRunnerSubscriberIdempotencyBucketKey)
+}
+
+func (s *SdkRunnerTestSuite) TestNew_UnknownCompressionCodec_ExpectError() {
+	// Given
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigNatsCompressionCodecKey, "lz4")
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.ErrorIs(err, compression.ErrUnknownCodec)
+}
+
+func (s *SdkRunnerTestSuite) TestNew_UnreachableNats_ExpectError() {
+	// Given
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigNatsURLKey, "nats://127.0.0.1:1")
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.ErrorContains(err, "error connecting to NATS")
+}
+
+func (s *SdkRunnerTestSuite) TestNew_SDKInitializationFails_ExpectOwnConnectionClosed() {
+	// Given
+	srv := s.startNatsServer()
+
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+	config.Set(common.ConfigNatsURLKey, srv.ClientURL())
+
+	// When
+	_, err := runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()))
+
+	// Then
+	s.ErrorContains(err, "error initializing SDK")
+	s.Eventually(func() bool { return srv.NumClients() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func (s *SdkRunnerTestSuite) TestNew_SDKInitializationFails_ExpectInjectedConnectionKept() {
+	// Given
+	srv := s.startNatsServer()
+
+	nc, err := nats.Connect(srv.ClientURL())
+	s.Require().NoError(err)
+
+	defer nc.Close()
+
+	config := viper.New()
+	s.Require().NoError(config.MergeConfigMap(viper.AllSettings()))
+
+	// When
+	_, err = runner.New(runner.WithViper(config), runner.WithLogger(logr.Discard()), runner.WithNatsConnection(nc))
+
+	// Then
+	s.ErrorContains(err, "error initializing SDK")
+	s.False(nc.IsClosed())
+}
+
+// startNatsServer starts a NATS server without JetStream, so the SDK cannot be initialized.
+func (s *SdkRunnerTestSuite) startNatsServer() *server.Server {
+	srv, err := server.NewServer(&server.Options{Host: "127.0.0.1", Port: server.RANDOM_PORT})
+	s.Require().NoError(err)
+
+	go srv.Start()
+
+	s.Require().True(srv.ReadyForConnections(5 * time.Second))
+	s.T().Cleanup(srv.Shutdown)
+
+	return srv
+}
+
 func TestRunnerTestSuite(t *testing.T) {
 	suite.Run(t, new(SdkRunnerTestSuite))
 }
@@ -3,6 +3,8 @@ package task
 import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -14,10 +16,10 @@ const (
 	_finalizerLoggerName     = "[FINALIZER]"
 )
 
-func composeInitializer(initializer common.Initializer) common.Initializer {
+func composeInitializer(initializer common.Initializer, config *viper.Viper) common.Initializer {
 	return func(kaiSDK sdk.KaiSDK) {
 		kaiSDK.Logger.WithName(_initializerLoggerName).V(1).Info("Initializing TaskRunner...")
-		common.InitializeProcessConfiguration(kaiSDK)
+		common.InitializeProcessConfigurationWithConfig(kaiSDK, config)
 
 		if initializer != nil {
 			kaiSDK.Logger.WithName(_initializerLoggerName).V(3).Info("Executing user initializer...")
@@ -42,6 +44,12 @@ func composePreprocessor(preprocessor Preprocessor) Preprocessor {
 	}
 }
 
+// TypedHandler creates a Handler receiving the payload already unmarshalled into T. Messages
+// holding another type fail with an error wrapping common.ErrPayloadType.
+func TypedHandler[T proto.Message](handler func(kaiSDK sdk.KaiSDK, payload T) error) Handler {
+	return Handler(common.TypedHandler(handler))
+}
+
 func composeHandler(handler Handler) Handler {
 	return func(kaiSDK sdk.KaiSDK, response *anypb.Any) error {
 		kaiSDK.Logger.WithName(_handlerLoggerName).V(1).Info("Handling TaskRunner...")
@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -19,7 +17,9 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	runnerCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
 )
 
 const _subscriberLoggerName = "[SUBSCRIBER]"
@@ -28,8 +28,12 @@ func (tr *Runner) getLoggerWithName() logr.Logger {
 	return tr.sdk.Logger.WithName(_subscriberLoggerName)
 }
 
+func (tr *Runner) getConfig() *viper.Viper {
+	return common.ConfigOrGlobal(tr.config)
+}
+
 func (tr *Runner) startSubscriber() {
-	inputSubjects := viper.GetStringSlice(common.ConfigNatsInputsKey)
+	inputSubjects := tr.getConfig().GetStringSlice(common.ConfigNatsInputsKey)
 
 	if len(inputSubjects) == 0 {
 		tr.getLoggerWithName().Info("Undefined input subjects")
@@ -48,6 +52,17 @@ func (tr *Runner) startSubscriber() {
 		os.Exit(1)
 	}
 
+	tr.panicsMetric, err = tr.sdk.Measurements.GetMetricsClient().Int64Counter(
+		"runner-handler-panics-metric",
+		metric.WithDescription("How many panics were recovered while processing messages."),
+	)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, "Error initializing metric")
+		os.Exit(1)
+	}
+
+	dispatcher := runnerCommon.NewDispatcher(tr.getConcurrency())
+
 	subscriptions := make([]*nats.Subscription, 0, len(inputSubjects))
 
 	for _, subject := range inputSubjects {
@@ -59,11 +74,11 @@ func (tr *Runner) startSubscriber() {
 		s, err := tr.jetstream.QueueSubscribe(
 			subject,
 			consumerName,
-			tr.processMessage,
+			dispatcher.Dispatch(tr.processMessage),
 			nats.DeliverNew(),
 			nats.Durable(consumerName),
 			nats.ManualAck(),
-			nats.AckWait(viper.GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
+			nats.AckWait(tr.getConfig().GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
 		)
 		if err != nil {
 			tr.getLoggerWithName().Error(err, fmt.Sprintf("Error subscribing to subject %s", subject))
@@ -77,14 +92,34 @@ func (tr *Runner) startSubscriber() {
 
 	tr.getLoggerWithName().V(1).Info("Subscribed to all subjects successfully")
 
-	// Handle sigterm and await termChan signal
-	termChan := make(chan os.Signal, 1)
-	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
-	<-termChan
+	tr.health.SetReady(true)
+
+	// Handle sigterm or context cancellation
+	runnerCommon.WaitForShutdown(tr.ctx)
 
 	// Handle shutdown
 	tr.getLoggerWithName().Info("Shutdown signal received")
 
+	// Stop getting new work while the messages in process finish.
+	tr.health.SetReady(false)
+
+	// Messages received from now on are returned to NATS, to be processed by another replica.
+	dispatcher.Stop()
+
+	gracePeriod := tr.getShutdownGracePeriod()
+
+	tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Waiting up to %s for the messages in process", gracePeriod))
+
+	if !dispatcher.WaitTimeout(gracePeriod) {
+		tr.getLoggerWithName().Info("Shutdown grace period expired, cancelling the messages in process")
+	}
+
+	tr.cancelHandlers()
+
+	if !dispatcher.WaitTimeout(runnerCommon.CancelledHandlersTimeout) {
+		tr.getLoggerWithName().Info("Cancelled handlers still running, shutting down without them")
+	}
+
 	tr.getLoggerWithName().V(1).Info("Unsubscribing from all subjects")
 
 	for _, s := range subscriptions {
@@ -93,18 +128,57 @@ func (tr *Runner) startSubscriber() {
 		err := s.Unsubscribe()
 		if err != nil {
 			tr.getLoggerWithName().Error(err, fmt.Sprintf("Error unsubscribing from the subject %s", s.Subject))
-			os.Exit(1)
 		}
 	}
 
 	tr.getLoggerWithName().Info("Unsubscribed from all subjects")
+
+	tr.cancel()
+}
+
+func (tr *Runner) getConcurrency() int {
+	if tr.concurrency > 0 {
+		return tr.concurrency
+	}
+
+	return tr.getConfig().GetInt(common.ConfigRunnerSubscriberMaxConcurrencyKey)
 }
 
 func (tr *Runner) processMessage(msg *nats.Msg) {
-	requestMsg, err := tr.newRequestMessage(msg.Data)
+	var requestMsg *kai.KaiNatsMessage
+
+	defer runnerCommon.RecoverPanic(tr.getLoggerWithName(), func(err error) {
+		tr.countPanic(requestMsg.GetRequestId())
+
+		errMsg := fmt.Sprintf("Error in node %q processing message from subject %s: %s",
+			tr.sdk.Metadata.GetProcess(), msg.Subject, err)
+		tr.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+	})
+
+	requestMsg, err := tr.newRequestMessage(msg)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error parsing msg.data coming from subject %s because is not a valid protobuf: %s", msg.Subject, err)
-		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
+		tr.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+
+		return
+	}
+
+	requestMsg, err = runnerCommon.ResolveClaimCheck(tr.jetstream, tr.getConfig(), msg, requestMsg)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error resolving the claim check of the message from subject %s: %s", msg.Subject, err)
+		tr.processRunnerError(msg, err, errMsg, requestMsg.GetRequestId())
+
+		return
+	}
+
+	if tr.idempotency.IsProcessed(msg) {
+		tr.getLoggerWithName().Info(fmt.Sprintf("Skipping message from subject %s already processed for request id %s",
+			msg.Subject, requestMsg.GetRequestId()))
+
+		ackErr := msg.Ack()
+		if ackErr != nil {
+			tr.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+		}
 
 		return
 	}
@@ -122,56 +196,216 @@ func (tr *Runner) processMessage(msg *nats.Msg) {
 	tr.getLoggerWithName().Info(fmt.Sprintf("New message received with subject %s",
 		msg.Subject))
 
-	handler := tr.getResponseHandler(strings.ToLower(requestMsg.FromNode))
+	handler := tr.getResponseHandler(strings.ToLower(requestMsg.FromNode), requestMsg.GetPayload().GetTypeUrl())
 	if handler == nil {
-		errMsg := fmt.Sprintf("Error missing handler for node %q", requestMsg.FromNode)
-		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
+		errMsg := fmt.Sprintf("Error missing handler for node %q and payload type %q",
+			requestMsg.FromNode, requestMsg.GetPayload().GetTypeUrl())
+		tr.processRunnerError(msg, nil, errMsg, requestMsg.RequestId)
 
 		return
 	}
 
+	ctx, cancel := tr.newMessageContext(msg)
+	defer cancel()
+
+	ctx, span := runnerCommon.StartMessageSpan(ctx, tr.sdk, msg, requestMsg)
+	defer span.End()
+
 	// Make a shallow copy of the sdk object to set inside the request msg.
 	hSdk := sdk.ShallowCopyWithRequest(&tr.sdk, requestMsg)
+	hSdk = hSdk.WithContext(ctx)
+	hSdk = hSdk.WithHandledMsgID(runnerCommon.HandledMsgID(msg))
+
+	result := make(chan handlerResult, 1)
+
+	go func() {
+		result <- tr.executeHandler(hSdk, handler, requestMsg)
+	}()
+
+	select {
+	case res := <-result:
+		if res.err != nil {
+			runnerCommon.RecordError(span, res.err)
+			tr.processRunnerError(msg, res.err, res.errMsg, requestMsg.RequestId)
+			return
+		}
+	case <-ctx.Done():
+		runnerCommon.RecordError(span, ctx.Err())
+		tr.processCancelledMessage(msg, requestMsg)
+
+		// The handler keeps its worker until it returns, so handlers ignoring the cancellation
+		// never run beyond the maximum concurrency.
+		<-result
+
+		return
+	}
+
+	tr.idempotency.MarkProcessed(msg)
+
+	// Tell NATS we don't need to receive the message anymore, and we are done processing it.
+	ackErr := msg.Ack()
+	if ackErr != nil {
+		tr.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+	}
+}
+
+type handlerResult struct {
+	errMsg string
+	err    error
+}
+
+// executeHandler runs the preprocessor, handler and postprocessor of a message. A panic in any of
+// them is recovered and returned as an error.
+func (tr *Runner) executeHandler(hSdk sdk.KaiSDK, handler Handler, requestMsg *kai.KaiNatsMessage) (result handlerResult) {
+	defer runnerCommon.RecoverPanic(tr.getLoggerWithName(), func(err error) {
+		tr.countPanic(requestMsg.GetRequestId())
+
+		result = handlerResult{
+			errMsg: fmt.Sprintf("Error in node %q executing handler for node %q: %s",
+				tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err),
+			err: err,
+		}
+	})
 
 	if tr.preprocessor != nil {
-		err := tr.preprocessor(hSdk, requestMsg.Payload)
+		err := runnerCommon.TracePhase(hSdk, runnerCommon.PreprocessorSpanName,
+			runnerCommon.Handler(tr.preprocessor), requestMsg.Payload)
 		if err != nil {
 			errMsg := fmt.Sprintf("Error in node %q executing handler preprocessor for node %q: %s",
 				tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-			tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-			return
+			return handlerResult{errMsg: errMsg, err: err}
 		}
 	}
 
-	err = handler(hSdk, requestMsg.Payload)
+	err := runnerCommon.TracePhase(hSdk, runnerCommon.HandlerSpanName, runnerCommon.Handler(handler), requestMsg.Payload)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error in node %q executing handler for node %q: %s",
 			tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-		return
+		return handlerResult{errMsg: errMsg, err: err}
 	}
 
 	if tr.postprocessor != nil {
-		err := tr.postprocessor(hSdk, requestMsg.Payload)
+		err := runnerCommon.TracePhase(hSdk, runnerCommon.PostprocessorSpanName,
+			runnerCommon.Handler(tr.postprocessor), requestMsg.Payload)
 		if err != nil {
 			errMsg := fmt.Sprintf("Error in node %q executing handler postprocessor for node %q: %s",
 				tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
-			tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
 
-			return
+			return handlerResult{errMsg: errMsg, err: err}
 		}
 	}
 
-	// Tell NATS we don't need to receive the message anymore, and we are done processing it.
-	ackErr := msg.Ack()
-	if ackErr != nil {
-		tr.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
+	// The outputs sent asynchronously must be delivered before the message is acknowledged.
+	if err := hSdk.Messaging.Flush(hSdk.Context()); err != nil {
+		errMsg := fmt.Sprintf("Error in node %q delivering the outputs of the handler for node %q: %s",
+			tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
+
+		return handlerResult{errMsg: errMsg, err: err}
+	}
+
+	return handlerResult{}
+}
+
+// newMessageContext returns the context of a message, cancelled when the shutdown grace period of
+// the runner expires or, if a handler timeout is set, when it expires.
+func (tr *Runner) newMessageContext(msg *nats.Msg) (context.Context, context.CancelFunc) {
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+	)
+
+	if timeout := tr.getHandlerTimeout(); timeout > 0 {
+		ctx, cancel = context.WithTimeout(tr.handlersCtx, timeout)
+	} else {
+		ctx, cancel = context.WithCancel(tr.handlersCtx)
+	}
+
+	deadline, ok := runnerCommon.MessageDeadline(msg)
+	if !ok {
+		return ctx, cancel
+	}
+
+	deadlineCtx, deadlineCancel := context.WithDeadline(ctx, deadline)
+
+	return deadlineCtx, func() {
+		deadlineCancel()
+		cancel()
+	}
+}
+
+// processCancelledMessage handles a message whose context was cancelled before its handler
+// finished. Once the shutdown grace period expires, the message is returned to NATS to be
+// delivered again. On timeout, it is failed with a retryable ErrHandlerTimeout, unless the deadline
+// of its request was exceeded, which is not retried. The handler keeps running until it returns,
+// but its result is discarded and the outputs it sends from then on are rejected.
+func (tr *Runner) processCancelledMessage(msg *nats.Msg, requestMsg *kai.KaiNatsMessage) {
+	if tr.handlersCtx.Err() != nil {
+		tr.getLoggerWithName().Info("Shutting down before the handler finished, returning message to NATS")
+
+		nakErr := msg.Nak()
+		if nakErr != nil {
+			tr.getLoggerWithName().Error(nakErr, errors.ErrMsgNak)
+		}
+
+		return
 	}
+
+	err := sdk.RetryableError(fmt.Errorf("%w after %s", runnerCommon.ErrHandlerTimeout, tr.getHandlerTimeout()))
+	if deadline, ok := runnerCommon.MessageDeadline(msg); ok && !time.Now().Before(deadline) {
+		err = fmt.Errorf("%w at %s", runnerCommon.ErrRequestDeadlineExceeded, deadline.Format(time.RFC3339Nano))
+	}
+
+	errMsg := fmt.Sprintf("Error in node %q executing handler for node %q: %s",
+		tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
+	tr.processRunnerError(msg, err, errMsg, requestMsg.RequestId)
+}
+
+func (tr *Runner) countPanic(requestID string) {
+	tr.panicsMetric.Add(context.Background(), 1, metric.WithAttributeSet(tr.getMetricAttributes(requestID)))
+}
+
+func (tr *Runner) getShutdownGracePeriod() time.Duration {
+	if tr.gracePeriod > 0 {
+		return tr.gracePeriod
+	}
+
+	return tr.getConfig().GetDuration(common.ConfigRunnerShutdownGracePeriodKey)
 }
 
-func (tr *Runner) processRunnerError(msg *nats.Msg, errMsg, requestID string) {
+func (tr *Runner) getHandlerTimeout() time.Duration {
+	if tr.handlerTimeout > 0 {
+		return tr.handlerTimeout
+	}
+
+	return tr.getConfig().GetDuration(common.ConfigRunnerSubscriberHandlerTimeoutKey)
+}
+
+// processRunnerError redelivers the message when the error is retryable and the retry policy
+// allows it. Otherwise, the message is dead-lettered if configured, acknowledged, and the error
+// is published to the output subject.
+func (tr *Runner) processRunnerError(msg *nats.Msg, err error, errMsg, requestID string) {
+	retryPolicy := tr.getRetryPolicy()
+	numDelivered := runnerCommon.DeliveryCount(msg)
+
+	if retryPolicy.ShouldRetry(err, numDelivered) {
+		delay := retryPolicy.Backoff(numDelivered)
+
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("%s, retrying in %s", errMsg, delay))
+
+		nakErr := msg.NakWithDelay(delay)
+		if nakErr != nil {
+			tr.getLoggerWithName().Error(nakErr, errors.ErrMsgNak)
+		}
+
+		return
+	}
+
+	if retryPolicy.DeadLetterSubject != "" {
+		tr.publishDeadLetter(retryPolicy, msg, errMsg, numDelivered)
+	}
+
 	ackErr := msg.Ack()
 	if ackErr != nil {
 		tr.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
@@ -181,16 +415,32 @@ func (tr *Runner) processRunnerError(msg *nats.Msg, errMsg, requestID string) {
 	tr.publishError(requestID, errMsg)
 }
 
-func (tr *Runner) newRequestMessage(data []byte) (*kai.KaiNatsMessage, error) {
+func (tr *Runner) publishDeadLetter(retryPolicy runnerCommon.RetryPolicy, msg *nats.Msg, errMsg string, numDelivered uint64) {
+	deadLetter := retryPolicy.DeadLetterMessage(msg, tr.sdk.Metadata.GetProcess(), errMsg, numDelivered)
+
+	tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Publishing failed message to dead-letter subject %s", deadLetter.Subject))
+
+	_, err := tr.jetstream.PublishMsg(deadLetter)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, "Error publishing message to the dead-letter subject")
+	}
+}
+
+func (tr *Runner) getRetryPolicy() runnerCommon.RetryPolicy {
+	if tr.retryPolicy != nil {
+		return *tr.retryPolicy
+	}
+
+	return runnerCommon.RetryPolicyFromConfig(tr.getConfig())
+}
+
+func (tr *Runner) newRequestMessage(msg *nats.Msg) (*kai.KaiNatsMessage, error) {
 	requestMsg := &kai.KaiNatsMessage{}
 
-	var err error
-	if common.IsCompressed(data) {
-		data, err = common.UncompressData(data)
-		if err != nil {
-			tr.getLoggerWithName().Error(err, "Error reading compressed message")
-			return nil, err
-		}
+	data, err := compression.Decompress(msg.Header, msg.Data)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, "Error reading compressed message")
+		return nil, err
 	}
 
 	err = proto.Unmarshal(data, requestMsg)
@@ -202,7 +452,7 @@ func (tr *Runner) publishError(requestID, errMsg string) {
 	responseMsg := &kai.KaiNatsMessage{
 		RequestId:   requestID,
 		Error:       errMsg,
-		FromNode:    viper.GetString(common.ConfigMetadataProcessIDKey),
+		FromNode:    tr.getConfig().GetString(common.ConfigMetadataProcessIDKey),
 		MessageType: kai.MessageType_ERROR,
 	}
 	tr.publishResponse(responseMsg, "")
@@ -218,7 +468,7 @@ func (tr *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 		return
 	}
 
-	outputMsg, err = tr.prepareOutputMessage(outputMsg)
+	outputMsg, codec, err := tr.prepareOutputMessage(outputMsg)
 	if err != nil {
 		tr.getLoggerWithName().Error(err, "Error preparing output message")
 		return
@@ -226,14 +476,18 @@ func (tr *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 
 	tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Publishing response with subject %s", outputSubject))
 
-	_, err = tr.jetstream.Publish(outputSubject, outputMsg)
+	pubMsg := nats.NewMsg(outputSubject)
+	pubMsg.Data = outputMsg
+	pubMsg.Header = compression.SetHeader(pubMsg.Header, codec)
+
+	_, err = tr.jetstream.PublishMsg(pubMsg)
 	if err != nil {
 		tr.getLoggerWithName().Error(err, "Error publishing output")
 	}
 }
 
 func (tr *Runner) getOutputSubject(channel string) string {
-	outputSubject := viper.GetString(common.ConfigNatsOutputKey)
+	outputSubject := tr.getConfig().GetString(common.ConfigNatsOutputKey)
 	if channel != "" {
 		return fmt.Sprintf("%s.%s", outputSubject, channel)
 	}
@@ -241,48 +495,72 @@ func (tr *Runner) getOutputSubject(channel string) string {
 	return outputSubject
 }
 
-// prepareOutputMessage will check the length of the message and compress it if necessary.
-// Fails on compressed messages bigger than the threshold.
-func (tr *Runner) prepareOutputMessage(msg []byte) ([]byte, error) {
+// prepareOutputMessage compresses the message with the configured codec when it exceeds the maximum
+// size of the stream or the compression threshold, and returns it along with the codec used, if any.
+// Fails on compressed messages bigger than the maximum size.
+func (tr *Runner) prepareOutputMessage(msg []byte) ([]byte, string, error) {
 	maxSize, err := tr.getMaxMessageSize()
 	if err != nil {
-		return nil, fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
+		return nil, "", fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
 	}
 
+	options := compression.OptionsFromConfig(tr.getConfig())
+
 	lenMsg := int64(len(msg))
-	if lenMsg <= maxSize {
-		return msg, nil
+	if lenMsg <= maxSize && !options.ShouldCompress(lenMsg) {
+		return msg, "", nil
 	}
 
-	outMsg, err := common.CompressData(msg)
+	outMsg, codec, err := options.Compress(msg)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	lenOutMsg := int64(len(outMsg))
 	if lenOutMsg > maxSize {
-		tr.getLoggerWithName().V(1).Info("Compressed message size %s "+
-			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize))
-		return nil, errors.ErrMessageToBig
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Compressed message size %s "+
+			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize)))
+		return nil, "", errors.ErrMessageToBig
 	}
 
 	tr.getLoggerWithName().Info(fmt.Sprintf("Message prepared with original size %s "+
 		"and compressed size %s", sizeInMB(lenMsg), sizeInMB(lenOutMsg)))
 
-	return outMsg, nil
+	return outMsg, codec, nil
+}
+
+func (tr *Runner) getResponseHandler(subject, typeURL string) Handler {
+	responseHandler := tr.routeResponseHandler(subject, typeURL)
+
+	if responseHandler == nil || len(tr.middlewares) == 0 {
+		return responseHandler
+	}
+
+	return Handler(runnerCommon.ChainMiddlewares(runnerCommon.Handler(responseHandler), tr.middlewares...))
 }
 
-func (tr *Runner) getResponseHandler(subject string) Handler {
+// routeResponseHandler returns the handler set for the node and the payload type, the payload
+// type, the node or the default one, in that order, or nil if none of them exists.
+func (tr *Runner) routeResponseHandler(subject, typeURL string) Handler {
+	messageType := runnerCommon.TypeName(typeURL)
+
+	if responseHandler, ok := tr.typeHandlers[typeRoute{node: subject, messageType: messageType}]; ok {
+		return responseHandler
+	}
+
+	if responseHandler, ok := tr.typeHandlers[typeRoute{messageType: messageType}]; ok {
+		return responseHandler
+	}
+
 	if responseHandler, ok := tr.responseHandlers[subject]; ok {
 		return responseHandler
 	}
 
-	// returns the default response handler, or nil if it doesn't exist
 	return tr.responseHandlers["default"]
 }
 
 func (tr *Runner) getMaxMessageSize() (int64, error) {
-	streamInfo, err := tr.jetstream.StreamInfo(viper.GetString(common.ConfigNatsStreamKey))
+	streamInfo, err := tr.jetstream.StreamInfo(tr.getConfig().GetString(common.ConfigNatsStreamKey))
 	if err != nil {
 		return 0, fmt.Errorf("error getting stream's max message size: %w", err)
 	}
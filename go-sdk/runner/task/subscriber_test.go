@@ -0,0 +1,410 @@
+//go:build unit
+
+package task_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type SubscriberTestSuite struct {
+	suite.Suite
+}
+
+func (s *SubscriberTestSuite) TestRun_RetryableError_ExpectRetriedAndResponse() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	sim.ForwardExit()
+
+	var calls atomic.Int32
+
+	sim.TaskRunner("transformer").
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 3, InitialBackoff: 10 * time.Millisecond}).
+		WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			if calls.Add(1) == 1 {
+				return sdk.RetryableError(errors.New("transient error"))
+			}
+
+			return kaiSDK.Messaging.SendAny(payload)
+		})
+
+	// When
+	response := sim.RequestString("hello")
+
+	// Then
+	s.Equal("hello", response)
+	s.Equal(int32(2), calls.Load())
+}
+
+func (s *SubscriberTestSuite) TestRun_RetriedAfterSendingOutput_ExpectDuplicateOutputDropped() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), "nats: {deduplication: {enabled: true}}"))
+	sim.ForwardExit()
+
+	var calls atomic.Int32
+
+	retried := make(chan struct{})
+
+	sim.TaskRunner("transformer").
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 3, InitialBackoff: 10 * time.Millisecond}).
+		WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			if err := kaiSDK.Messaging.SendAny(payload); err != nil {
+				return err
+			}
+
+			if calls.Add(1) == 1 {
+				return sdk.RetryableError(errors.New("transient error"))
+			}
+
+			close(retried)
+
+			return nil
+		})
+
+	responses := make(chan *anypb.Any, 1)
+
+	sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		response, _ := tr.Request(context.Background(), wrapperspb.String("hello"))
+		responses <- response
+	})
+
+	// When
+	stop := sim.Start()
+	defer stop()
+
+	var response *anypb.Any
+
+	timeout := time.After(localtest.Timeout)
+
+	select {
+	case <-retried:
+	case <-timeout:
+		s.FailNow("handler not retried")
+	}
+
+	select {
+	case response = <-responses:
+	case <-timeout:
+		s.FailNow("no response")
+	}
+
+	// Then
+	value := &wrapperspb.StringValue{}
+	s.Require().NoError(response.UnmarshalTo(value))
+	s.Equal("hello", value.GetValue())
+	s.Equal(int32(2), calls.Load())
+	s.Equal(uint64(1), sim.CountStoredMessages(localtest.Subject("transformer")))
+}
+
+func (s *SubscriberTestSuite) TestRun_DeduplicationEnabledAndTwoUpstreamNodes_ExpectEveryMessageProcessed() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Workflow(s.T(), "nats: {deduplication: {enabled: true}}",
+		localtest.Trigger("entrypoint", "exit"),
+		localtest.Task("left", "entrypoint"),
+		localtest.Task("right", "entrypoint"),
+		localtest.Task("join", "left", "right"),
+		localtest.Exit("exit", "join"),
+	))
+	sim.SendRequest(wrapperspb.String("hello"))
+	sim.DiscardExit()
+
+	for _, name := range []string{"left", "right"} {
+		sim.TaskRunner(name).WithHandler(sendValue(name))
+	}
+
+	var calls atomic.Int32
+
+	sim.TaskRunner("join").WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		calls.Add(1)
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+
+	// When
+	stop := sim.Start()
+	defer stop()
+
+	// Then
+	s.Eventually(func() bool {
+		return calls.Load() == 2 && sim.CountStoredMessages(localtest.Subject("join")) == 2
+	}, localtest.Timeout, 10*time.Millisecond)
+}
+
+func (s *SubscriberTestSuite) TestRun_IdempotencyEnabled_ExpectRequestPublishedAgainSkipped() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(),
+		"runner: {subscriber: {idempotency: {enabled: true, bucket: processed}}}"))
+	sim.TriggerRunner("entrypoint").WithRunner(func(_ *trigger.Runner, _ sdk.KaiSDK) {})
+	sim.ForwardExit()
+
+	var calls atomic.Int32
+
+	sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		calls.Add(1)
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+
+	stop := sim.Start()
+	defer stop()
+
+	js := sim.JetStream()
+	consumer := localtest.Consumer("entrypoint", "transformer")
+
+	payload, err := anypb.New(wrapperspb.String("hello"))
+	s.Require().NoError(err)
+
+	requestID := uuid.New().String()
+
+	data, err := proto.Marshal(&kai.KaiNatsMessage{RequestId: requestID, Payload: payload, FromNode: "entrypoint"})
+	s.Require().NoError(err)
+
+	s.Require().Eventually(func() bool {
+		_, err := js.ConsumerInfo(localtest.StreamName, consumer)
+		return err == nil
+	}, localtest.Timeout, 10*time.Millisecond)
+
+	// The duplicates window of the stream is shortened, so the message published again once it is
+	// over is stored, as it would be when a producer retries it too late.
+	info, err := js.StreamInfo(localtest.StreamName)
+	s.Require().NoError(err)
+
+	info.Config.Duplicates = 100 * time.Millisecond
+
+	_, err = js.UpdateStream(&info.Config)
+	s.Require().NoError(err)
+
+	// When
+	for i := 0; i < 2; i++ {
+		_, err = js.Publish(localtest.Subject("entrypoint"), data, nats.MsgId(requestID+"/entrypoint//0"))
+		s.Require().NoError(err)
+
+		s.Require().Eventually(func() bool {
+			info, err := js.ConsumerInfo(localtest.StreamName, consumer)
+			return err == nil && info.AckFloor.Consumer == uint64(i+1) && info.NumAckPending == 0
+		}, localtest.Timeout, 10*time.Millisecond)
+
+		time.Sleep(2 * info.Config.Duplicates)
+	}
+
+	// Then
+	s.Equal(int32(1), calls.Load())
+	s.Equal(uint64(1), sim.CountStoredMessages(localtest.Subject("transformer")))
+}
+
+func (s *SubscriberTestSuite) TestRun_RetriesExhausted_ExpectDeadLetter() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	sim.SendRequest(wrapperspb.String("hello"))
+	sim.ForwardExit()
+
+	sim.TaskRunner("transformer").
+		WithRetryPolicy(common.RetryPolicy{
+			MaxDeliveries:     2,
+			InitialBackoff:    10 * time.Millisecond,
+			DeadLetterSubject: localtest.DeadLetterSubject,
+		}).
+		WithHandler(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+			return sdk.RetryableError(errors.New("transient error"))
+		})
+
+	// When
+	deadLetter := sim.RunUntilDeadLetter()
+
+	// Then
+	s.Equal("2", deadLetter.Header.Get(common.DeadLetterDeliveriesHeader))
+	s.Equal("transformer", deadLetter.Header.Get(common.DeadLetterProcessHeader))
+	s.Contains(deadLetter.Header.Get(common.DeadLetterErrorHeader), "transient error")
+}
+
+func (s *SubscriberTestSuite) TestRun_HandlerTimeout_ExpectContextCancelledAndDeadLetter() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	sim.SendRequest(wrapperspb.String("hello"))
+	sim.ForwardExit()
+
+	handlerErrors := make(chan error, 1)
+
+	sim.TaskRunner("transformer").
+		WithHandlerTimeout(50 * time.Millisecond).
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 1, DeadLetterSubject: localtest.DeadLetterSubject}).
+		WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+			<-kaiSDK.Context().Done()
+			handlerErrors <- kaiSDK.Context().Err()
+
+			return kaiSDK.Context().Err()
+		})
+
+	// When
+	deadLetter := sim.RunUntilDeadLetter()
+
+	// Then
+	s.ErrorIs(<-handlerErrors, context.DeadlineExceeded)
+	s.Contains(deadLetter.Header.Get(common.DeadLetterErrorHeader), common.ErrHandlerTimeout.Error())
+}
+
+func (s *SubscriberTestSuite) TestRun_HandlerIgnoringTimeout_ExpectWorkerKeptAndOutputRejected() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	sim.ForwardExit()
+
+	deadLetters, err := sim.Conn().SubscribeSync(localtest.DeadLetterSubject)
+	s.Require().NoError(err)
+
+	var calls atomic.Int32
+
+	release := make(chan struct{})
+	sendErrors := make(chan error, 1)
+
+	sim.TriggerRunner("entrypoint").WithRunner(func(_ *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		for i := 0; i < 2; i++ {
+			_ = kaiSDK.Messaging.SendOutputWithRequestID(wrapperspb.String("hello"), uuid.New().String())
+		}
+	})
+
+	sim.TaskRunner("transformer").
+		WithConcurrency(1).
+		WithHandlerTimeout(50 * time.Millisecond).
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 1, DeadLetterSubject: localtest.DeadLetterSubject}).
+		WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			if calls.Add(1) > 1 {
+				return kaiSDK.Messaging.SendAny(payload)
+			}
+
+			<-kaiSDK.Context().Done()
+			<-release
+
+			sendErrors <- kaiSDK.Messaging.SendAny(payload)
+
+			return nil
+		})
+
+	stop := sim.Start()
+
+	// When
+	_, err = deadLetters.NextMsg(localtest.Timeout)
+	s.Require().NoError(err)
+
+	// Then
+	s.Never(func() bool { return calls.Load() > 1 }, 200*time.Millisecond, 10*time.Millisecond)
+
+	close(release)
+
+	s.ErrorIs(<-sendErrors, context.DeadlineExceeded)
+	s.Eventually(func() bool { return calls.Load() == 2 }, localtest.Timeout, 10*time.Millisecond)
+
+	stop()
+
+	// The error of the first message and the output of the second one.
+	s.Equal(uint64(2), sim.CountStoredMessages(localtest.Subject("transformer")))
+}
+
+func (s *SubscriberTestSuite) TestRun_HandlerPanic_ExpectRecoveredAndDeadLetter() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	sim.SendRequest(wrapperspb.String("hello"))
+	sim.ForwardExit()
+
+	sim.TaskRunner("transformer").
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 1, DeadLetterSubject: localtest.DeadLetterSubject}).
+		WithHandler(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+			panic("unexpected state")
+		})
+
+	// When
+	deadLetter := sim.RunUntilDeadLetter()
+
+	// Then
+	s.Contains(deadLetter.Header.Get(common.DeadLetterErrorHeader), common.ErrHandlerPanic.Error())
+	s.Contains(deadLetter.Header.Get(common.DeadLetterErrorHeader), "unexpected state")
+}
+
+func (s *SubscriberTestSuite) TestRun_OversizedPayloadToSeveralNodes_ExpectClaimCheckResolvedByEveryNode() {
+	// Given
+	sim := localtest.New(s.T(), localtest.Workflow(s.T(), "",
+		localtest.Trigger("entrypoint", "exit"),
+		localtest.Task("transformer", "entrypoint"),
+		localtest.Task("auditor", "entrypoint"),
+		localtest.Exit("exit", "transformer", "auditor"),
+	))
+	sim.DiscardExit()
+
+	payload := make([]byte, 2*1024*1024)
+	_, err := rand.Read(payload)
+	s.Require().NoError(err)
+
+	sim.SendRequest(&wrapperspb.BytesValue{Value: payload})
+
+	received := make(chan string, 2)
+
+	receive := func(name string) task.Handler {
+		return func(_ sdk.KaiSDK, request *anypb.Any) error {
+			value := &wrapperspb.BytesValue{}
+			if err := request.UnmarshalTo(value); err != nil {
+				return err
+			}
+
+			if bytes.Equal(payload, value.GetValue()) {
+				received <- name
+			}
+
+			return nil
+		}
+	}
+
+	sim.TaskRunner("transformer").WithHandler(receive("transformer"))
+
+	var auditorCalls atomic.Int32
+
+	// The auditor resolves the claim check again when retried, once the transformer is done with it.
+	sim.TaskRunner("auditor").
+		WithRetryPolicy(common.RetryPolicy{MaxDeliveries: 2, InitialBackoff: 200 * time.Millisecond}).
+		WithHandler(func(kaiSDK sdk.KaiSDK, request *anypb.Any) error {
+			if auditorCalls.Add(1) == 1 {
+				return sdk.RetryableError(errors.New("transient error"))
+			}
+
+			return receive("auditor")(kaiSDK, request)
+		})
+
+	// When
+	stop := sim.Start()
+	defer stop()
+
+	var nodes []string
+
+	timeout := time.After(localtest.Timeout)
+
+	for len(nodes) < 2 {
+		select {
+		case name := <-received:
+			nodes = append(nodes, name)
+		case <-timeout:
+			s.FailNow("claim check not resolved by every node", nodes)
+		}
+	}
+
+	// Then
+	s.ElementsMatch([]string{"transformer", "auditor"}, nodes)
+}
+
+func TestSubscriberTestSuite(t *testing.T) {
+	suite.Run(t, new(SubscriberTestSuite))
+}
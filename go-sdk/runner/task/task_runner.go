@@ -1,10 +1,14 @@
 package task
 
 import (
+	"context"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel/metric"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
@@ -19,29 +23,66 @@ type Handler common.Handler
 
 type Postprocessor common.Handler
 
+// typeRoute identifies the handler of a payload type, optionally restricted to a node.
+type typeRoute struct {
+	node        string
+	messageType string
+}
+
 type Runner struct {
 	sdk              sdk.KaiSDK
+	config           *viper.Viper
+	ctx              context.Context
+	cancel           context.CancelFunc
+	handlersCtx      context.Context
+	cancelHandlers   context.CancelFunc
 	nats             *nats.Conn
 	jetstream        nats.JetStreamContext
 	responseHandlers map[string]Handler
+	typeHandlers     map[typeRoute]Handler
 	initializer      common.Initializer
 	preprocessor     Preprocessor
 	postprocessor    Postprocessor
 	finalizer        common.Finalizer
 	messagesMetric   metric.Int64Histogram
+	panicsMetric     metric.Int64Counter
+	concurrency      int
+	retryPolicy      *common.RetryPolicy
+	handlerTimeout   time.Duration
+	gracePeriod      time.Duration
+	sharedConnection bool
+	health           *common.HealthServer
+	idempotency      *common.IdempotencyGuard
+	middlewares      []common.Middleware
 }
 
 func NewTaskRunner(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext) *Runner {
+	return NewTaskRunnerWithConfig(logger, ns, js, nil)
+}
+
+// NewTaskRunnerWithConfig creates a Runner reading its configuration from the given viper instance
+// instead of the global one.
+func NewTaskRunnerWithConfig(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	return NewTaskRunnerWithSDK(sdk.NewKaiSDKWithConfig(logger, ns, js, config), ns, js, config)
+}
+
+// NewTaskRunnerWithSDK creates a Runner using an already initialized KaiSDK, so the errors
+// initializing it can be handled by the caller.
+func NewTaskRunnerWithSDK(kaiSDK sdk.KaiSDK, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	kaiSDK.Logger = kaiSDK.Logger.WithName(_taskLoggerName)
+
 	return &Runner{
-		sdk:              sdk.NewKaiSDK(logger.WithName(_taskLoggerName), ns, js),
+		sdk:              kaiSDK,
+		config:           config,
 		nats:             ns,
 		jetstream:        js,
 		responseHandlers: make(map[string]Handler),
+		typeHandlers:     make(map[typeRoute]Handler),
 	}
 }
 
 func (tr *Runner) WithInitializer(initializer common.Initializer) *Runner {
-	tr.initializer = composeInitializer(initializer)
+	tr.initializer = composeInitializer(initializer, tr.config)
 	return tr
 }
 
@@ -60,6 +101,32 @@ func (tr *Runner) WithCustomHandler(subject string, handler Handler) *Runner {
 	return tr
 }
 
+// WithTypeHandler sets the handler of the messages whose payload holds the given message type,
+// given as a type URL or a full name, wherever they come from. See common.TypeURL.
+//
+// Handlers are chosen in this order: the one set for both the node and the payload type, the one
+// set for the payload type, the one set for the node and, finally, the default handler.
+func (tr *Runner) WithTypeHandler(messageType string, handler Handler) *Runner {
+	tr.typeHandlers[typeRoute{messageType: common.TypeName(messageType)}] = composeHandler(handler)
+	return tr
+}
+
+// WithCustomTypeHandler sets the handler of the messages coming from the given node whose payload
+// holds the given message type. It takes precedence over every other handler.
+func (tr *Runner) WithCustomTypeHandler(subject, messageType string, handler Handler) *Runner {
+	route := typeRoute{node: strings.ToLower(subject), messageType: common.TypeName(messageType)}
+	tr.typeHandlers[route] = composeHandler(handler)
+
+	return tr
+}
+
+// WithMiddleware adds middlewares wrapping every handler, including the custom ones. Middlewares
+// run in the order they are added, so the first one is the outermost.
+func (tr *Runner) WithMiddleware(middlewares ...common.Middleware) *Runner {
+	tr.middlewares = append(tr.middlewares, middlewares...)
+	return tr
+}
+
 func (tr *Runner) WithPostprocessor(postprocessor Postprocessor) *Runner {
 	tr.postprocessor = composePostprocessor(postprocessor)
 	return tr
@@ -70,22 +137,97 @@ func (tr *Runner) WithFinalizer(finalizer common.Finalizer) *Runner {
 	return tr
 }
 
+// WithConcurrency sets how many messages are processed at the same time, overriding the
+// runner.subscriber.max_concurrency configuration. Each message is still acknowledged once
+// its handler finishes.
+func (tr *Runner) WithConcurrency(maxConcurrency int) *Runner {
+	tr.concurrency = maxConcurrency
+	return tr
+}
+
+// WithHandlerTimeout sets how long a message can be processed before it is failed with
+// common.ErrHandlerTimeout, overriding the runner.subscriber.handler_timeout configuration.
+// The context of the SDK given to the handler is cancelled at that moment.
+func (tr *Runner) WithHandlerTimeout(timeout time.Duration) *Runner {
+	tr.handlerTimeout = timeout
+	return tr
+}
+
+// WithShutdownGracePeriod sets how long the messages in process can take to finish once the runner
+// starts shutting down, overriding the runner.shutdown_grace_period configuration. The context of
+// the SDK given to the handler is cancelled when it expires.
+func (tr *Runner) WithShutdownGracePeriod(gracePeriod time.Duration) *Runner {
+	tr.gracePeriod = gracePeriod
+	return tr
+}
+
+// WithSharedConnection tells the runner that its NATS connection is also used by the caller, so on
+// shutdown it is only flushed instead of drained and closed.
+func (tr *Runner) WithSharedConnection() *Runner {
+	tr.sharedConnection = true
+	return tr
+}
+
+// WithRetryPolicy sets how failed messages are retried and dead-lettered, overriding the
+// runner.subscriber.retry and runner.subscriber.dead_letter_subject configuration.
+func (tr *Runner) WithRetryPolicy(retryPolicy common.RetryPolicy) *Runner {
+	tr.retryPolicy = &retryPolicy
+	return tr
+}
+
 func (tr *Runner) Run() {
+	tr.RunContext(context.Background())
+}
+
+// RunContext runs the TaskRunner until a shutdown signal is received or the given context is done.
+func (tr *Runner) RunContext(ctx context.Context) {
+	tr.ctx, tr.cancel = context.WithCancel(ctx)
+	defer tr.cancel()
+
+	// Handlers are only cancelled once the shutdown grace period expires.
+	tr.handlersCtx, tr.cancelHandlers = context.WithCancel(context.WithoutCancel(tr.ctx))
+	defer tr.cancelHandlers()
+
 	if tr.responseHandlers["default"] == nil {
 		panic("Undefined default handler")
 	}
 
 	if tr.initializer == nil {
-		tr.initializer = composeInitializer(nil)
+		tr.initializer = composeInitializer(nil, tr.config)
 	}
 
 	if tr.finalizer == nil {
 		tr.finalizer = composeFinalizer(nil)
 	}
 
+	tr.health = common.NewHealthServer(tr.sdk.Logger, tr.sdk, tr.nats, tr.config)
+	if err := tr.health.Start(); err != nil {
+		tr.sdk.Logger.Error(err, "Error starting health server")
+		os.Exit(1)
+	}
+	defer tr.health.Shutdown()
+
+	idempotency, err := common.NewIdempotencyGuard(tr.sdk.Logger, tr.jetstream, tr.config)
+	if err != nil {
+		tr.sdk.Logger.Error(err, "Error initializing idempotency guard")
+		os.Exit(1)
+	}
+
+	tr.idempotency = idempotency
+
+	stopClaimCheckSweeper := common.StartClaimCheckSweeper(tr.sdk.Logger, tr.jetstream, tr.config)
+
 	tr.initializer(tr.sdk)
 
 	tr.startSubscriber()
 
+	stopClaimCheckSweeper()
+
+	if tr.sharedConnection {
+		common.FlushConnection(tr.sdk.Logger, tr.sdk, tr.nats)
+	} else {
+		common.DrainConnection(tr.sdk.Logger, tr.sdk, tr.nats)
+	}
+
 	tr.finalizer(tr.sdk)
 }
@@ -0,0 +1,163 @@
+//go:build unit
+
+package task_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type TaskRunnerTestSuite struct {
+	suite.Suite
+	sim *localtest.Simulator
+}
+
+func (s *TaskRunnerTestSuite) TestRun_WithMiddleware_ExpectHandlerWrapped() {
+	// Given
+	s.setupSimulator("")
+
+	s.sim.TaskRunner("transformer").
+		WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+			return kaiSDK.Messaging.SendAny(payload)
+		}).
+		WithMiddleware(func(next common.Handler) common.Handler {
+			return func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+				payload, err := anypb.New(wrapperspb.String("intercepted"))
+				if err != nil {
+					return err
+				}
+
+				return next(kaiSDK, payload)
+			}
+		})
+
+	// When
+	response := s.sim.RequestString("hello")
+
+	// Then
+	s.Equal("intercepted", response)
+}
+
+func (s *TaskRunnerTestSuite) TestRun_WithTypeHandler_ExpectTypeHandlerBeforeNodeHandler() {
+	// Given
+	s.setupSimulator("")
+
+	s.sim.TaskRunner("transformer").
+		WithHandler(sendValue("default")).
+		WithCustomHandler("entrypoint", sendValue("node")).
+		WithTypeHandler(common.TypeURL(&wrapperspb.StringValue{}), sendValue("type")).
+		WithCustomTypeHandler("other", "google.protobuf.StringValue", sendValue("other node and type")).
+		WithTypeHandler("google.protobuf.Int32Value", sendValue("other type"))
+
+	// When
+	response := s.sim.RequestString("hello")
+
+	// Then
+	s.Equal("type", response)
+}
+
+func (s *TaskRunnerTestSuite) TestRun_WithCustomTypeHandler_ExpectNodeAndTypeHandlerFirst() {
+	// Given
+	s.setupSimulator("")
+
+	s.sim.TaskRunner("transformer").
+		WithHandler(sendValue("default")).
+		WithTypeHandler("google.protobuf.StringValue", sendValue("type")).
+		WithCustomTypeHandler("Entrypoint", common.TypeURL(&wrapperspb.StringValue{}), sendValue("node and type"))
+
+	// When
+	response := s.sim.RequestString("hello")
+
+	// Then
+	s.Equal("node and type", response)
+}
+
+func (s *TaskRunnerTestSuite) TestRun_ShutdownDuringHandler_ExpectHandlerFinishedWithinGracePeriod() {
+	// Given
+	s.setupSimulator("runner: {shutdown_grace_period: 5s}")
+
+	started := make(chan struct{})
+	handlerErr := make(chan error, 1)
+
+	s.sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		handlerErr <- kaiSDK.Context().Err()
+
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String("done"))
+	})
+
+	// When
+	s.runUntilStarted(started)
+
+	// Then
+	s.NoError(<-handlerErr)
+
+	_, err := s.sim.JetStream().GetLastMsg(localtest.StreamName, localtest.Subject("transformer"))
+	s.NoError(err)
+}
+
+func (s *TaskRunnerTestSuite) TestRun_ShutdownGracePeriodExpired_ExpectHandlerCancelled() {
+	// Given
+	s.setupSimulator("runner: {shutdown_grace_period: 100ms}")
+
+	started := make(chan struct{})
+	handlerErr := make(chan error, 1)
+
+	s.sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		close(started)
+		<-kaiSDK.Context().Done()
+		handlerErr <- kaiSDK.Context().Err()
+
+		return nil
+	})
+
+	// When
+	s.runUntilStarted(started)
+
+	// Then
+	s.ErrorIs(<-handlerErr, context.Canceled)
+}
+
+// setupSimulator creates the simulator of a pipeline with the given settings, whose exit answers the trigger.
+func (s *TaskRunnerTestSuite) setupSimulator(settings string) {
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), settings))
+	s.sim.ForwardExit()
+}
+
+// runUntilStarted runs the simulator, with a trigger sending a single request, and shuts it down
+// as soon as the handler processing it starts.
+func (s *TaskRunnerTestSuite) runUntilStarted(started <-chan struct{}) {
+	s.sim.SendRequest(wrapperspb.String("hello"))
+
+	stop := s.sim.Start()
+
+	select {
+	case <-started:
+	case <-time.After(localtest.Timeout):
+		s.Fail("handler not started")
+	}
+
+	stop()
+}
+
+// sendValue returns a handler sending the given value, whatever the payload it receives.
+func sendValue(value string) task.Handler {
+	return func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(value))
+	}
+}
+
+func TestTaskRunnerTestSuite(t *testing.T) {
+	suite.Run(t, new(TaskRunnerTestSuite))
+}
@@ -0,0 +1,91 @@
+package trigger
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// _consumerIDPattern matches the random suffix trigger runners add to the names of their consumers.
+var _consumerIDPattern = regexp.MustCompile(`-[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// OrphanedConsumers returns the names of the consumers of the given stream created by trigger
+// runners that no subscriber is bound to and that have been inactive for at least the given
+// duration, usually left behind by processes that did not shut down cleanly. Consumers active more
+// recently may belong to a replica that is only reconnecting, so they are not returned.
+func OrphanedConsumers(js nats.JetStreamContext, stream string, inactiveFor time.Duration) ([]string, error) {
+	return orphanedConsumers(js, stream, "", inactiveFor)
+}
+
+// RemoveOrphanedConsumers removes the consumers returned by OrphanedConsumers and returns their
+// names. Consumers removed by someone else in the meantime are ignored.
+func RemoveOrphanedConsumers(js nats.JetStreamContext, stream string, inactiveFor time.Duration) ([]string, error) {
+	return removeOrphanedConsumers(js, stream, "", inactiveFor)
+}
+
+func orphanedConsumers(js nats.JetStreamContext, stream, prefix string, inactiveFor time.Duration) ([]string, error) {
+	// Consumers does not report errors, so a missing stream is checked first.
+	if _, err := js.StreamInfo(stream); err != nil {
+		return nil, fmt.Errorf("error getting stream %s: %w", stream, err)
+	}
+
+	var names []string
+
+	for info := range js.Consumers(stream) {
+		if !strings.HasPrefix(info.Name, prefix) || !_consumerIDPattern.MatchString(info.Name) {
+			continue
+		}
+
+		if info.Config.DeliverSubject != "" && !info.PushBound && time.Since(lastActivity(info)) >= inactiveFor {
+			names = append(names, info.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// lastActivity returns when the consumer was created or last delivered or got a message acknowledged.
+func lastActivity(info *nats.ConsumerInfo) time.Time {
+	last := info.Created
+
+	for _, activity := range []*time.Time{info.Delivered.Last, info.AckFloor.Last} {
+		if activity != nil && activity.After(last) {
+			last = *activity
+		}
+	}
+
+	return last
+}
+
+func removeOrphanedConsumers(js nats.JetStreamContext, stream, prefix string,
+	inactiveFor time.Duration,
+) ([]string, error) {
+	names, err := orphanedConsumers(js, stream, prefix, inactiveFor)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(names))
+
+	var errs []error
+
+	for _, name := range names {
+		err := js.DeleteConsumer(stream, name)
+		if errors.Is(err, nats.ErrConsumerNotFound) {
+			continue
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error removing consumer %s: %w", name, err))
+			continue
+		}
+
+		removed = append(removed, name)
+	}
+
+	return removed, errors.Join(errs...)
+}
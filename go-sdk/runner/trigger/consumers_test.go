@@ -0,0 +1,163 @@
+//go:build unit
+
+package trigger_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+var _consumerPrefix = localtest.Consumer("exit", "entrypoint") + "-"
+
+type ConsumersTestSuite struct {
+	suite.Suite
+	sim *localtest.Simulator
+	nc  *nats.Conn
+	js  nats.JetStreamContext
+}
+
+func (s *ConsumersTestSuite) SetupTest() {
+	s.setupSimulator("200ms")
+}
+
+func (s *ConsumersTestSuite) TestRemoveOrphanedConsumers_ExpectOnlyUnboundInactiveTriggerConsumersRemoved() {
+	// Given
+	orphaned := s.addConsumer(_consumerPrefix+uuid.New().String(), "orphaned")
+	bound := s.addConsumer(_consumerPrefix+uuid.New().String(), "bound")
+	other := s.addConsumer(localtest.Consumer("exit", "exit"), "other")
+
+	time.Sleep(200 * time.Millisecond)
+
+	recent := s.addConsumer(_consumerPrefix+uuid.New().String(), "recent")
+
+	subscription, err := s.nc.SubscribeSync("bound")
+	s.Require().NoError(err)
+
+	defer func() { _ = subscription.Unsubscribe() }()
+
+	s.Require().NoError(s.nc.Flush())
+
+	s.Require().Eventually(func() bool {
+		info, err := s.js.ConsumerInfo(localtest.StreamName, bound)
+		return err == nil && info.PushBound
+	}, localtest.Timeout, 10*time.Millisecond)
+
+	// When
+	listed, listErr := trigger.OrphanedConsumers(s.js, localtest.StreamName, 100*time.Millisecond)
+	removed, removeErr := trigger.RemoveOrphanedConsumers(s.js, localtest.StreamName, 100*time.Millisecond)
+
+	// Then
+	s.Require().NoError(listErr)
+	s.Require().NoError(removeErr)
+	s.Equal([]string{orphaned}, listed)
+	s.Equal([]string{orphaned}, removed)
+
+	_, err = s.js.ConsumerInfo(localtest.StreamName, orphaned)
+	s.ErrorIs(err, nats.ErrConsumerNotFound)
+
+	for _, name := range []string{bound, other, recent} {
+		_, err = s.js.ConsumerInfo(localtest.StreamName, name)
+		s.NoError(err, name)
+	}
+}
+
+func (s *ConsumersTestSuite) TestOrphanedConsumers_UnknownStream_ExpectError() {
+	// When
+	_, err := trigger.OrphanedConsumers(s.js, "unknown", time.Hour)
+
+	// Then
+	s.ErrorIs(err, nats.ErrStreamNotFound)
+}
+
+func (s *ConsumersTestSuite) TestRun_StaleConsumer_ExpectRemovedAtStartup() {
+	// Given
+	stale := s.addConsumer(_consumerPrefix+uuid.New().String(), "stale")
+
+	// The consumer is removed once inactive for the inactive threshold of the workflow.
+	time.Sleep(200 * time.Millisecond)
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(_ *trigger.Runner, _ sdk.KaiSDK) {})
+	s.sim.DiscardExit()
+
+	// When
+	stop := s.sim.Start()
+	defer stop()
+
+	// Then
+	s.Eventually(func() bool {
+		_, err := s.js.ConsumerInfo(localtest.StreamName, stale)
+		return errors.Is(err, nats.ErrConsumerNotFound)
+	}, localtest.Timeout, 10*time.Millisecond)
+}
+
+func (s *ConsumersTestSuite) TestRun_RecentlyActiveUnboundConsumer_ExpectKeptAtStartup() {
+	// Given
+	s.setupSimulator("1h")
+
+	// A replica reconnecting at startup has its consumer unbound for a moment.
+	reconnecting := s.addConsumer(_consumerPrefix+uuid.New().String(), "reconnecting")
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(_ *trigger.Runner, _ sdk.KaiSDK) {})
+	s.sim.DiscardExit()
+
+	// When
+	stop := s.sim.Start()
+	defer stop()
+
+	// Then
+	s.Eventually(func() bool {
+		return len(s.consumerNames()) == 2
+	}, localtest.Timeout, 10*time.Millisecond)
+
+	_, err := s.js.ConsumerInfo(localtest.StreamName, reconnecting)
+	s.NoError(err)
+}
+
+// setupSimulator creates the simulator of a workflow whose trigger consumers have the given
+// inactive threshold, and connects to it.
+func (s *ConsumersTestSuite) setupSimulator(inactiveThreshold string) {
+	s.sim = localtest.New(s.T(), localtest.Workflow(s.T(), "runner: {subscriber: {inactive_threshold: "+inactiveThreshold+"}}",
+		localtest.Trigger("entrypoint", "exit"),
+		localtest.Exit("exit", "entrypoint"),
+	))
+	s.nc = s.sim.Conn()
+	s.js = s.sim.JetStream()
+}
+
+func (s *ConsumersTestSuite) consumerNames() []string {
+	var names []string
+
+	for name := range s.js.ConsumerNames(localtest.StreamName) {
+		if strings.HasPrefix(name, _consumerPrefix) {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func (s *ConsumersTestSuite) addConsumer(name, deliverSubject string) string {
+	_, err := s.js.AddConsumer(localtest.StreamName, &nats.ConsumerConfig{
+		Durable:        name,
+		DeliverSubject: deliverSubject,
+		FilterSubject:  localtest.Subject("exit"),
+		AckPolicy:      nats.AckExplicitPolicy,
+	})
+	s.Require().NoError(err)
+
+	return name
+}
+
+func TestConsumersTestSuite(t *testing.T) {
+	suite.Run(t, new(ConsumersTestSuite))
+}
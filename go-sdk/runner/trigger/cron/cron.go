@@ -0,0 +1,221 @@
+// Package cron provides a trigger sending a request to the workflow on a schedule, either a cron
+// expression or a fixed interval.
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const (
+	_cronLoggerName = "[CRON]"
+	// _leaseTTL is how long the leases of the ticks are kept in their bucket.
+	_leaseTTL = time.Hour
+)
+
+var ErrInvalidTrigger = errors.New("invalid cron trigger")
+
+// PayloadFunc creates the payload sent to the workflow at the given tick.
+type PayloadFunc func(ctx context.Context, tick time.Time) (proto.Message, error)
+
+// Trigger sends the payloads created by its PayloadFunc to the workflow on a schedule. A tick is
+// skipped when the run of the previous one is still in progress.
+type Trigger struct {
+	schedule Schedule
+	payload  PayloadFunc
+	options  *options
+}
+
+// New creates a Trigger running on the given schedule.
+func New(schedule Schedule, payload PayloadFunc, opts ...Option) (*Trigger, error) {
+	if schedule == nil || payload == nil {
+		return nil, fmt.Errorf("%w: schedule and payload function are required", ErrInvalidTrigger)
+	}
+
+	return &Trigger{
+		schedule: schedule,
+		payload:  payload,
+		options:  newOptions(opts...),
+	}, nil
+}
+
+// RunnerFunc returns a trigger.RunnerFunc running the schedule until the trigger shuts down. It
+// returns once the run in progress, if any, finishes: shutting down only stops new ticks, the run
+// in progress keeps its timeout and gets its response until the shutdown grace period expires.
+func (t *Trigger) RunnerFunc() trigger.RunnerFunc {
+	return func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		logger := t.getLogger(kaiSDK.Logger)
+
+		var (
+			leaseStore *lease
+			err        error
+		)
+
+		if t.options.leaseBucket != "" {
+			leaseStore, err = newLease(tr.JetStream(), t.options.leaseBucket, kaiSDK.Metadata.GetProcess())
+			if err != nil {
+				logger.Error(err, "Error initializing lease")
+				return
+			}
+		}
+
+		t.run(tr, leaseStore, logger)
+	}
+}
+
+func (t *Trigger) run(tr *trigger.Runner, leaseStore *lease, logger logr.Logger) {
+	ctx := tr.Context()
+
+	var (
+		running atomic.Bool
+		wg      sync.WaitGroup
+	)
+
+	defer wg.Wait()
+
+	for {
+		tick := t.schedule.Next(time.Now())
+		if tick.IsZero() {
+			logger.Info("The schedule has no more ticks")
+			return
+		}
+
+		timer := time.NewTimer(time.Until(tick))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !running.CompareAndSwap(false, true) {
+			logger.V(1).Info(fmt.Sprintf("Skipping tick %s, the previous run is still in progress", tick))
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			defer running.Store(false)
+
+			t.fire(ctx, tr, leaseStore, tick, logger)
+		}()
+	}
+}
+
+func (t *Trigger) fire(ctx context.Context, tr *trigger.Runner, leaseStore *lease, tick time.Time, logger logr.Logger) {
+	if t.options.jitter > 0 {
+		timer := time.NewTimer(rand.N(t.options.jitter))
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		}
+	}
+
+	if leaseStore != nil {
+		acquired, err := leaseStore.acquire(tick)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Error taking the lease of tick %s", tick))
+			return
+		}
+
+		if !acquired {
+			logger.V(1).Info(fmt.Sprintf("Tick %s is run by another replica", tick))
+			return
+		}
+	}
+
+	// The trigger context is cancelled as soon as the runner starts shutting down, which must not
+	// cancel the run in progress.
+	runCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), t.options.timeout)
+	defer cancel()
+
+	logger.V(1).Info(fmt.Sprintf("Running tick %s", tick))
+
+	payload, err := t.payload(runCtx, tick)
+	if err != nil {
+		t.handleResponse(tick, nil, fmt.Errorf("error creating payload: %w", err), logger)
+		return
+	}
+
+	response, err := tr.Request(runCtx, payload)
+	t.handleResponse(tick, response, err, logger)
+}
+
+func (t *Trigger) handleResponse(tick time.Time, response *anypb.Any, err error, logger logr.Logger) {
+	if err != nil {
+		logger.Error(err, fmt.Sprintf("Error running tick %s", tick))
+	}
+
+	if t.options.responseHandler != nil {
+		t.options.responseHandler(tick, response, err)
+	}
+}
+
+func (t *Trigger) getLogger(defaultLogger logr.Logger) logr.Logger {
+	if t.options.logger != nil {
+		return *t.options.logger
+	}
+
+	return defaultLogger.WithName(_cronLoggerName)
+}
+
+// lease makes sure a single replica runs each tick by creating a key for it in a key-value bucket.
+type lease struct {
+	kv     nats.KeyValue
+	prefix string
+	owner  string
+}
+
+func newLease(js nats.JetStreamContext, bucket, process string) (*lease, error) {
+	kv, err := js.KeyValue(bucket)
+	if errors.Is(err, nats.ErrBucketNotFound) {
+		kv, err = js.CreateKeyValue(&nats.KeyValueConfig{
+			Bucket: bucket,
+			TTL:    _leaseTTL,
+		})
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting lease bucket %s: %w", bucket, err)
+	}
+
+	return &lease{
+		kv:     kv,
+		prefix: common.KeyToken(process),
+		owner:  uuid.New().String(),
+	}, nil
+}
+
+func (l *lease) acquire(tick time.Time) (bool, error) {
+	_, err := l.kv.Create(fmt.Sprintf("%s.%d", l.prefix, tick.UnixMilli()), []byte(l.owner))
+	if errors.Is(err, nats.ErrKeyExists) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
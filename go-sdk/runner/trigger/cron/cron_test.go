@@ -0,0 +1,309 @@
+//go:build unit
+
+package cron_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger/cron"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type CronTestSuite struct {
+	suite.Suite
+	sim *localtest.Simulator
+}
+
+func (s *CronTestSuite) SetupTest() {
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	s.sim.ForwardExit()
+}
+
+func (s *CronTestSuite) TestRun_Interval_ExpectResponses() {
+	// Given
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		kaiSDK.Messaging.SendAny(payload)
+		return nil
+	})
+
+	responses := make(chan string, 10)
+
+	cronTrigger, err := cron.New(cron.Every(50*time.Millisecond), tickPayload,
+		cron.WithJitter(10*time.Millisecond),
+		cron.WithResponseHandler(func(_ time.Time, response *anypb.Any, err error) {
+			s.NoError(err)
+
+			value := &wrapperspb.StringValue{}
+			if err == nil && response.UnmarshalTo(value) == nil {
+				responses <- value.GetValue()
+			}
+		}),
+	)
+	s.Require().NoError(err)
+
+	// When
+	s.runUntil(cronTrigger.RunnerFunc(), func() bool {
+		return len(responses) >= 2
+	})
+
+	// Then
+	s.Equal("tick", <-responses)
+}
+
+func (s *CronTestSuite) TestRun_SlowRun_ExpectTicksSkipped() {
+	// Given
+	var inFlight, maxInFlight, runs atomic.Int32
+
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		time.Sleep(100 * time.Millisecond)
+		kaiSDK.Messaging.SendAny(payload)
+
+		return nil
+	})
+
+	cronTrigger, err := cron.New(cron.Every(10*time.Millisecond),
+		func(ctx context.Context, tick time.Time) (proto.Message, error) {
+			current := inFlight.Add(1)
+			if current > maxInFlight.Load() {
+				maxInFlight.Store(current)
+			}
+
+			return tickPayload(ctx, tick)
+		},
+		cron.WithResponseHandler(func(_ time.Time, _ *anypb.Any, _ error) {
+			inFlight.Add(-1)
+			runs.Add(1)
+		}),
+	)
+	s.Require().NoError(err)
+
+	// When
+	s.runUntil(cronTrigger.RunnerFunc(), func() bool {
+		return runs.Load() >= 3
+	})
+
+	// Then
+	s.Equal(int32(1), maxInFlight.Load())
+}
+
+func (s *CronTestSuite) TestRun_TriggerStoppedDuringRun_ExpectResponseWithinGracePeriod() {
+	// Given
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), "runner: {shutdown_grace_period: 5s}"))
+	s.sim.ForwardExit()
+
+	var once sync.Once
+
+	started := make(chan struct{})
+
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		once.Do(func() { close(started) })
+		time.Sleep(200 * time.Millisecond)
+		kaiSDK.Messaging.SendAny(payload)
+
+		return nil
+	})
+
+	runErrs := make(chan error, 10)
+
+	cronTrigger, err := cron.New(cron.Every(50*time.Millisecond), tickPayload,
+		cron.WithResponseHandler(func(_ time.Time, _ *anypb.Any, err error) {
+			runErrs <- err
+		}),
+	)
+	s.Require().NoError(err)
+
+	runnerFunc := cronTrigger.RunnerFunc()
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		go func() {
+			<-started
+			tr.Stop()
+		}()
+
+		runnerFunc(tr, kaiSDK)
+	})
+
+	// When
+	s.sim.Start()
+
+	// Then
+	select {
+	case err := <-runErrs:
+		s.NoError(err)
+	case <-time.After(localtest.Timeout):
+		s.Fail("run not finished")
+	}
+}
+
+func (s *CronTestSuite) TestRun_WithLease_ExpectEachTickRunOnce() {
+	// Given
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		kaiSDK.Messaging.SendAny(payload)
+		return nil
+	})
+
+	var (
+		mu    sync.Mutex
+		ticks = map[time.Time]int{}
+	)
+
+	newReplica := func() trigger.RunnerFunc {
+		cronTrigger, err := cron.New(cron.Every(50*time.Millisecond),
+			func(ctx context.Context, tick time.Time) (proto.Message, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				ticks[tick]++
+
+				return tickPayload(ctx, tick)
+			},
+			cron.WithLease("cron-leases"),
+			cron.WithJitter(5*time.Millisecond),
+		)
+		s.Require().NoError(err)
+
+		return cronTrigger.RunnerFunc()
+	}
+
+	replicas := []trigger.RunnerFunc{newReplica(), newReplica()}
+
+	// When
+	s.runUntil(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		var wg sync.WaitGroup
+
+		for _, replica := range replicas {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				replica(tr, kaiSDK)
+			}()
+		}
+
+		wg.Wait()
+	}, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(ticks) >= 3
+	})
+
+	// Then
+	mu.Lock()
+	defer mu.Unlock()
+
+	for tick, count := range ticks {
+		s.Equal(1, count, tick)
+	}
+}
+
+func (s *CronTestSuite) TestRun_WithLeaseAndProcessNameInvalidInKeys_ExpectTicksRun() {
+	// Given
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		kaiSDK.Messaging.SendAny(payload)
+		return nil
+	})
+
+	var ticks atomic.Int32
+
+	cronTrigger, err := cron.New(cron.Every(50*time.Millisecond),
+		func(ctx context.Context, tick time.Time) (proto.Message, error) {
+			ticks.Add(1)
+			return tickPayload(ctx, tick)
+		},
+		cron.WithLease("cron-leases"),
+	)
+	s.Require().NoError(err)
+
+	runnerFunc := cronTrigger.RunnerFunc()
+
+	// When
+	s.runUntil(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		kaiSDK.Metadata = renamedProcess{metadata: kaiSDK.Metadata, process: "cron entrypoint.v1"}
+		runnerFunc(tr, kaiSDK)
+	}, func() bool {
+		return ticks.Load() >= 2
+	})
+
+	// Then
+	s.GreaterOrEqual(ticks.Load(), int32(2))
+}
+
+func (s *CronTestSuite) TestNew_WithoutSchedule_ExpectError() {
+	// When
+	_, err := cron.New(nil, tickPayload)
+
+	// Then
+	s.ErrorIs(err, cron.ErrInvalidTrigger)
+}
+
+func tickPayload(_ context.Context, _ time.Time) (proto.Message, error) {
+	return wrapperspb.String("tick"), nil
+}
+
+func (s *CronTestSuite) setupTask(handler task.Handler) {
+	s.sim.TaskRunner("transformer").WithHandler(handler)
+}
+
+// runUntil runs the simulator with the given trigger until the condition holds, and checks the
+// trigger returns once the simulator stops.
+func (s *CronTestSuite) runUntil(runnerFunc trigger.RunnerFunc, condition func() bool) {
+	stopped := make(chan struct{})
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		defer close(stopped)
+		runnerFunc(tr, kaiSDK)
+	})
+
+	stop := s.sim.Start()
+
+	s.Eventually(condition, localtest.Timeout, 10*time.Millisecond)
+
+	stop()
+
+	select {
+	case <-stopped:
+	case <-time.After(localtest.Timeout):
+		s.Fail("trigger did not stop")
+	}
+}
+
+type metadata interface {
+	GetProcess() string
+	GetWorkflow() string
+	GetWorkflowType() string
+	GetProduct() string
+	GetProcessType() string
+	GetVersion() string
+	GetEphemeralStorageName() string
+	GetGlobalCentralizedConfigurationName() string
+	GetProductCentralizedConfigurationName() string
+	GetWorkflowCentralizedConfigurationName() string
+	GetProcessCentralizedConfigurationName() string
+}
+
+// renamedProcess gives the process a name the simulator does not allow.
+type renamedProcess struct {
+	metadata
+	process string
+}
+
+func (m renamedProcess) GetProcess() string {
+	return m.process
+}
+
+func TestCronTestSuite(t *testing.T) {
+	suite.Run(t, new(CronTestSuite))
+}
@@ -0,0 +1,75 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const _defaultTimeout = 30 * time.Second
+
+// ResponseHandler receives the response of the workflow to each run, or the error of the run.
+type ResponseHandler func(tick time.Time, response *anypb.Any, err error)
+
+// Option configures how New creates a Trigger.
+type Option func(*options)
+
+type options struct {
+	jitter          time.Duration
+	timeout         time.Duration
+	leaseBucket     string
+	responseHandler ResponseHandler
+	logger          *logr.Logger
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		timeout: _defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithJitter delays every run by a random duration up to the given one, to spread the load of
+// processes scheduled at the same time.
+func WithJitter(jitter time.Duration) Option {
+	return func(o *options) {
+		o.jitter = jitter
+	}
+}
+
+// WithTimeout sets how long a run waits for the response of the workflow, 30 seconds by default.
+// The next ticks are skipped while a run is in progress.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithLease makes the replicas of the process take a lease for each tick in the given key-value
+// bucket, created when missing, so only one of them runs it. Replicas must share the schedule.
+func WithLease(bucket string) Option {
+	return func(o *options) {
+		o.leaseBucket = bucket
+	}
+}
+
+// WithResponseHandler sets the function receiving the response of the workflow to each run.
+// Responses are discarded by default.
+func WithResponseHandler(handler ResponseHandler) Option {
+	return func(o *options) {
+		o.responseHandler = handler
+	}
+}
+
+// WithLogger sets the logger of the trigger. The logger of the trigger runner is used by default.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
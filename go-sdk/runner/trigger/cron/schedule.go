@@ -0,0 +1,229 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// _maxSearch bounds how far ahead the next time of a cron expression is looked for, so
+// expressions that never match, such as "0 0 30 2 *", do not loop forever.
+const _maxSearch = 5 * 366 * 24 * time.Hour
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// Schedule tells when a trigger fires.
+type Schedule interface {
+	// Next returns the first time after the given one the trigger fires at, or the zero time
+	// if there is none.
+	Next(after time.Time) time.Time
+}
+
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a schedule firing at a fixed interval. Times are aligned to multiples of the
+// interval since the zero time, so every replica of a process fires at the same times.
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	if s.interval <= 0 {
+		return time.Time{}
+	}
+
+	return after.Truncate(s.interval).Add(s.interval)
+}
+
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var _cronFields = []cronField{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12},
+	{name: "day of week", min: 0, max: 7},
+}
+
+var _descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	// anyDay and anyWeekday tell whether the day of month or the day of week is "*". When both
+	// are restricted, a day matches if it matches either of them.
+	anyDay, anyWeekday bool
+}
+
+// Parse parses a standard cron expression with five fields (minute, hour, day of month, month and
+// day of week), supporting "*", lists, ranges and steps, such as "*/5 9-17 * * 1-5". The
+// descriptors "@hourly", "@daily", "@midnight", "@weekly", "@monthly", "@yearly" and "@annually"
+// are accepted too, as well as "@every <duration>" for fixed intervals. Times are computed in the
+// location of the time given to Next.
+func Parse(expression string) (Schedule, error) {
+	expression = strings.TrimSpace(expression)
+
+	if interval, ok := strings.CutPrefix(expression, "@every "); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("%w: invalid interval %q", ErrInvalidSchedule, interval)
+		}
+
+		return Every(duration), nil
+	}
+
+	if descriptor, ok := _descriptors[expression]; ok {
+		expression = descriptor
+	}
+
+	fields := strings.Fields(expression)
+	if len(fields) != len(_cronFields) {
+		return nil, fmt.Errorf("%w: expected %d fields in %q", ErrInvalidSchedule, len(_cronFields), expression)
+	}
+
+	values := make([]uint64, len(fields))
+
+	for i, field := range fields {
+		bits, err := parseField(field, _cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = bits
+	}
+
+	weekdays := values[4]
+	if weekdays&(1<<7) != 0 {
+		// Both 0 and 7 mean Sunday.
+		weekdays |= 1
+	}
+
+	return &cronSchedule{
+		minutes:    values[0],
+		hours:      values[1],
+		days:       values[2],
+		months:     values[3],
+		weekdays:   weekdays,
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+func parseField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+
+	for _, part := range strings.Split(field, ",") {
+		first, last, step, err := parseRange(part, spec)
+		if err != nil {
+			return 0, err
+		}
+
+		for value := first; value <= last; value += step {
+			bits |= 1 << value
+		}
+	}
+
+	return bits, nil
+}
+
+func parseRange(part string, spec cronField) (first, last, step int, err error) {
+	invalid := func() error {
+		return fmt.Errorf("%w: invalid %s %q", ErrInvalidSchedule, spec.name, part)
+	}
+
+	rangePart, stepPart, hasStep := strings.Cut(part, "/")
+
+	step = 1
+
+	if hasStep {
+		step, err = strconv.Atoi(stepPart)
+		if err != nil || step <= 0 {
+			return 0, 0, 0, invalid()
+		}
+	}
+
+	switch {
+	case rangePart == "*":
+		first, last = spec.min, spec.max
+	case strings.Contains(rangePart, "-"):
+		start, end, _ := strings.Cut(rangePart, "-")
+
+		first, err = strconv.Atoi(start)
+		if err != nil {
+			return 0, 0, 0, invalid()
+		}
+
+		last, err = strconv.Atoi(end)
+		if err != nil {
+			return 0, 0, 0, invalid()
+		}
+	default:
+		first, err = strconv.Atoi(rangePart)
+		if err != nil {
+			return 0, 0, 0, invalid()
+		}
+
+		last = first
+		if hasStep {
+			last = spec.max
+		}
+	}
+
+	if first < spec.min || last > spec.max || first > last {
+		return 0, 0, 0, invalid()
+	}
+
+	return first, last, step, nil
+}
+
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(_maxSearch)
+
+	for t.Before(limit) {
+		switch {
+		case s.months&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.matchesDay(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case s.hours&(1<<uint(t.Hour())) == 0:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case s.minutes&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) matchesDay(t time.Time) bool {
+	dayMatches := s.days&(1<<uint(t.Day())) != 0
+	weekdayMatches := s.weekdays&(1<<uint(t.Weekday())) != 0
+
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekdayMatches
+	case s.anyWeekday:
+		return dayMatches
+	default:
+		return dayMatches || weekdayMatches
+	}
+}
@@ -0,0 +1,100 @@
+//go:build unit
+
+package cron_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger/cron"
+)
+
+func TestParse_Next(t *testing.T) {
+	// Wednesday.
+	after := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		expression string
+		want       time.Time
+	}{
+		{
+			name:       "Every minute",
+			expression: "* * * * *",
+			want:       time.Date(2024, 1, 10, 10, 8, 0, 0, time.UTC),
+		},
+		{
+			name:       "Step of minutes",
+			expression: "*/15 * * * *",
+			want:       time.Date(2024, 1, 10, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			name:       "List of hours",
+			expression: "0 8,12,18 * * *",
+			want:       time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Range of week days",
+			expression: "30 9 * * 6-7",
+			want:       time.Date(2024, 1, 13, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:       "Day of month or day of week",
+			expression: "0 0 1 * 5",
+			want:       time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Next month",
+			expression: "0 0 1 * *",
+			want:       time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Leap day",
+			expression: "0 0 29 2 *",
+			want:       time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Descriptor",
+			expression: "@hourly",
+			want:       time.Date(2024, 1, 10, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:       "Interval",
+			expression: "@every 10m",
+			want:       time.Date(2024, 1, 10, 10, 10, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := cron.Parse(tt.expression)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, schedule.Next(after))
+		})
+	}
+}
+
+func TestParse_NeverMatches_ExpectZeroTime(t *testing.T) {
+	schedule, err := cron.Parse("0 0 30 2 *")
+	require.NoError(t, err)
+
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestParse_InvalidExpression_ExpectError(t *testing.T) {
+	for _, expression := range []string{"", "* * * *", "60 * * * *", "* 5-2 * * *", "*/0 * * * *", "@every -1s", "a * * * *"} {
+		_, err := cron.Parse(expression)
+		assert.ErrorIs(t, err, cron.ErrInvalidSchedule, expression)
+	}
+}
+
+func TestEvery_Next_ExpectAlignedToInterval(t *testing.T) {
+	after := time.Date(2024, 1, 10, 10, 7, 30, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 10, 10, 8, 0, 0, time.UTC), cron.Every(time.Minute).Next(after))
+	assert.Equal(t, time.Date(2024, 1, 10, 10, 7, 35, 0, time.UTC), cron.Every(5*time.Second).Next(after))
+}
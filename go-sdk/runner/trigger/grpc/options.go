@@ -0,0 +1,66 @@
+package grpc
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/grpc"
+)
+
+const (
+	_defaultAddress = ":9090"
+	_defaultTimeout = 30 * time.Second
+)
+
+// Option configures how New creates a Service.
+type Option func(*options)
+
+type options struct {
+	address       string
+	timeout       time.Duration
+	serverOptions []grpc.ServerOption
+	logger        *logr.Logger
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		address: _defaultAddress,
+		timeout: _defaultTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithAddress sets the TCP address the server listens on, ":9090" by default.
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithTimeout sets how long a call without deadline waits for the response of the workflow when
+// its method does not set its own timeout, 30 seconds by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithServerOptions sets the options of the gRPC server created by the RunnerFunc, such as its
+// credentials or interceptors.
+func WithServerOptions(serverOptions ...grpc.ServerOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, serverOptions...)
+	}
+}
+
+// WithLogger sets the logger of the service. The logger of the trigger is used by default.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
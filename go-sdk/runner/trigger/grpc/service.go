@@ -0,0 +1,243 @@
+// Package grpc provides a trigger exposing a workflow as a gRPC service. Every unary method of the
+// service sends its request to the workflow and returns the response of the workflow to it.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const (
+	// RequestIDMetadata holds the request ID of the workflow request. It is taken from the
+	// incoming metadata when present, so it must be unique, and is always sent in the header.
+	RequestIDMetadata = "x-request-id"
+
+	_serviceLoggerName = "[GRPC]"
+)
+
+var ErrInvalidService = errors.New("invalid gRPC service")
+
+// Method is a unary method of the service.
+type Method struct {
+	// Name is the name of the method, as declared in the service definition.
+	Name string
+	// Request is the message type of the requests. Requests are a google.protobuf.Any, sent to the
+	// workflow as they are, when it is nil.
+	Request proto.Message
+	// Response is the message type the responses of the workflow are converted into. Responses
+	// are returned as a google.protobuf.Any when it is nil.
+	Response proto.Message
+	// Timeout overrides the timeout of the service for this method.
+	Timeout time.Duration
+}
+
+// Service serves the methods of a gRPC service through a trigger.
+type Service struct {
+	name    string
+	methods []Method
+	options *options
+}
+
+// New creates a Service with the given fully qualified name, such as "my.package.MyService",
+// and methods.
+func New(name string, methods []Method, opts ...Option) (*Service, error) {
+	if name == "" || len(methods) == 0 {
+		return nil, fmt.Errorf("%w: a name and at least one method are required", ErrInvalidService)
+	}
+
+	for _, method := range methods {
+		if method.Name == "" {
+			return nil, fmt.Errorf("%w: every method needs a name", ErrInvalidService)
+		}
+	}
+
+	return &Service{
+		name:    name,
+		methods: methods,
+		options: newOptions(opts...),
+	}, nil
+}
+
+// RunnerFunc returns a trigger.RunnerFunc serving the service on its address until the trigger
+// shuts down. It returns once the calls in process are answered. The trigger is stopped when the
+// server cannot listen or serve.
+func (s *Service) RunnerFunc() trigger.RunnerFunc {
+	return func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		logger := s.getLogger(kaiSDK.Logger)
+
+		listener, err := net.Listen("tcp", s.options.address)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Error listening on %s, stopping the trigger", s.options.address))
+			tr.Stop()
+
+			return
+		}
+
+		server := grpc.NewServer(s.options.serverOptions...)
+		s.register(server, tr, logger)
+
+		stopped := make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			<-tr.Context().Done()
+			server.GracefulStop()
+		}()
+
+		logger.Info(fmt.Sprintf("Listening on %s", listener.Addr()))
+
+		if err := server.Serve(listener); err != nil {
+			logger.Error(err, "Error serving gRPC requests, stopping the trigger")
+			tr.Stop()
+		}
+
+		// Serve may return before the calls in process finish, which GracefulStop waits for.
+		<-stopped
+	}
+}
+
+// Register registers the service in the given server, sending its requests through the given
+// trigger, which must be running when they are received.
+func (s *Service) Register(registrar grpc.ServiceRegistrar, tr *trigger.Runner) {
+	s.register(registrar, tr, s.getLogger(logr.Discard()))
+}
+
+func (s *Service) register(registrar grpc.ServiceRegistrar, tr *trigger.Runner, logger logr.Logger) {
+	desc := &grpc.ServiceDesc{
+		ServiceName: s.name,
+		HandlerType: (*any)(nil),
+		Methods:     make([]grpc.MethodDesc, 0, len(s.methods)),
+	}
+
+	for _, method := range s.methods {
+		desc.Methods = append(desc.Methods, grpc.MethodDesc{
+			MethodName: method.Name,
+			Handler:    s.methodHandler(tr, logger, method),
+		})
+	}
+
+	registrar.RegisterService(desc, s)
+}
+
+// methodHandler returns the handler of a method in the form expected by grpc.MethodDesc.
+func (s *Service) methodHandler(tr *trigger.Runner, logger logr.Logger, method Method,
+) func(any, context.Context, func(any) error, grpc.UnaryServerInterceptor) (any, error) {
+	fullMethod := fmt.Sprintf("/%s/%s", s.name, method.Name)
+
+	return func(_ any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		request := newMessage(method.Request)
+		if err := dec(request); err != nil {
+			return nil, err
+		}
+
+		handler := func(ctx context.Context, request any) (any, error) {
+			return s.call(ctx, tr, logger, method, request.(proto.Message))
+		}
+
+		if interceptor == nil {
+			return handler(ctx, request)
+		}
+
+		return interceptor(ctx, request, &grpc.UnaryServerInfo{Server: s, FullMethod: fullMethod}, handler)
+	}
+}
+
+func (s *Service) call(ctx context.Context, tr *trigger.Runner, logger logr.Logger, method Method,
+	request proto.Message,
+) (proto.Message, error) {
+	requestID := getRequestID(ctx)
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(RequestIDMetadata, requestID)); err != nil {
+		logger.V(1).Info(fmt.Sprintf("Error setting request ID header: %s", err))
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		timeout := method.Timeout
+		if timeout == 0 {
+			timeout = s.options.timeout
+		}
+
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	response, err := tr.RequestWithID(ctx, requestID, request)
+	if err != nil {
+		logger.WithValues(sdk.LoggerRequestID, requestID).V(1).Info(fmt.Sprintf("Error processing request: %s", err))
+		return nil, status.Error(statusCode(err), err.Error())
+	}
+
+	result := newMessage(method.Response)
+	if response == nil {
+		return result, nil
+	}
+
+	if method.Response == nil {
+		return response, nil
+	}
+
+	if err := response.UnmarshalTo(result); err != nil {
+		return nil, status.Errorf(codes.Internal, "error converting response of type %q: %s", response.GetTypeUrl(), err)
+	}
+
+	return result, nil
+}
+
+func (s *Service) getLogger(defaultLogger logr.Logger) logr.Logger {
+	if s.options.logger != nil {
+		return *s.options.logger
+	}
+
+	return defaultLogger.WithName(_serviceLoggerName)
+}
+
+func getRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(RequestIDMetadata); len(values) > 0 && values[0] != "" {
+			return values[0]
+		}
+	}
+
+	return uuid.New().String()
+}
+
+func newMessage(messageType proto.Message) proto.Message {
+	if messageType == nil {
+		return &anypb.Any{}
+	}
+
+	return messageType.ProtoReflect().New().Interface()
+}
+
+func statusCode(err error) codes.Code {
+	switch {
+	case errors.Is(err, trigger.ErrRequestFailed):
+		return codes.Internal
+	case errors.Is(err, trigger.ErrDuplicateRequestID):
+		return codes.AlreadyExists
+	case errors.Is(err, context.DeadlineExceeded):
+		return codes.DeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return codes.Canceled
+	default:
+		return codes.Unavailable
+	}
+}
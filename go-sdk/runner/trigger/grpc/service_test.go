@@ -0,0 +1,274 @@
+//go:build unit
+
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	triggerGRPC "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger/grpc"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+const _serviceName = "test.Workflow"
+
+var _methods = []triggerGRPC.Method{
+	{Name: "Upper", Request: &wrapperspb.StringValue{}, Response: &wrapperspb.StringValue{}},
+	{Name: "Any"},
+}
+
+type ServiceTestSuite struct {
+	suite.Suite
+	logger logr.Logger
+	sim    *localtest.Simulator
+	server *grpc.Server
+	conn   *grpc.ClientConn
+}
+
+func (s *ServiceTestSuite) SetupTest() {
+	s.logger = testr.NewWithOptions(s.T(), testr.Options{Verbosity: 1})
+
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	s.sim.ForwardExit()
+}
+
+func (s *ServiceTestSuite) TearDownTest() {
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+
+	if s.server != nil {
+		s.server.Stop()
+		s.server = nil
+	}
+}
+
+func (s *ServiceTestSuite) TestInvoke_TypedMethod_ExpectTypedResponse() {
+	// Given
+	s.setupTask(task.TypedHandler(func(kaiSDK sdk.KaiSDK, value *wrapperspb.StringValue) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(strings.ToUpper(value.GetValue())))
+	}))
+	s.startService()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), triggerGRPC.RequestIDMetadata, "some-request-id")
+	response := &wrapperspb.StringValue{}
+
+	var header metadata.MD
+
+	// When
+	err := s.conn.Invoke(ctx, "/test.Workflow/Upper", wrapperspb.String("hello"), response, grpc.Header(&header))
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal("HELLO", response.GetValue())
+	s.Equal([]string{"some-request-id"}, header.Get(triggerGRPC.RequestIDMetadata))
+}
+
+func (s *ServiceTestSuite) TestInvoke_AnyMethod_ExpectAnyResponse() {
+	// Given
+	s.setupTask(task.TypedHandler(func(kaiSDK sdk.KaiSDK, value *wrapperspb.StringValue) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.Int32(int32(len(value.GetValue()))))
+	}))
+	s.startService()
+
+	request, err := anypb.New(wrapperspb.String("hello"))
+	s.Require().NoError(err)
+
+	response := &anypb.Any{}
+
+	var header metadata.MD
+
+	// When
+	err = s.conn.Invoke(context.Background(), "/test.Workflow/Any", request, response, grpc.Header(&header))
+
+	// Then
+	s.Require().NoError(err)
+
+	value := &wrapperspb.Int32Value{}
+	s.Require().NoError(response.UnmarshalTo(value))
+	s.Equal(int32(5), value.GetValue())
+	s.NotEmpty(header.Get(triggerGRPC.RequestIDMetadata))
+}
+
+func (s *ServiceTestSuite) TestInvoke_ErrorResponse_ExpectInternal() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return errors.New("invalid request")
+	})
+	s.startService()
+
+	// When
+	err := s.conn.Invoke(context.Background(), "/test.Workflow/Upper", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+
+	// Then
+	s.Equal(codes.Internal, status.Code(err))
+	s.Contains(err.Error(), "invalid request")
+}
+
+func (s *ServiceTestSuite) TestInvoke_RequestIDInProcess_ExpectAlreadyExists() {
+	// Given
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		started <- struct{}{}
+		<-release
+
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+	s.startService()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), triggerGRPC.RequestIDMetadata, "some-request-id")
+
+	firstErrors := make(chan error, 1)
+
+	go func() {
+		firstErrors <- s.conn.Invoke(ctx, "/test.Workflow/Upper", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+	}()
+
+	<-started
+
+	// When
+	err := s.conn.Invoke(ctx, "/test.Workflow/Upper", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+	close(release)
+
+	// Then
+	s.Equal(codes.AlreadyExists, status.Code(err))
+	s.NoError(<-firstErrors)
+}
+
+func (s *ServiceTestSuite) TestInvoke_Deadline_ExpectPropagatedToWorkflow() {
+	// Given
+	handlerDeadlines := make(chan bool, 1)
+
+	s.setupTask(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		_, ok := kaiSDK.Context().Deadline()
+		handlerDeadlines <- ok
+
+		<-kaiSDK.Context().Done()
+
+		return kaiSDK.Context().Err()
+	})
+	s.startService()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// When
+	err := s.conn.Invoke(ctx, "/test.Workflow/Upper", wrapperspb.String("hello"), &wrapperspb.StringValue{})
+
+	// Then
+	s.Equal(codes.DeadlineExceeded, status.Code(err))
+	s.True(<-handlerDeadlines)
+}
+
+func (s *ServiceTestSuite) TestRunnerFunc_AddressInUse_ExpectTriggerStopped() {
+	// Given
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	defer listener.Close()
+
+	service, err := triggerGRPC.New(_serviceName, _methods,
+		triggerGRPC.WithAddress(listener.Addr().String()), triggerGRPC.WithLogger(s.logger))
+	s.Require().NoError(err)
+
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+
+	runners := make(chan *trigger.Runner, 1)
+	runnerFunc := service.RunnerFunc()
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		runners <- tr
+		runnerFunc(tr, kaiSDK)
+	})
+
+	// When
+	s.sim.Start()
+
+	// Then
+	select {
+	case tr := <-runners:
+		s.Eventually(func() bool { return tr.Context().Err() != nil }, localtest.Timeout/2, 10*time.Millisecond)
+	case <-time.After(localtest.Timeout):
+		s.Fail("trigger not started")
+	}
+}
+
+func (s *ServiceTestSuite) TestNew_WithoutMethods_ExpectError() {
+	// When
+	_, err := triggerGRPC.New(_serviceName, nil)
+
+	// Then
+	s.ErrorIs(err, triggerGRPC.ErrInvalidService)
+}
+
+func (s *ServiceTestSuite) setupTask(handler task.Handler) {
+	s.sim.TaskRunner("transformer").WithHandler(handler)
+}
+
+// startService runs the simulator and serves the service through its trigger on an in-memory
+// connection.
+func (s *ServiceTestSuite) startService() {
+	service, err := triggerGRPC.New(_serviceName, _methods, triggerGRPC.WithLogger(s.logger))
+	s.Require().NoError(err)
+
+	runners := make(chan *trigger.Runner, 1)
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		runners <- tr
+	})
+
+	s.sim.Start()
+
+	var tr *trigger.Runner
+
+	select {
+	case tr = <-runners:
+	case <-time.After(localtest.Timeout):
+		s.FailNow("trigger not started")
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+
+	s.server = grpc.NewServer()
+	service.Register(s.server, tr)
+
+	go func() {
+		_ = s.server.Serve(listener)
+	}()
+
+	s.conn, err = grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	s.Require().NoError(err)
+}
+
+func TestServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceTestSuite))
+}
@@ -2,11 +2,9 @@ package trigger
 
 import (
 	"fmt"
-	"os"
-	"os/signal"
-	"sync"
-	"syscall"
+	"time"
 
+	"github.com/spf13/viper"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
@@ -20,10 +18,10 @@ const (
 	_finalizerLoggerName       = "[FINALIZER]"
 )
 
-func composeInitializer(initializer common.Initializer) common.Initializer {
+func composeInitializer(initializer common.Initializer, config *viper.Viper) common.Initializer {
 	return func(sdk sdk.KaiSDK) {
 		sdk.Logger.WithName(_initializerLoggerName).V(1).Info("Initializing TriggerRunner...")
-		common.InitializeProcessConfiguration(sdk)
+		common.InitializeProcessConfigurationWithConfig(sdk, config)
 
 		if initializer != nil {
 			sdk.Logger.WithName(_initializerLoggerName).V(3).Info("Executing user initializer...")
@@ -39,22 +37,44 @@ func composeRunner(userRunner RunnerFunc) RunnerFunc {
 	return func(runner *Runner, kaiSDK sdk.KaiSDK) {
 		kaiSDK.Logger.WithName(_runnerLoggerName).V(1).Info("Running TriggerRunner...")
 
+		finished := make(chan struct{})
+
 		if userRunner != nil {
 			kaiSDK.Logger.WithName(_runnerLoggerName).V(3).Info("Executing user runner...")
 
-			go userRunner(runner, kaiSDK)
+			go func() {
+				defer close(finished)
+				userRunner(runner, kaiSDK)
+			}()
+		} else {
+			close(finished)
 		}
 
-		// Handle sigterm and await termChan signal
-		termChan := make(chan os.Signal, 1)
-		signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
-		<-termChan
-
-		kaiSDK.Logger.WithName(_runnerLoggerName).V(3).Info("User runner executed")
+		// Handle sigterm or context cancellation
+		common.WaitForShutdown(runner.ctx)
 
 		// Handle shutdown
 		kaiSDK.Logger.WithName(_runnerLoggerName).Info("Shutting down runner...")
+		runner.health.SetReady(false)
+		runner.cancel()
+
+		gracePeriod := runner.getShutdownGracePeriod()
+
+		kaiSDK.Logger.WithName(_runnerLoggerName).V(1).
+			Info(fmt.Sprintf("Waiting up to %s for the user runner to finish", gracePeriod))
+
+		select {
+		case <-finished:
+			kaiSDK.Logger.WithName(_runnerLoggerName).V(3).Info("User runner executed")
+		case <-time.After(gracePeriod):
+			kaiSDK.Logger.WithName(_runnerLoggerName).Info("Shutdown grace period expired, stopping the requests in process")
+		}
+
+		close(runner.stopped)
+
 		kaiSDK.Logger.WithName(_runnerLoggerName).V(1).Info("Closing opened channels...")
+		runner.stopPendingRequests()
+		runner.closeStreams()
 		runner.responseChannels.Range(func(key, value interface{}) bool {
 			close(value.(chan *anypb.Any))
 			kaiSDK.Logger.WithName(_runnerLoggerName).V(1).Info(fmt.Sprintf("Channel closed for request id %s", key))
@@ -63,17 +83,26 @@ func composeRunner(userRunner RunnerFunc) RunnerFunc {
 		})
 
 		kaiSDK.Logger.WithName(_runnerLoggerName).Info("RunnerFunc shutdown")
-		wg.Done()
+		runner.wg.Done()
 	}
 }
 
-func getResponseHandler(handlers *sync.Map) ResponseHandler {
+func getResponseHandler(runner *Runner) ResponseHandler {
 	return func(kaiSDK sdk.KaiSDK, response *anypb.Any) error {
 		// Unmarshal response to a KaiNatsMessage type
 		kaiSDK.Logger.WithName(_responseHandlerLoggerName).
 			Info(fmt.Sprintf("Message received with request id %s", kaiSDK.GetRequestID()))
 
-		responseHandler, ok := handlers.LoadAndDelete(kaiSDK.GetRequestID())
+		result := requestResult{response: response}
+		if kaiSDK.Messaging.IsMessageError() {
+			result = requestResult{err: fmt.Errorf("%w: %s", ErrRequestFailed, kaiSDK.Messaging.GetErrorMessage())}
+		}
+
+		if runner.resolveRequest(kaiSDK.GetRequestID(), result) {
+			return nil
+		}
+
+		responseHandler, ok := runner.responseChannels.LoadAndDelete(kaiSDK.GetRequestID())
 
 		if ok {
 			responseHandler.(chan *anypb.Any) <- response
@@ -0,0 +1,79 @@
+package http
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	_defaultAddress     = ":8080"
+	_defaultTimeout     = 30 * time.Second
+	_defaultMaxBodySize = 4 * 1024 * 1024
+)
+
+// Option configures how New creates a Server.
+type Option func(*options)
+
+type options struct {
+	address     string
+	timeout     time.Duration
+	maxBodySize int64
+	types       *protoregistry.Types
+	logger      *logr.Logger
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		address:     _defaultAddress,
+		timeout:     _defaultTimeout,
+		maxBodySize: _defaultMaxBodySize,
+		types:       protoregistry.GlobalTypes,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithAddress sets the TCP address the server listens on, ":8080" by default.
+func WithAddress(address string) Option {
+	return func(o *options) {
+		o.address = address
+	}
+}
+
+// WithTimeout sets how long a request waits for the response of the workflow when its route does
+// not set its own timeout, 30 seconds by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithMaxBodySize sets the largest request body, in bytes, the server reads, 4 MiB by default.
+// Requests with a larger body are answered with 413 Request Entity Too Large.
+func WithMaxBodySize(size int64) Option {
+	return func(o *options) {
+		o.maxBodySize = size
+	}
+}
+
+// WithTypes sets the registry resolving the message types of the responses to convert them into
+// JSON. The global registry, holding every generated message linked into the binary, is used by
+// default.
+func WithTypes(types *protoregistry.Types) Option {
+	return func(o *options) {
+		o.types = types
+	}
+}
+
+// WithLogger sets the logger of the server. The logger of the trigger is used by default.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = &logger
+	}
+}
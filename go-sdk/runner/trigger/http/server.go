@@ -0,0 +1,261 @@
+// Package http provides a trigger serving a workflow through a JSON HTTP API. Every route converts
+// the JSON body of its requests into a protobuf message, sends it to the workflow and replies with
+// the response of the workflow converted back into JSON.
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+const (
+	// RequestIDHeader holds the request ID of the workflow request. It is taken from the HTTP
+	// request when present, so it must be unique, and is always set in the response.
+	RequestIDHeader = "X-Request-Id"
+
+	_serverLoggerName = "[HTTP]"
+)
+
+var ErrInvalidRoute = errors.New("invalid route")
+
+// Route maps the requests to a path into workflow requests.
+type Route struct {
+	// Method is the HTTP method of the route, POST by default.
+	Method string
+	// Path is the path of the route, following the patterns of http.ServeMux.
+	Path string
+	// Request is the message type the JSON body is converted into.
+	Request proto.Message
+	// Timeout overrides the timeout of the server for this route.
+	Timeout time.Duration
+}
+
+// Server serves the routes of a trigger.
+type Server struct {
+	routes  []Route
+	options *options
+}
+
+// New creates a Server serving the given routes.
+func New(routes []Route, opts ...Option) (*Server, error) {
+	for _, route := range routes {
+		if route.Path == "" || route.Request == nil {
+			return nil, fmt.Errorf("%w: path and request message are required, got %q", ErrInvalidRoute, route.Path)
+		}
+	}
+
+	return &Server{
+		routes:  routes,
+		options: newOptions(opts...),
+	}, nil
+}
+
+// RunnerFunc returns a trigger.RunnerFunc listening on the address of the server until the
+// trigger shuts down. It returns once the requests in process are answered, or the shutdown grace
+// period of the trigger expires. The trigger is stopped when the server cannot listen or serve.
+func (s *Server) RunnerFunc() trigger.RunnerFunc {
+	return func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		logger := s.getLogger(kaiSDK.Logger)
+
+		listener, err := net.Listen("tcp", s.options.address)
+		if err != nil {
+			logger.Error(err, fmt.Sprintf("Error listening on %s, stopping the trigger", s.options.address))
+			tr.Stop()
+
+			return
+		}
+
+		server := &http.Server{
+			Handler:           s.handler(tr, logger),
+			ReadHeaderTimeout: s.options.timeout,
+		}
+
+		stopped := make(chan struct{})
+
+		go func() {
+			defer close(stopped)
+
+			<-tr.Context().Done()
+
+			ctx, cancel := context.WithTimeout(context.Background(), tr.ShutdownGracePeriod())
+			defer cancel()
+
+			if err := server.Shutdown(ctx); err != nil {
+				logger.Error(err, "Error shutting down HTTP server")
+			}
+		}()
+
+		logger.Info(fmt.Sprintf("Listening on %s", listener.Addr()))
+
+		if err := server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			logger.Error(err, "Error serving HTTP requests, stopping the trigger")
+			tr.Stop()
+		}
+
+		// Serve returns as soon as the shutdown starts, so the requests in process are waited for
+		// here.
+		<-stopped
+	}
+}
+
+// Handler returns the handler of the routes, sending their requests through the given trigger,
+// which must be running.
+func (s *Server) Handler(tr *trigger.Runner) http.Handler {
+	return s.handler(tr, s.getLogger(logr.Discard()))
+}
+
+func (s *Server) handler(tr *trigger.Runner, logger logr.Logger) http.Handler {
+	mux := http.NewServeMux()
+
+	for _, route := range s.routes {
+		method := route.Method
+		if method == "" {
+			method = http.MethodPost
+		}
+
+		mux.Handle(fmt.Sprintf("%s %s", method, route.Path), s.routeHandler(tr, logger, route))
+	}
+
+	return mux
+}
+
+func (s *Server) routeHandler(tr *trigger.Runner, logger logr.Logger, route Route) http.HandlerFunc {
+	timeout := route.Timeout
+	if timeout == 0 {
+		timeout = s.options.timeout
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(RequestIDHeader)
+		if requestID == "" {
+			requestID = uuid.New().String()
+		}
+
+		w.Header().Set(RequestIDHeader, requestID)
+
+		requestLogger := logger.WithValues(sdk.LoggerRequestID, requestID)
+
+		r.Body = http.MaxBytesReader(w, r.Body, s.options.maxBodySize)
+
+		payload, err := readPayload(r, route.Request)
+		if err != nil {
+			writeError(w, readErrorStatusCode(err), err)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(tracing.ExtractHTTP(r.Context(), r.Header), timeout)
+		defer cancel()
+
+		response, err := tr.RequestWithID(ctx, requestID, payload)
+		if err != nil {
+			requestLogger.V(1).Info(fmt.Sprintf("Error processing request: %s", err))
+			writeError(w, statusCode(err), err)
+
+			return
+		}
+
+		body, err := s.marshalResponse(response)
+		if err != nil {
+			requestLogger.Error(err, "Error converting the response into JSON")
+			writeError(w, http.StatusInternalServerError, err)
+
+			return
+		}
+
+		if body == nil {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+
+		if _, err := w.Write(body); err != nil {
+			requestLogger.Error(err, "Error writing response")
+		}
+	}
+}
+
+func (s *Server) marshalResponse(response *anypb.Any) ([]byte, error) {
+	if response == nil {
+		return nil, nil
+	}
+
+	message, err := anypb.UnmarshalNew(response, proto.UnmarshalOptions{Resolver: s.options.types})
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling response of type %q: %w", response.GetTypeUrl(), err)
+	}
+
+	return protojson.Marshal(message)
+}
+
+func (s *Server) getLogger(defaultLogger logr.Logger) logr.Logger {
+	if s.options.logger != nil {
+		return *s.options.logger
+	}
+
+	return defaultLogger.WithName(_serverLoggerName)
+}
+
+func readPayload(r *http.Request, request proto.Message) (proto.Message, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading request body: %w", err)
+	}
+
+	payload := request.ProtoReflect().New().Interface()
+	if len(body) == 0 {
+		return payload, nil
+	}
+
+	if err := protojson.Unmarshal(body, payload); err != nil {
+		return nil, fmt.Errorf("error converting request body into %s: %w", request.ProtoReflect().Descriptor().FullName(), err)
+	}
+
+	return payload, nil
+}
+
+func readErrorStatusCode(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return http.StatusRequestEntityTooLarge
+	}
+
+	return http.StatusBadRequest
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, trigger.ErrRequestFailed):
+		return http.StatusInternalServerError
+	case errors.Is(err, trigger.ErrDuplicateRequestID):
+		return http.StatusConflict
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	body, _ := json.Marshal(map[string]string{"error": err.Error()})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,329 @@
+//go:build unit
+
+package http_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	triggerHTTP "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger/http"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type ServerTestSuite struct {
+	suite.Suite
+	logger logr.Logger
+	sim    *localtest.Simulator
+	server *httptest.Server
+}
+
+func (s *ServerTestSuite) SetupTest() {
+	s.logger = testr.NewWithOptions(s.T(), testr.Options{Verbosity: 1})
+
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	s.sim.ForwardExit()
+}
+
+func (s *ServerTestSuite) TearDownTest() {
+	if s.server != nil {
+		s.server.Close()
+		s.server = nil
+	}
+}
+
+func (s *ServerTestSuite) TestPost_ExpectJSONResponse() {
+	// Given
+	s.setupTask(task.TypedHandler(func(kaiSDK sdk.KaiSDK, value *wrapperspb.StringValue) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(strings.ToUpper(value.GetValue())))
+	}))
+	s.startServer(triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	request, err := http.NewRequest(http.MethodPost, s.server.URL+"/upper", strings.NewReader(`"hello"`))
+	s.Require().NoError(err)
+	request.Header.Set(triggerHTTP.RequestIDHeader, "some-request-id")
+
+	// When
+	response, err := http.DefaultClient.Do(request)
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	body, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Equal("some-request-id", response.Header.Get(triggerHTTP.RequestIDHeader))
+	s.JSONEq(`"HELLO"`, string(body))
+}
+
+func (s *ServerTestSuite) TestPost_StructRequest_ExpectRequestIDGenerated() {
+	// Given
+	s.setupTask(task.TypedHandler(func(kaiSDK sdk.KaiSDK, value *structpb.Struct) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(value.GetFields()["name"].GetStringValue()))
+	}))
+	s.startServer(triggerHTTP.Route{Path: "/name", Request: &structpb.Struct{}})
+
+	// When
+	response, err := http.Post(s.server.URL+"/name", "application/json", strings.NewReader(`{"name": "kai"}`))
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	body, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.NotEmpty(response.Header.Get(triggerHTTP.RequestIDHeader))
+	s.JSONEq(`"kai"`, string(body))
+}
+
+func (s *ServerTestSuite) TestPost_InvalidBody_ExpectBadRequest() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+	s.startServer(triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	// When
+	response, err := http.Post(s.server.URL+"/upper", "application/json", strings.NewReader(`{"not": "a string"}`))
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	s.Equal(http.StatusBadRequest, response.StatusCode)
+	s.NotEmpty(s.errorMessage(response))
+}
+
+func (s *ServerTestSuite) TestPost_ErrorResponse_ExpectInternalServerError() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return errors.New("invalid request")
+	})
+	s.startServer(triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	// When
+	response, err := http.Post(s.server.URL+"/upper", "application/json", strings.NewReader(`"hello"`))
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	s.Equal(http.StatusInternalServerError, response.StatusCode)
+	s.Contains(s.errorMessage(response), "invalid request")
+}
+
+func (s *ServerTestSuite) TestPost_NoResponse_ExpectGatewayTimeout() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+	s.startServer(triggerHTTP.Route{
+		Path:    "/upper",
+		Request: &wrapperspb.StringValue{},
+		Timeout: 100 * time.Millisecond,
+	})
+
+	// When
+	response, err := http.Post(s.server.URL+"/upper", "application/json", strings.NewReader(`"hello"`))
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	s.Equal(http.StatusGatewayTimeout, response.StatusCode)
+}
+
+func (s *ServerTestSuite) TestPost_RequestIDInProcess_ExpectConflict() {
+	// Given
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	s.setupTask(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		started <- struct{}{}
+		<-release
+
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+	s.startServer(triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	post := func() (*http.Response, error) {
+		request, err := http.NewRequest(http.MethodPost, s.server.URL+"/upper", strings.NewReader(`"hello"`))
+		if err != nil {
+			return nil, err
+		}
+
+		request.Header.Set(triggerHTTP.RequestIDHeader, "some-request-id")
+
+		return http.DefaultClient.Do(request)
+	}
+
+	firstStatuses := make(chan int, 1)
+
+	go func() {
+		response, err := post()
+		if err != nil {
+			firstStatuses <- 0
+			return
+		}
+
+		response.Body.Close()
+		firstStatuses <- response.StatusCode
+	}()
+
+	<-started
+
+	// When
+	response, err := post()
+	close(release)
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	s.Equal(http.StatusConflict, response.StatusCode)
+	s.Equal(http.StatusOK, <-firstStatuses)
+}
+
+func (s *ServerTestSuite) TestGet_UnknownRouteAndMethod_ExpectNotFoundAndMethodNotAllowed() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+	s.startServer(triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	// When
+	notFound, err := http.Get(s.server.URL + "/unknown")
+	s.Require().NoError(err)
+	notFound.Body.Close()
+
+	notAllowed, err := http.Get(s.server.URL + "/upper")
+	s.Require().NoError(err)
+	notAllowed.Body.Close()
+
+	// Then
+	s.Equal(http.StatusNotFound, notFound.StatusCode)
+	s.Equal(http.StatusMethodNotAllowed, notAllowed.StatusCode)
+}
+
+func (s *ServerTestSuite) TestPost_BodyTooLarge_ExpectRequestEntityTooLarge() {
+	// Given
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+	s.startServerWithOptions([]triggerHTTP.Option{triggerHTTP.WithMaxBodySize(16)},
+		triggerHTTP.Route{Path: "/upper", Request: &wrapperspb.StringValue{}})
+
+	// When
+	response, err := http.Post(s.server.URL+"/upper", "application/json",
+		strings.NewReader(`"a string longer than sixteen bytes"`))
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	// Then
+	s.Equal(http.StatusRequestEntityTooLarge, response.StatusCode)
+	s.NotEmpty(s.errorMessage(response))
+}
+
+func (s *ServerTestSuite) TestRunnerFunc_AddressInUse_ExpectTriggerStopped() {
+	// Given
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	defer listener.Close()
+
+	server, err := triggerHTTP.New([]triggerHTTP.Route{{Path: "/upper", Request: &wrapperspb.StringValue{}}},
+		triggerHTTP.WithAddress(listener.Addr().String()), triggerHTTP.WithLogger(s.logger))
+	s.Require().NoError(err)
+
+	s.setupTask(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+
+	runners := make(chan *trigger.Runner, 1)
+	runnerFunc := server.RunnerFunc()
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, kaiSDK sdk.KaiSDK) {
+		runners <- tr
+		runnerFunc(tr, kaiSDK)
+	})
+
+	// When
+	s.sim.Start()
+
+	// Then
+	select {
+	case tr := <-runners:
+		s.Eventually(func() bool { return tr.Context().Err() != nil }, localtest.Timeout/2, 10*time.Millisecond)
+	case <-time.After(localtest.Timeout):
+		s.Fail("trigger not started")
+	}
+}
+
+func (s *ServerTestSuite) TestNew_InvalidRoute_ExpectError() {
+	// When
+	_, err := triggerHTTP.New([]triggerHTTP.Route{{Path: "/upper"}})
+
+	// Then
+	s.ErrorIs(err, triggerHTTP.ErrInvalidRoute)
+}
+
+func (s *ServerTestSuite) setupTask(handler task.Handler) {
+	s.sim.TaskRunner("transformer").WithHandler(handler)
+}
+
+// startServer runs the simulator and serves the given routes through its trigger.
+func (s *ServerTestSuite) startServer(routes ...triggerHTTP.Route) {
+	s.startServerWithOptions(nil, routes...)
+}
+
+func (s *ServerTestSuite) startServerWithOptions(opts []triggerHTTP.Option, routes ...triggerHTTP.Route) {
+	server, err := triggerHTTP.New(routes, append(opts, triggerHTTP.WithLogger(s.logger))...)
+	s.Require().NoError(err)
+
+	handlers := make(chan http.Handler, 1)
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		handlers <- server.Handler(tr)
+	})
+
+	s.sim.Start()
+
+	select {
+	case handler := <-handlers:
+		s.server = httptest.NewServer(handler)
+	case <-time.After(localtest.Timeout):
+		s.FailNow("trigger not started")
+	}
+}
+
+func (s *ServerTestSuite) errorMessage(response *http.Response) string {
+	body := map[string]string{}
+	s.Require().NoError(json.NewDecoder(response.Body).Decode(&body))
+
+	return body["error"]
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
@@ -0,0 +1,146 @@
+package trigger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/metric"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	runnerCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
+)
+
+var (
+	ErrRequestFailed      = errors.New("request failed")
+	ErrRunnerNotRunning   = errors.New("trigger runner is not running")
+	ErrRunnerStopped      = errors.New("trigger runner stopped before the response was received")
+	ErrDuplicateRequestID = errors.New("a request with the same ID is already waiting for its response")
+)
+
+type requestResult struct {
+	response *anypb.Any
+	err      error
+}
+
+// Request sends the payload to the output subject with a new request ID and waits until the
+// response of the workflow to that request is received, the given context is done or the runner
+// stops, failing with ErrRunnerStopped. An error response is returned as an error wrapping
+// ErrRequestFailed. The deadline of the context is propagated to every process of the workflow,
+// and payloads that are already an Any are sent as they are.
+//
+// It must be called while the runner is running, usually from its RunnerFunc. Requests in process
+// when the runner starts shutting down still get their response until its shutdown grace period
+// expires. A response arriving once the request has timed out is discarded.
+func (tr *Runner) Request(ctx context.Context, payload proto.Message) (*anypb.Any, error) {
+	return tr.RequestWithID(ctx, uuid.New().String(), payload)
+}
+
+// RequestWithID works like Request, with the given request ID instead of a new one. Request IDs
+// must be unique among the pending requests: while a request waits for its response, another one
+// with the same ID fails with ErrDuplicateRequestID without being sent.
+func (tr *Runner) RequestWithID(ctx context.Context, requestID string, payload proto.Message) (*anypb.Any, error) {
+	ctx, span := runnerCommon.StartRequestSpan(ctx, tr.sdk, requestID)
+	defer span.End()
+
+	response, err := tr.request(ctx, requestID, payload)
+	runnerCommon.RecordError(span, err)
+
+	return response, err
+}
+
+func (tr *Runner) request(ctx context.Context, requestID string, payload proto.Message) (*anypb.Any, error) {
+	if tr.ctx == nil {
+		return nil, ErrRunnerNotRunning
+	}
+
+	select {
+	case <-tr.stopped:
+		return nil, ErrRunnerStopped
+	default:
+	}
+
+	result := make(chan requestResult, 1)
+
+	if _, loaded := tr.pendingRequests.LoadOrStore(requestID, result); loaded {
+		return nil, fmt.Errorf("error sending request %s: %w", requestID, ErrDuplicateRequestID)
+	}
+
+	attributes := metric.WithAttributeSet(tr.getProcessMetricAttributes())
+
+	tr.pendingRequestsMetric.Add(ctx, 1, attributes)
+
+	defer func() {
+		// Once resolved, the ID may already belong to a new request, which must be kept.
+		tr.pendingRequests.CompareAndDelete(requestID, result)
+		tr.pendingRequestsMetric.Add(context.Background(), -1, attributes)
+	}()
+
+	// The deadline and the trace of the context, if any, are sent along with the request to every process.
+	messaging := tr.sdk.WithContext(ctx).Messaging
+
+	var err error
+	if anyPayload, ok := payload.(*anypb.Any); ok {
+		err = messaging.SendAnyWithRequestID(anyPayload, requestID)
+	} else {
+		err = messaging.SendOutputWithRequestID(payload, requestID)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error sending request %s: %w", requestID, err)
+	}
+
+	select {
+	case res := <-result:
+		return res.response, res.err
+	case <-ctx.Done():
+		tr.timedOutRequestsMetric.Add(context.Background(), 1, attributes)
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Request %s timed out waiting for its response", requestID))
+
+		return nil, fmt.Errorf("error waiting for the response of request %s: %w", requestID, ctx.Err())
+	case <-tr.stopped:
+		return nil, ErrRunnerStopped
+	}
+}
+
+// resolveRequest hands the response over to the pending request with the given ID, if any.
+func (tr *Runner) resolveRequest(requestID string, result requestResult) bool {
+	pending, ok := tr.pendingRequests.LoadAndDelete(requestID)
+	if !ok {
+		return false
+	}
+
+	// The channel is buffered and only the one removing the request writes to it, so it never blocks.
+	pending.(chan requestResult) <- result
+
+	return true
+}
+
+// stopPendingRequests fails every pending request with ErrRunnerStopped.
+func (tr *Runner) stopPendingRequests() {
+	tr.pendingRequests.Range(func(key, _ any) bool {
+		tr.resolveRequest(key.(string), requestResult{err: ErrRunnerStopped})
+		return true
+	})
+}
+
+func (tr *Runner) initializeRequestMetrics() error {
+	var err error
+
+	tr.pendingRequestsMetric, err = tr.sdk.Measurements.GetMetricsClient().Int64UpDownCounter(
+		"runner-pending-requests-metric",
+		metric.WithDescription("How many requests are waiting for their response."),
+	)
+	if err != nil {
+		return err
+	}
+
+	tr.timedOutRequestsMetric, err = tr.sdk.Measurements.GetMetricsClient().Int64Counter(
+		"runner-timed-out-requests-metric",
+		metric.WithDescription("How many requests were done before their response was received."),
+	)
+
+	return err
+}
@@ -0,0 +1,119 @@
+//go:build unit
+
+package trigger_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/task"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type RequestTestSuite struct {
+	suite.Suite
+	sim *localtest.Simulator
+}
+
+func (s *RequestTestSuite) SetupTest() {
+	s.sim = localtest.New(s.T(), localtest.Pipeline(s.T(), ""))
+	s.sim.ForwardExit()
+}
+
+func (s *RequestTestSuite) TestRequest_ExpectCorrelatedResponse() {
+	// Given
+	s.sim.TaskRunner("transformer").WithHandler(task.TypedHandler(func(kaiSDK sdk.KaiSDK, value *wrapperspb.StringValue) error {
+		return kaiSDK.Messaging.SendOutput(wrapperspb.String(strings.ToUpper(value.GetValue())))
+	}))
+
+	// When
+	response, err := s.sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().NoError(err)
+
+	value := &wrapperspb.StringValue{}
+	s.Require().NoError(response.UnmarshalTo(value))
+	s.Equal("HELLO", value.GetValue())
+}
+
+func (s *RequestTestSuite) TestRequest_ErrorResponse_ExpectRequestFailed() {
+	// Given
+	s.sim.TaskRunner("transformer").WithHandler(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return errors.New("invalid request")
+	})
+
+	// When
+	_, err := s.sim.Request(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.ErrorIs(err, trigger.ErrRequestFailed)
+	s.Contains(err.Error(), "invalid request")
+}
+
+func (s *RequestTestSuite) TestRequest_NoResponse_ExpectDeadlineExceeded() {
+	// Given
+	s.sim.TaskRunner("transformer").WithHandler(func(_ sdk.KaiSDK, _ *anypb.Any) error {
+		return nil
+	})
+
+	// When
+	_, err := s.sim.Request(wrapperspb.String("hello"), 100*time.Millisecond)
+
+	// Then
+	s.ErrorIs(err, context.DeadlineExceeded)
+}
+
+func (s *RequestTestSuite) TestRequestWithID_SameIDPending_ExpectDuplicateRequestID() {
+	// Given
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	s.sim.TaskRunner("transformer").WithHandler(func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		started <- struct{}{}
+		<-release
+
+		return kaiSDK.Messaging.SendAny(payload)
+	})
+
+	firstResponses := make(chan *anypb.Any, 1)
+	secondErrors := make(chan error, 1)
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		go func() {
+			response, _ := tr.RequestWithID(context.Background(), "some-request-id", wrapperspb.String("first"))
+			firstResponses <- response
+		}()
+
+		<-started
+
+		_, err := tr.RequestWithID(context.Background(), "some-request-id", wrapperspb.String("second"))
+		secondErrors <- err
+
+		close(release)
+	})
+
+	// When
+	stop := s.sim.Start()
+	defer stop()
+
+	// Then
+	s.ErrorIs(<-secondErrors, trigger.ErrDuplicateRequestID)
+
+	value := &wrapperspb.StringValue{}
+	s.Require().NoError((<-firstResponses).UnmarshalTo(value))
+	s.Equal("first", value.GetValue())
+}
+
+func TestRequestTestSuite(t *testing.T) {
+	suite.Run(t, new(RequestTestSuite))
+}
@@ -0,0 +1,146 @@
+package trigger
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+)
+
+// StreamMessage is a message received for a streamed request, together with the channel it was
+// sent to ("" for the default output) and the node that sent it. Error messages have a nil
+// payload and an error wrapping ErrRequestFailed.
+type StreamMessage struct {
+	Channel  string
+	FromNode string
+	Payload  *anypb.Any
+	Err      error
+}
+
+type stream struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	closed   bool
+	messages chan StreamMessage
+}
+
+// Stream returns a channel receiving every message of the workflow with the given request ID, in
+// the order they are received through each subscription of the trigger. The channel is closed once
+// an end-of-stream marker is received, sent with SendEndOfStream, the given context is done, the
+// returned cancel function is called or the runner stops. Messages are not passed to the response
+// handler of the runner while the stream is open.
+//
+// The stream must be opened before sending the request, and read until it is closed, since a
+// message waits for the previous one to be read.
+func (tr *Runner) Stream(ctx context.Context, requestID string) (<-chan StreamMessage, context.CancelFunc) {
+	streamCtx, cancel := context.WithCancel(ctx)
+
+	s := &stream{
+		ctx:      streamCtx,
+		cancel:   cancel,
+		messages: make(chan StreamMessage),
+	}
+
+	if previous, loaded := tr.streams.Swap(requestID, s); loaded {
+		previous.(*stream).close()
+	}
+
+	context.AfterFunc(streamCtx, func() {
+		tr.streams.CompareAndDelete(requestID, s)
+		s.close()
+	})
+
+	return s.messages, cancel
+}
+
+// deliverToStream hands the message over to the stream of its request, if any, and tells whether
+// it was.
+func (tr *Runner) deliverToStream(msg *nats.Msg, requestMsg *kai.KaiNatsMessage) bool {
+	value, ok := tr.streams.Load(requestMsg.GetRequestId())
+	if !ok {
+		return false
+	}
+
+	s := value.(*stream)
+
+	if msg.Header.Get(common.EndOfStreamHeader) != "" {
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("End of stream received for request id %s", requestMsg.GetRequestId()))
+		s.cancel()
+
+		return true
+	}
+
+	message := StreamMessage{
+		Channel:  getChannel(msg.Subject, requestMsg.GetFromNode()),
+		FromNode: requestMsg.GetFromNode(),
+		Payload:  requestMsg.GetPayload(),
+	}
+
+	if requestMsg.GetMessageType() == kai.MessageType_ERROR {
+		message.Payload = nil
+		message.Err = fmt.Errorf("%w: %s", ErrRequestFailed, requestMsg.GetError())
+	}
+
+	s.deliver(message)
+
+	return true
+}
+
+// closeStreams closes every open stream.
+func (tr *Runner) closeStreams() {
+	tr.streams.Range(func(key, value any) bool {
+		tr.streams.Delete(key)
+		value.(*stream).close()
+
+		return true
+	})
+}
+
+func (s *stream) deliver(message StreamMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.messages <- message:
+	case <-s.ctx.Done():
+	}
+}
+
+func (s *stream) close() {
+	// Cancelling first releases a deliver blocked on a reader that is gone.
+	s.cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.messages)
+	}
+}
+
+// getChannel returns the channel a message with the given subject was sent to, or "" for the
+// default output of the node. The subject is the output subject of the node, which ends with its
+// name and may have any number of tokens before it, followed by the channel if any.
+func getChannel(subject, fromNode string) string {
+	tokens := strings.Split(subject, ".")
+
+	for i := len(tokens) - 1; i >= 0; i-- {
+		if tokens[i] == fromNode {
+			return strings.Join(tokens[i+1:], ".")
+		}
+	}
+
+	return ""
+}
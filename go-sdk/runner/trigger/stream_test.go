@@ -0,0 +1,176 @@
+//go:build unit
+
+package trigger_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/local/localtest"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/trigger"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type StreamTestSuite struct {
+	suite.Suite
+	sim *localtest.Simulator
+}
+
+// SetupTest creates the simulator of a workflow whose trigger subscribes to the results channel of the exit.
+func (s *StreamTestSuite) SetupTest() {
+	s.sim = localtest.New(s.T(), localtest.Workflow(s.T(), "",
+		localtest.Trigger("entrypoint", "exit.results"),
+		localtest.Exit("exit", "entrypoint"),
+	))
+}
+
+func (s *StreamTestSuite) TestStream_ExpectMessagesInOrderUntilEndOfStream() {
+	// Given
+	s.setupExit(true)
+
+	// When
+	messages := s.sim.Stream(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().Len(messages, 3)
+
+	for i, want := range []string{"FIRST", "SECOND", "DONE"} {
+		s.NoError(messages[i].Err)
+		s.Equal("results", messages[i].Channel)
+		s.Equal("exit", messages[i].FromNode)
+
+		value := &wrapperspb.StringValue{}
+		s.Require().NoError(messages[i].Payload.UnmarshalTo(value))
+		s.Equal(want, value.GetValue())
+	}
+}
+
+func (s *StreamTestSuite) TestStream_AsyncOutputs_ExpectMessagesInOrderUntilEndOfStream() {
+	// Given
+	s.sim.ExitRunner("exit").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		for _, value := range []string{"FIRST", "SECOND", "DONE"} {
+			if _, err := kaiSDK.Messaging.SendOutputAsync(wrapperspb.String(value), "results"); err != nil {
+				return err
+			}
+		}
+
+		if err := kaiSDK.Messaging.Flush(kaiSDK.Context()); err != nil {
+			return err
+		}
+
+		return kaiSDK.Messaging.SendEndOfStream("results")
+	})
+
+	// When
+	messages := s.sim.Stream(wrapperspb.String("hello"), localtest.Timeout)
+
+	// Then
+	s.Require().Len(messages, 3)
+
+	for i, want := range []string{"FIRST", "SECOND", "DONE"} {
+		s.NoError(messages[i].Err)
+
+		value := &wrapperspb.StringValue{}
+		s.Require().NoError(messages[i].Payload.UnmarshalTo(value))
+		s.Equal(want, value.GetValue())
+	}
+}
+
+func (s *StreamTestSuite) TestStream_NoEndOfStream_ExpectClosedOnTimeout() {
+	// Given
+	s.setupExit(false)
+
+	// When
+	messages := s.sim.Stream(wrapperspb.String("hello"), 500*time.Millisecond)
+
+	// Then
+	s.Len(messages, 3)
+}
+
+func (s *StreamTestSuite) TestStream_OutputSubjectWithSeveralTokens_ExpectChannelAfterOutputSubject() {
+	// Given
+	outputSubject := localtest.Subject("kai.exit")
+
+	s.sim = localtest.New(s.T(), localtest.Workflow(s.T(),
+		fmt.Sprintf("nats: {inputs: [%s, %s.results]}", outputSubject, outputSubject),
+		localtest.Trigger("entrypoint"),
+	))
+
+	payload, err := anypb.New(wrapperspb.String("hello"))
+	s.Require().NoError(err)
+
+	js := s.sim.JetStream()
+	channels := make(chan []string, 1)
+
+	s.sim.TriggerRunner("entrypoint").WithRunner(func(tr *trigger.Runner, _ sdk.KaiSDK) {
+		ctx, cancel := context.WithTimeout(context.Background(), localtest.Timeout)
+		defer cancel()
+
+		requestID := uuid.New().String()
+		stream, stop := tr.Stream(ctx, requestID)
+
+		defer stop()
+
+		data, err := proto.Marshal(&kai.KaiNatsMessage{RequestId: requestID, Payload: payload, FromNode: "exit"})
+		if !s.NoError(err) {
+			return
+		}
+
+		for _, subject := range []string{outputSubject + ".results", outputSubject} {
+			if _, err = js.Publish(subject, data); !s.NoError(err) {
+				return
+			}
+		}
+
+		received := make([]string, 0, 2)
+		for message := range stream {
+			received = append(received, message.Channel)
+			if len(received) == 2 {
+				break
+			}
+		}
+
+		channels <- received
+	})
+
+	// When
+	s.sim.Start()
+
+	// Then
+	select {
+	case received := <-channels:
+		s.ElementsMatch([]string{"results", ""}, received)
+	case <-time.After(localtest.Timeout):
+		s.Fail("messages not received")
+	}
+}
+
+// setupExit registers an exit sending three results, followed by an end-of-stream marker if asked to.
+func (s *StreamTestSuite) setupExit(endOfStream bool) {
+	s.sim.ExitRunner("exit").WithHandler(func(kaiSDK sdk.KaiSDK, _ *anypb.Any) error {
+		for _, value := range []string{"FIRST", "SECOND", "DONE"} {
+			if err := kaiSDK.Messaging.SendOutput(wrapperspb.String(value), "results"); err != nil {
+				return err
+			}
+		}
+
+		if endOfStream {
+			return kaiSDK.Messaging.SendEndOfStream("results")
+		}
+
+		return nil
+	})
+}
+
+func TestStreamTestSuite(t *testing.T) {
+	suite.Run(t, new(StreamTestSuite))
+}
@@ -4,9 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"os/signal"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -22,17 +20,28 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	runnerCommon "github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
 )
 
-const _subscriberLoggerName = "[SUBSCRIBER]"
+const (
+	_subscriberLoggerName = "[SUBSCRIBER]"
+	// _staleConsumerInactivity is how long the consumers of previous runs must have been inactive
+	// to be removed when the inactive threshold is disabled.
+	_staleConsumerInactivity = time.Hour
+)
 
 func (tr *Runner) getLoggerWithName() logr.Logger {
 	return tr.sdk.Logger.WithName(_subscriberLoggerName)
 }
 
-func (tr *Runner) startSubscriber() {
-	inputSubjects := viper.GetStringSlice(common.ConfigNatsInputsKey)
+func (tr *Runner) getConfig() *viper.Viper {
+	return common.ConfigOrGlobal(tr.config)
+}
+
+func (tr *Runner) startSubscriber(subscribed chan<- struct{}) {
+	inputSubjects := tr.getConfig().GetStringSlice(common.ConfigNatsInputsKey)
 
 	var err error
 
@@ -46,25 +55,33 @@ func (tr *Runner) startSubscriber() {
 		os.Exit(1)
 	}
 
+	tr.panicsMetric, err = tr.sdk.Measurements.GetMetricsClient().Int64Counter(
+		"runner-handler-panics-metric",
+		metric.WithDescription("How many panics were recovered while processing messages."),
+	)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, "Error initializing metric")
+		os.Exit(1)
+	}
+
 	subscriptions := make([]*nats.Subscription, 0, len(inputSubjects))
 
 	for _, subject := range inputSubjects {
 		consumerName := fmt.Sprintf("%s-%s", strings.ReplaceAll(subject, ".", "-"),
 			strings.ReplaceAll(strings.ReplaceAll(tr.sdk.Metadata.GetProcess(), ".", "-"), " ", "-"))
 
+		tr.removeStaleConsumers(consumerName)
+
 		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Subscribing to subject %s with queue group %s", subject, consumerName))
 
 		s, err := tr.jetstream.Subscribe(
 			subject,
 			tr.processMessage,
-			nats.DeliverNew(),
-			nats.Durable(fmt.Sprintf("%s-%s", consumerName, uuid.New().String())),
-			nats.ManualAck(),
-			nats.AckWait(viper.GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
+			tr.getSubscribeOptions(consumerName)...,
 		)
 		if err != nil {
 			tr.getLoggerWithName().Error(err, fmt.Sprintf("Error subscribing to subject %s", subject))
-			wg.Done()
+			tr.wg.Done()
 			os.Exit(1)
 		}
 
@@ -74,14 +91,14 @@ func (tr *Runner) startSubscriber() {
 	}
 
 	tr.getLoggerWithName().V(1).Info("Subscribed to all subjects successfully")
+	close(subscribed)
 
-	// Handle sigterm and await termChan signal
-	termChan := make(chan os.Signal, 1)
-	signal.Notify(termChan, syscall.SIGINT, syscall.SIGTERM)
-	<-termChan
+	// The subscriptions are kept until the runner stops, so the requests in process during the
+	// shutdown grace period still get their responses.
+	<-tr.stopped
 
 	// Handle shutdown
-	tr.getLoggerWithName().Info("Shutdown signal received")
+	tr.getLoggerWithName().Info("Runner stopped")
 
 	tr.getLoggerWithName().V(1).Info("Unsubscribing from all subjects")
 
@@ -91,22 +108,86 @@ func (tr *Runner) startSubscriber() {
 		err := s.Unsubscribe()
 		if err != nil {
 			tr.getLoggerWithName().Error(err, fmt.Sprintf("Error unsubscribing from the subject %s", s.Subject))
-			wg.Done()
-			os.Exit(1)
 		}
 	}
 
 	tr.getLoggerWithName().Info("Unsubscribed from all subjects")
-	wg.Done()
+	tr.wg.Done()
+}
+
+func (tr *Runner) getShutdownGracePeriod() time.Duration {
+	if tr.gracePeriod > 0 {
+		return tr.gracePeriod
+	}
+
+	return tr.getConfig().GetDuration(common.ConfigRunnerShutdownGracePeriodKey)
+}
+
+// getSubscribeOptions returns the options of a new durable consumer. Each subscription gets a
+// consumer of its own, which the server removes once it has been inactive for the configured
+// threshold, so consumers of processes that did not shut down cleanly do not pile up.
+func (tr *Runner) getSubscribeOptions(consumerName string) []nats.SubOpt {
+	opts := []nats.SubOpt{
+		nats.DeliverNew(),
+		nats.Durable(fmt.Sprintf("%s-%s", consumerName, uuid.New().String())),
+		nats.ManualAck(),
+		nats.AckWait(tr.getConfig().GetDuration(common.ConfigRunnerSubscriberAckWaitTimeKey)),
+	}
+
+	if threshold := tr.getConfig().GetDuration(common.ConfigRunnerSubscriberInactiveThresholdKey); threshold > 0 {
+		opts = append(opts, nats.InactiveThreshold(threshold))
+	}
+
+	return opts
+}
+
+// removeStaleConsumers removes the consumers of previous runs of the process no subscriber is
+// bound to anymore and that have been inactive for the inactive threshold, or an hour when it is
+// disabled, so those of replicas that are only reconnecting are kept. Errors are logged, since they
+// do not prevent the runner from subscribing.
+func (tr *Runner) removeStaleConsumers(consumerName string) {
+	stream := tr.getConfig().GetString(common.ConfigNatsStreamKey)
+
+	inactiveFor := tr.getConfig().GetDuration(common.ConfigRunnerSubscriberInactiveThresholdKey)
+	if inactiveFor <= 0 {
+		inactiveFor = _staleConsumerInactivity
+	}
+
+	removed, err := removeOrphanedConsumers(tr.jetstream, stream, consumerName+"-", inactiveFor)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, fmt.Sprintf("Error removing stale consumers %s", consumerName))
+	}
+
+	for _, name := range removed {
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Removed stale consumer %s", name))
+	}
 }
 
 func (tr *Runner) processMessage(msg *nats.Msg) {
 	tr.getLoggerWithName().V(1).Info("New message received")
 
-	requestMsg, err := tr.newRequestMessage(msg.Data)
+	var requestMsg *kai.KaiNatsMessage
+
+	defer runnerCommon.RecoverPanic(tr.getLoggerWithName(), func(err error) {
+		tr.panicsMetric.Add(context.Background(), 1, metric.WithAttributeSet(tr.getMetricAttributes(requestMsg.GetRequestId())))
+
+		errMsg := fmt.Sprintf("Error in node %q processing message from subject %s: %s",
+			tr.sdk.Metadata.GetProcess(), msg.Subject, err)
+		tr.processRunnerError(msg, errMsg, requestMsg.GetRequestId())
+	})
+
+	requestMsg, err := tr.newRequestMessage(msg)
 	if err != nil {
 		errMsg := fmt.Sprintf("Error parsing msg.data coming from subject %s because is not a valid protobuf: %s", msg.Subject, err)
-		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
+		tr.processRunnerError(msg, errMsg, requestMsg.GetRequestId())
+
+		return
+	}
+
+	requestMsg, err = runnerCommon.ResolveClaimCheck(tr.jetstream, tr.getConfig(), msg, requestMsg)
+	if err != nil {
+		errMsg := fmt.Sprintf("Error resolving the claim check of the message from subject %s: %s", msg.Subject, err)
+		tr.processRunnerError(msg, errMsg, requestMsg.GetRequestId())
 
 		return
 	}
@@ -124,6 +205,11 @@ func (tr *Runner) processMessage(msg *nats.Msg) {
 	tr.getLoggerWithName().Info(fmt.Sprintf("New message received with subject %s",
 		msg.Subject))
 
+	if tr.deliverToStream(msg, requestMsg) {
+		tr.ackMessage(msg)
+		return
+	}
+
 	if tr.responseHandler == nil {
 		errMsg := fmt.Sprintf("Error missing handler for node %q", requestMsg.FromNode)
 		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
@@ -131,11 +217,19 @@ func (tr *Runner) processMessage(msg *nats.Msg) {
 		return
 	}
 
+	ctx, span := runnerCommon.StartMessageSpan(context.Background(), tr.sdk, msg, requestMsg)
+	defer span.End()
+
 	// Make a shallow copy of the sdk object to set inside the request msg.
 	hSdk := sdk.ShallowCopyWithRequest(&tr.sdk, requestMsg)
+	hSdk = hSdk.WithContext(ctx)
+	hSdk = hSdk.WithHandledMsgID(runnerCommon.HandledMsgID(msg))
 
-	err = tr.responseHandler(hSdk, requestMsg.Payload)
+	err = runnerCommon.TracePhase(hSdk, runnerCommon.HandlerSpanName,
+		runnerCommon.Handler(tr.responseHandler), requestMsg.Payload)
 	if err != nil {
+		runnerCommon.RecordError(span, err)
+
 		errMsg := fmt.Sprintf("Error in node %q executing handler for node %q: %s",
 			tr.sdk.Metadata.GetProcess(), requestMsg.FromNode, err)
 		tr.processRunnerError(msg, errMsg, requestMsg.RequestId)
@@ -143,7 +237,11 @@ func (tr *Runner) processMessage(msg *nats.Msg) {
 		return
 	}
 
-	// Tell NATS we don't need to receive the message anymore, and we are done processing it.
+	tr.ackMessage(msg)
+}
+
+// ackMessage tells NATS we don't need to receive the message anymore, and we are done processing it.
+func (tr *Runner) ackMessage(msg *nats.Msg) {
 	ackErr := msg.Ack()
 	if ackErr != nil {
 		tr.getLoggerWithName().Error(ackErr, errors.ErrMsgAck)
@@ -160,16 +258,13 @@ func (tr *Runner) processRunnerError(msg *nats.Msg, errMsg, requestID string) {
 	tr.publishError(requestID, errMsg)
 }
 
-func (tr *Runner) newRequestMessage(data []byte) (*kai.KaiNatsMessage, error) {
+func (tr *Runner) newRequestMessage(msg *nats.Msg) (*kai.KaiNatsMessage, error) {
 	requestMsg := &kai.KaiNatsMessage{}
 
-	var err error
-	if common.IsCompressed(data) {
-		data, err = common.UncompressData(data)
-		if err != nil {
-			tr.getLoggerWithName().Error(err, "Error reading compressed message")
-			return nil, err
-		}
+	data, err := compression.Decompress(msg.Header, msg.Data)
+	if err != nil {
+		tr.getLoggerWithName().Error(err, "Error reading compressed message")
+		return nil, err
 	}
 
 	err = proto.Unmarshal(data, requestMsg)
@@ -181,7 +276,7 @@ func (tr *Runner) publishError(requestID, errMsg string) {
 	responseMsg := &kai.KaiNatsMessage{
 		RequestId:   requestID,
 		Error:       errMsg,
-		FromNode:    viper.GetString(common.ConfigMetadataProcessIDKey),
+		FromNode:    tr.getConfig().GetString(common.ConfigMetadataProcessIDKey),
 		MessageType: kai.MessageType_ERROR,
 	}
 	tr.publishResponse(responseMsg, "")
@@ -196,7 +291,7 @@ func (tr *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 		return
 	}
 
-	outputMsg, err = tr.prepareOutputMessage(outputMsg)
+	outputMsg, codec, err := tr.prepareOutputMessage(outputMsg)
 	if err != nil {
 		tr.getLoggerWithName().Error(err, "Error preparing output message")
 		return
@@ -204,14 +299,18 @@ func (tr *Runner) publishResponse(responseMsg *kai.KaiNatsMessage, channel strin
 
 	tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Publishing response with subject %s", outputSubject))
 
-	_, err = tr.jetstream.Publish(outputSubject, outputMsg)
+	pubMsg := nats.NewMsg(outputSubject)
+	pubMsg.Data = outputMsg
+	pubMsg.Header = compression.SetHeader(pubMsg.Header, codec)
+
+	_, err = tr.jetstream.PublishMsg(pubMsg)
 	if err != nil {
 		tr.getLoggerWithName().Error(err, "Error publishing output")
 	}
 }
 
 func (tr *Runner) getOutputSubject(channel string) string {
-	outputSubject := viper.GetString(common.ConfigNatsOutputKey)
+	outputSubject := tr.getConfig().GetString(common.ConfigNatsOutputKey)
 	if channel != "" {
 		return fmt.Sprintf("%s.%s", outputSubject, channel)
 	}
@@ -219,39 +318,42 @@ func (tr *Runner) getOutputSubject(channel string) string {
 	return outputSubject
 }
 
-// prepareOutputMessage will check the length of the message and compress it if necessary.
-// Fails on compressed messages bigger than the threshold.
-func (tr *Runner) prepareOutputMessage(msg []byte) ([]byte, error) {
+// prepareOutputMessage compresses the message with the configured codec when it exceeds the maximum
+// size of the stream or the compression threshold, and returns it along with the codec used, if any.
+// Fails on compressed messages bigger than the maximum size.
+func (tr *Runner) prepareOutputMessage(msg []byte) ([]byte, string, error) {
 	maxSize, err := tr.getMaxMessageSize()
 	if err != nil {
-		return nil, fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
+		return nil, "", fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
 	}
 
+	options := compression.OptionsFromConfig(tr.getConfig())
+
 	lenMsg := int64(len(msg))
-	if lenMsg <= maxSize {
-		return msg, nil
+	if lenMsg <= maxSize && !options.ShouldCompress(lenMsg) {
+		return msg, "", nil
 	}
 
-	outMsg, err := common.CompressData(msg)
+	outMsg, codec, err := options.Compress(msg)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	lenOutMsg := int64(len(outMsg))
 	if lenOutMsg > maxSize {
-		tr.getLoggerWithName().V(1).Info("Compressed message size %s "+
-			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize))
-		return nil, errors.ErrMessageToBig
+		tr.getLoggerWithName().V(1).Info(fmt.Sprintf("Compressed message size %s "+
+			"exceeds maximum size allowed %s", sizeInMB(lenOutMsg), sizeInMB(maxSize)))
+		return nil, "", errors.ErrMessageToBig
 	}
 
 	tr.getLoggerWithName().Info(fmt.Sprintf("Message prepared with original size %s "+
 		"and compressed size %s", sizeInMB(lenMsg), sizeInMB(lenOutMsg)))
 
-	return outMsg, nil
+	return outMsg, codec, nil
 }
 
 func (tr *Runner) getMaxMessageSize() (int64, error) {
-	streamInfo, err := tr.jetstream.StreamInfo(viper.GetString(common.ConfigNatsStreamKey))
+	streamInfo, err := tr.jetstream.StreamInfo(tr.getConfig().GetString(common.ConfigNatsStreamKey))
 	if err != nil {
 		return 0, fmt.Errorf("error getting stream's max message size: %w", err)
 	}
@@ -271,28 +373,37 @@ func (tr *Runner) getMaxMessageSize() (int64, error) {
 }
 
 func (tr *Runner) getMetricAttributes(requestID string) attribute.Set {
-	return attribute.NewSet(
-		attribute.KeyValue{
+	return attribute.NewSet(append(tr.getProcessAttributes(), attribute.KeyValue{
+		Key:   "request_id",
+		Value: attribute.StringValue(requestID),
+	})...)
+}
+
+// getProcessMetricAttributes returns the attributes of the metrics about the process as a whole,
+// which are not split by request ID so they are not given a new series per request.
+func (tr *Runner) getProcessMetricAttributes() attribute.Set {
+	return attribute.NewSet(tr.getProcessAttributes()...)
+}
+
+func (tr *Runner) getProcessAttributes() []attribute.KeyValue {
+	return []attribute.KeyValue{
+		{
 			Key:   "product",
 			Value: attribute.StringValue(tr.sdk.Metadata.GetProduct()),
 		},
-		attribute.KeyValue{
+		{
 			Key:   "version",
 			Value: attribute.StringValue(tr.sdk.Metadata.GetVersion()),
 		},
-		attribute.KeyValue{
+		{
 			Key:   "workflow",
 			Value: attribute.StringValue(tr.sdk.Metadata.GetWorkflow()),
 		},
-		attribute.KeyValue{
+		{
 			Key:   "process",
 			Value: attribute.StringValue(tr.sdk.Metadata.GetProcess()),
 		},
-		attribute.KeyValue{
-			Key:   "request_id",
-			Value: attribute.StringValue(requestID),
-		},
-	)
+	}
 }
 
 func sizeInMB(size int64) string {
@@ -1,12 +1,16 @@
 package trigger
 
 import (
+	"context"
+	"os"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/runner/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
 	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel/metric"
 	"google.golang.org/protobuf/types/known/anypb"
 )
@@ -19,21 +23,48 @@ type ResponseHandler func(sdk sdk.KaiSDK, response *anypb.Any) error
 
 type Runner struct {
 	sdk              sdk.KaiSDK
+	config           *viper.Viper
+	ctx              context.Context
+	cancel           context.CancelFunc
+	stopped          chan struct{}
 	nats             *nats.Conn
 	jetstream        nats.JetStreamContext
 	responseHandler  ResponseHandler
 	responseChannels sync.Map
+	pendingRequests  sync.Map
+	streams          sync.Map
 	initializer      common.Initializer
 	runner           RunnerFunc
 	finalizer        common.Finalizer
 	messagesMetric   metric.Int64Histogram
+	panicsMetric     metric.Int64Counter
+	wg               sync.WaitGroup
+	gracePeriod      time.Duration
+	sharedConnection bool
+	health           *common.HealthServer
+
+	pendingRequestsMetric  metric.Int64UpDownCounter
+	timedOutRequestsMetric metric.Int64Counter
 }
 
-var wg sync.WaitGroup //nolint:gochecknoglobals // WaitGroup is used to wait for goroutines to finish
-
 func NewTriggerRunner(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext) *Runner {
+	return NewTriggerRunnerWithConfig(logger, ns, js, nil)
+}
+
+// NewTriggerRunnerWithConfig creates a Runner reading its configuration from the given viper instance
+// instead of the global one.
+func NewTriggerRunnerWithConfig(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	return NewTriggerRunnerWithSDK(sdk.NewKaiSDKWithConfig(logger, ns, js, config), ns, js, config)
+}
+
+// NewTriggerRunnerWithSDK creates a Runner using an already initialized KaiSDK, so the errors
+// initializing it can be handled by the caller.
+func NewTriggerRunnerWithSDK(kaiSDK sdk.KaiSDK, ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) *Runner {
+	kaiSDK.Logger = kaiSDK.Logger.WithName(_triggerLoggerName)
+
 	return &Runner{
-		sdk:              sdk.NewKaiSDK(logger.WithName(_triggerLoggerName), ns, js),
+		sdk:              kaiSDK,
+		config:           config,
 		nats:             ns,
 		jetstream:        js,
 		responseChannels: sync.Map{},
@@ -41,7 +72,7 @@ func NewTriggerRunner(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContex
 }
 
 func (tr *Runner) WithInitializer(initializer common.Initializer) *Runner {
-	tr.initializer = composeInitializer(initializer)
+	tr.initializer = composeInitializer(initializer, tr.config)
 	return tr
 }
 
@@ -55,39 +86,126 @@ func (tr *Runner) WithFinalizer(finalizer common.Finalizer) *Runner {
 	return tr
 }
 
+// WithShutdownGracePeriod sets how long the RunnerFunc can take to finish the requests in process
+// once the runner starts shutting down, overriding the runner.shutdown_grace_period configuration.
+// The pending requests fail with ErrRunnerStopped when it expires.
+func (tr *Runner) WithShutdownGracePeriod(gracePeriod time.Duration) *Runner {
+	tr.gracePeriod = gracePeriod
+	return tr
+}
+
+// WithSharedConnection tells the runner that its NATS connection is also used by the caller, so on
+// shutdown it is only flushed instead of drained and closed.
+func (tr *Runner) WithSharedConnection() *Runner {
+	tr.sharedConnection = true
+	return tr
+}
+
+// GetResponseChannel returns a channel receiving the response to the given request ID. The channel
+// is kept until the response arrives or the runner stops.
+//
+// Deprecated: use Request or RequestWithID, which stop waiting once their context is done instead
+// of keeping the channel of a response that never arrives until the runner stops.
 func (tr *Runner) GetResponseChannel(requestID string) <-chan *anypb.Any {
-	tr.responseChannels.Store(requestID, make(chan *anypb.Any))
+	tr.responseChannels.Store(requestID, make(chan *anypb.Any, 1))
 	channel, _ := tr.responseChannels.Load(requestID)
 
 	return channel.(chan *anypb.Any)
 }
 
+// Context returns a context that is done once the runner starts shutting down, so the RunnerFunc
+// can stop taking new requests. The requests in process still get their responses until the
+// RunnerFunc returns or the shutdown grace period expires. It is nil until the runner runs.
+func (tr *Runner) Context() context.Context {
+	return tr.ctx
+}
+
+// ShutdownGracePeriod returns how long the RunnerFunc and the requests in process are waited for
+// once the runner starts shutting down.
+func (tr *Runner) ShutdownGracePeriod() time.Duration {
+	return tr.getShutdownGracePeriod()
+}
+
+// Stop shuts the runner down as a shutdown signal does. A RunnerFunc that cannot take requests
+// anymore, such as a server failing to listen, stops the runner so the process does not keep
+// running without serving anything.
+func (tr *Runner) Stop() {
+	if tr.cancel != nil {
+		tr.cancel()
+	}
+}
+
+// JetStream returns the JetStream context of the runner, so a RunnerFunc can use streams and
+// key-value stores of its own.
+func (tr *Runner) JetStream() nats.JetStreamContext {
+	return tr.jetstream
+}
+
 func (tr *Runner) Run() {
+	tr.RunContext(context.Background())
+}
+
+// RunContext runs the TriggerRunner until a shutdown signal is received or the given context is done.
+func (tr *Runner) RunContext(ctx context.Context) {
+	tr.ctx, tr.cancel = context.WithCancel(ctx)
+	defer tr.cancel()
+
+	tr.stopped = make(chan struct{})
+
 	// Check required fields are initialized
 	if tr.runner == nil {
 		panic("Undefined runner function")
 	}
 
 	if tr.initializer == nil {
-		tr.initializer = composeInitializer(nil)
+		tr.initializer = composeInitializer(nil, tr.config)
 	}
 
-	tr.responseHandler = getResponseHandler(&tr.responseChannels)
+	tr.responseHandler = getResponseHandler(tr)
 
 	if tr.finalizer == nil {
 		tr.finalizer = composeFinalizer(nil)
 	}
 
+	if err := tr.initializeRequestMetrics(); err != nil {
+		tr.sdk.Logger.Error(err, "Error initializing metric")
+		os.Exit(1)
+	}
+
+	tr.health = common.NewHealthServer(tr.sdk.Logger, tr.sdk, tr.nats, tr.config)
+	if err := tr.health.Start(); err != nil {
+		tr.sdk.Logger.Error(err, "Error starting health server")
+		os.Exit(1)
+	}
+	defer tr.health.Shutdown()
+
+	stopClaimCheckSweeper := common.StartClaimCheckSweeper(tr.sdk.Logger, tr.jetstream, tr.config)
+
 	tr.initializer(tr.sdk)
 
 	delta := 2
-	wg.Add(delta)
+	tr.wg.Add(delta)
+
+	// The runner starts once the subscriptions are ready, so no response to its requests is missed.
+	subscribed := make(chan struct{})
+
+	go tr.startSubscriber(subscribed)
+
+	<-subscribed
+
+	tr.health.SetReady(true)
 
 	go tr.runner(tr, tr.sdk)
 
-	go tr.startSubscriber()
+	tr.wg.Wait()
 
-	wg.Wait()
+	stopClaimCheckSweeper()
+
+	if tr.sharedConnection {
+		common.FlushConnection(tr.sdk.Logger, tr.sdk, tr.nats)
+	} else {
+		common.DrainConnection(tr.sdk.Logger, tr.sdk, tr.nats)
+	}
 
 	tr.finalizer(tr.sdk)
 }
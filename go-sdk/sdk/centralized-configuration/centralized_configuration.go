@@ -37,9 +37,15 @@ type CentralizedConfiguration struct {
 }
 
 func New(logger logr.Logger, js nats.JetStreamContext) (*CentralizedConfiguration, error) {
+	return NewWithConfig(logger, js, nil)
+}
+
+// NewWithConfig creates a CentralizedConfiguration reading the key-value store names from the given
+// viper instance instead of the global one.
+func NewWithConfig(logger logr.Logger, js nats.JetStreamContext, config *viper.Viper) (*CentralizedConfiguration, error) {
 	wrapErr := utilErrors.Wrapper("configuration init: %w")
 
-	globalKv, productKv, workflowKv, processKv, err := initKVStores(logger, js)
+	globalKv, productKv, workflowKv, processKv, err := initKVStores(logger, js, common.ConfigOrGlobal(config))
 	if err != nil {
 		return nil, wrapErr(err)
 	}
@@ -53,12 +59,12 @@ func New(logger logr.Logger, js nats.JetStreamContext) (*CentralizedConfiguratio
 	}, nil
 }
 
-func initKVStores(logger logr.Logger, js nats.JetStreamContext) (
+func initKVStores(logger logr.Logger, js nats.JetStreamContext, config *viper.Viper) (
 	globalKv, productKv, workflowKv, processKv nats.KeyValue, err error,
 ) {
 	wrapErr := utilErrors.Wrapper("configuration init: %w")
 
-	name := viper.GetString(common.ConfigCcGlobalBucketKey)
+	name := config.GetString(common.ConfigCcGlobalBucketKey)
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).
 		Info(fmt.Sprintf("Initializing global key-value store with name %s", name))
 
@@ -68,7 +74,7 @@ func initKVStores(logger logr.Logger, js nats.JetStreamContext) (
 		return nil, nil, nil, nil, wrapErr(err)
 	}
 
-	name = viper.GetString(common.ConfigCcProductBucketKey)
+	name = config.GetString(common.ConfigCcProductBucketKey)
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).
 		Info(fmt.Sprintf("Initializing product key-value store with name %s", name))
 
@@ -81,7 +87,7 @@ func initKVStores(logger logr.Logger, js nats.JetStreamContext) (
 
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).Info("Product key-value store initialized")
 
-	name = viper.GetString(common.ConfigCcWorkflowBucketKey)
+	name = config.GetString(common.ConfigCcWorkflowBucketKey)
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).
 		Info(fmt.Sprintf("Initializing workflow key-value store with name %s", name))
 
@@ -94,7 +100,7 @@ func initKVStores(logger logr.Logger, js nats.JetStreamContext) (
 
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).Info("Workflow key-value store initialized")
 
-	name = viper.GetString(common.ConfigCcProcessBucketKey)
+	name = config.GetString(common.ConfigCcProcessBucketKey)
 	logger.WithName(_centralizedConfigurationLoggerName).V(1).
 		Info(fmt.Sprintf("Initializing process key-value store with name %s", name))
 
@@ -0,0 +1,146 @@
+package compression
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/s2"
+	"github.com/klauspost/compress/zstd"
+)
+
+// _gzipDefaultLevel keeps the best compression gzip messages were always sent with.
+const _gzipDefaultLevel = gzip.BestCompression
+
+type noneCodec struct{}
+
+func (noneCodec) Name() string {
+	return CodecNone
+}
+
+func (noneCodec) Compress(data []byte, _ int) ([]byte, error) {
+	return data, nil
+}
+
+func (noneCodec) Decompress(data []byte) ([]byte, error) {
+	return data, nil
+}
+
+// gzipCodec takes levels from gzip.HuffmanOnly to gzip.BestCompression.
+type gzipCodec struct{}
+
+func (gzipCodec) Name() string {
+	return CodecGzip
+}
+
+func (gzipCodec) Compress(data []byte, level int) ([]byte, error) {
+	if level == DefaultLevel {
+		level = _gzipDefaultLevel
+	}
+
+	var b bytes.Buffer
+
+	gz, err := gzip.NewWriterLevel(&b, level)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := gz.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+func (gzipCodec) Decompress(data []byte) ([]byte, error) {
+	gr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	defer gr.Close()
+
+	return io.ReadAll(gr)
+}
+
+// zstdCodec takes the zstd levels, from 1 to 22, mapped to the closest level of the encoder.
+// Encoders and the decoder are created once and shared, since they are expensive to create.
+type zstdCodec struct {
+	encoders    sync.Map
+	decoderOnce sync.Once
+	decoder     *zstd.Decoder
+	decoderErr  error
+}
+
+func (*zstdCodec) Name() string {
+	return CodecZstd
+}
+
+func (c *zstdCodec) Compress(data []byte, level int) ([]byte, error) {
+	encoder, err := c.getEncoder(level)
+	if err != nil {
+		return nil, err
+	}
+
+	return encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) Decompress(data []byte) ([]byte, error) {
+	c.decoderOnce.Do(func() {
+		c.decoder, c.decoderErr = zstd.NewReader(nil)
+	})
+
+	if c.decoderErr != nil {
+		return nil, c.decoderErr
+	}
+
+	return c.decoder.DecodeAll(data, nil)
+}
+
+func (c *zstdCodec) getEncoder(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.SpeedDefault
+	if level != DefaultLevel {
+		encoderLevel = zstd.EncoderLevelFromZstd(level)
+	}
+
+	if encoder, ok := c.encoders.Load(encoderLevel); ok {
+		return encoder.(*zstd.Encoder), nil //nolint:forcetypeassert // only encoders are stored
+	}
+
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel))
+	if err != nil {
+		return nil, err
+	}
+
+	stored, _ := c.encoders.LoadOrStore(encoderLevel, encoder)
+
+	return stored.(*zstd.Encoder), nil //nolint:forcetypeassert // only encoders are stored
+}
+
+// snappyCodec sends Snappy blocks. Level 1, the default, is the fastest, 2 compresses better and 3
+// compresses best.
+type snappyCodec struct{}
+
+func (snappyCodec) Name() string {
+	return CodecSnappy
+}
+
+func (snappyCodec) Compress(data []byte, level int) ([]byte, error) {
+	switch {
+	case level >= 3: //nolint:gomnd // snappy levels
+		return s2.EncodeSnappyBest(nil, data), nil
+	case level == 2: //nolint:gomnd // snappy levels
+		return s2.EncodeSnappyBetter(nil, data), nil
+	default:
+		return s2.EncodeSnappy(nil, data), nil
+	}
+}
+
+func (snappyCodec) Decompress(data []byte) ([]byte, error) {
+	return s2.Decode(nil, data)
+}
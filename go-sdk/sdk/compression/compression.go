@@ -0,0 +1,185 @@
+package compression
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+)
+
+// Codecs of the nats.compression.codec configuration.
+const (
+	CodecNone   = "none"
+	CodecGzip   = "gzip"
+	CodecZstd   = "zstd"
+	CodecSnappy = "snappy"
+)
+
+// DefaultLevel compresses with the default level of the codec.
+const DefaultLevel = 0
+
+var ErrUnknownCodec = errors.New("unknown compression codec")
+
+// Codec compresses the messages sent between the processes of a workflow. The level is specific
+// to each codec, and DefaultLevel chooses its default one. Codecs must be safe for concurrent use.
+type Codec interface {
+	Name() string
+	Compress(data []byte, level int) ([]byte, error)
+	Decompress(data []byte) ([]byte, error)
+}
+
+var (
+	_codecsMu sync.RWMutex
+	_codecs   = map[string]Codec{}
+)
+
+func init() {
+	Register(noneCodec{})
+	Register(gzipCodec{})
+	Register(&zstdCodec{})
+	Register(snappyCodec{})
+}
+
+// Register makes the codec available to send and receive messages under its name, replacing the
+// codec registered with the same name, if any. Every process of a workflow must register the
+// codecs used by the processes sending it messages.
+func Register(codec Codec) {
+	_codecsMu.Lock()
+	defer _codecsMu.Unlock()
+
+	_codecs[codec.Name()] = codec
+}
+
+// Get returns the codec registered with the given name.
+func Get(name string) (Codec, error) {
+	_codecsMu.RLock()
+	defer _codecsMu.RUnlock()
+
+	codec, ok := _codecs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCodec, name)
+	}
+
+	return codec, nil
+}
+
+// Names returns the sorted names of the registered codecs.
+func Names() []string {
+	_codecsMu.RLock()
+	defer _codecsMu.RUnlock()
+
+	names := make([]string, 0, len(_codecs))
+	for name := range _codecs {
+		names = append(names, name)
+	}
+
+	slices.Sort(names)
+
+	return names
+}
+
+// Options of the compression of the messages sent by a process.
+type Options struct {
+	// Codec compressing the messages, gzip by default.
+	Codec string
+	// Level of the codec, DefaultLevel by default.
+	Level int
+	// Threshold is the size in bytes from which messages are compressed even when they fit in the
+	// stream. Zero only compresses messages bigger than the maximum size of the stream.
+	Threshold int64
+}
+
+// OptionsFromConfig reads the nats.compression.codec, nats.compression.level and
+// nats.compression.threshold keys of the given viper instance, or the global one when nil.
+func OptionsFromConfig(config *viper.Viper) Options {
+	cfg := common.ConfigOrGlobal(config)
+
+	options := Options{
+		Codec:     cfg.GetString(common.ConfigNatsCompressionCodecKey),
+		Level:     cfg.GetInt(common.ConfigNatsCompressionLevelKey),
+		Threshold: int64(cfg.GetSizeInBytes(common.ConfigNatsCompressionThresholdKey)),
+	}
+
+	if options.Codec == "" {
+		options.Codec = CodecGzip
+	}
+
+	return options
+}
+
+// ShouldCompress tells whether a message of the given size fitting in the stream is compressed
+// anyway because it reaches the threshold.
+func (o Options) ShouldCompress(size int64) bool {
+	return o.Codec != CodecNone && o.Threshold > 0 && size >= o.Threshold
+}
+
+// Compress compresses the message with the codec of the options and returns it along with the
+// name of the codec to declare in the CompressionHeader. Messages that do not shrink are returned
+// as is, with no codec.
+func (o Options) Compress(data []byte) ([]byte, string, error) {
+	codec, err := Get(o.Codec)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if codec.Name() == CodecNone {
+		return data, "", nil
+	}
+
+	compressed, err := codec.Compress(data, o.Level)
+	if err != nil {
+		return nil, "", fmt.Errorf("error compressing message with %s: %w", codec.Name(), err)
+	}
+
+	if len(compressed) >= len(data) {
+		return data, "", nil
+	}
+
+	return compressed, codec.Name(), nil
+}
+
+// Decompress returns the message decompressed with the codec declared in its CompressionHeader.
+// Messages without it are only decompressed when they are gzipped, as sent by former versions,
+// which is unambiguous since a serialized KaiNatsMessage never starts with the gzip magic bytes.
+func Decompress(header nats.Header, data []byte) ([]byte, error) {
+	name := header.Get(common.CompressionHeader)
+	if name == "" {
+		if !common.IsCompressed(data) {
+			return data, nil
+		}
+
+		name = CodecGzip
+	}
+
+	codec, err := Get(name)
+	if err != nil {
+		return nil, err
+	}
+
+	decompressed, err := codec.Decompress(data)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing message with %s: %w", name, err)
+	}
+
+	return decompressed, nil
+}
+
+// SetHeader declares the codec returned by Options.Compress in the header, if any.
+func SetHeader(header nats.Header, codec string) nats.Header {
+	if codec == "" {
+		return header
+	}
+
+	if header == nil {
+		header = nats.Header{}
+	}
+
+	header.Set(common.CompressionHeader, codec)
+
+	return header
+}
@@ -0,0 +1,200 @@
+//go:build unit
+
+package compression_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
+)
+
+type CompressionTestSuite struct {
+	suite.Suite
+	data []byte
+}
+
+func (s *CompressionTestSuite) SetupTest() {
+	s.data = bytes.Repeat([]byte("compressible message "), 1000)
+}
+
+func (s *CompressionTestSuite) TestCompress_EveryCodec_ExpectDecompressedFromHeader() {
+	for _, name := range []string{compression.CodecGzip, compression.CodecZstd, compression.CodecSnappy} {
+		for _, level := range []int{compression.DefaultLevel, 1, 3} {
+			// Given
+			options := compression.Options{Codec: name, Level: level}
+
+			// When
+			compressed, codec, err := options.Compress(s.data)
+			s.Require().NoError(err, name)
+
+			header := compression.SetHeader(nil, codec)
+			decompressed, err := compression.Decompress(header, compressed)
+
+			// Then
+			s.Require().NoError(err, name)
+			s.Equal(name, header.Get(common.CompressionHeader))
+			s.Less(len(compressed), len(s.data), name)
+			s.Equal(s.data, decompressed, name)
+		}
+	}
+}
+
+func (s *CompressionTestSuite) TestCompress_NoneCodec_ExpectSameData() {
+	// Given
+	options := compression.Options{Codec: compression.CodecNone}
+
+	// When
+	compressed, codec, err := options.Compress(s.data)
+
+	// Then
+	s.Require().NoError(err)
+	s.Empty(codec)
+	s.Equal(s.data, compressed)
+	s.Nil(compression.SetHeader(nil, codec))
+}
+
+func (s *CompressionTestSuite) TestCompress_Incompressible_ExpectSameData() {
+	// Given
+	data := []byte{0x0a, 0x01, 0x02}
+	options := compression.Options{Codec: compression.CodecGzip}
+
+	// When
+	compressed, codec, err := options.Compress(data)
+
+	// Then
+	s.Require().NoError(err)
+	s.Empty(codec)
+	s.Equal(data, compressed)
+}
+
+func (s *CompressionTestSuite) TestCompress_UnknownCodec_ExpectError() {
+	// Given
+	options := compression.Options{Codec: "lz4"}
+
+	// When
+	_, _, err := options.Compress(s.data)
+
+	// Then
+	s.ErrorIs(err, compression.ErrUnknownCodec)
+}
+
+func (s *CompressionTestSuite) TestDecompress_GzipWithoutHeader_ExpectDecompressed() {
+	// Given
+	compressed, err := common.CompressData(s.data)
+	s.Require().NoError(err)
+
+	// When
+	decompressed, err := compression.Decompress(nil, compressed)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(s.data, decompressed)
+}
+
+func (s *CompressionTestSuite) TestDecompress_NoHeader_ExpectSameData() {
+	// Given
+	header := nats.Header{}
+
+	// When
+	decompressed, err := compression.Decompress(header, s.data)
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal(s.data, decompressed)
+}
+
+func (s *CompressionTestSuite) TestDecompress_UnknownCodec_ExpectError() {
+	// Given
+	header := compression.SetHeader(nil, "lz4")
+
+	// When
+	_, err := compression.Decompress(header, s.data)
+
+	// Then
+	s.ErrorIs(err, compression.ErrUnknownCodec)
+}
+
+func (s *CompressionTestSuite) TestDecompress_CorruptedData_ExpectError() {
+	// Given
+	header := compression.SetHeader(nil, compression.CodecZstd)
+
+	// When
+	_, err := compression.Decompress(header, s.data)
+
+	// Then
+	s.ErrorContains(err, "error decompressing message with zstd")
+}
+
+func (s *CompressionTestSuite) TestRegister_ExpectCodecAvailable() {
+	// Given
+	codec := reverseCodec{}
+
+	// When
+	compression.Register(codec)
+
+	// Then
+	s.Contains(compression.Names(), codec.Name())
+
+	decompressed, err := compression.Decompress(compression.SetHeader(nil, codec.Name()), []byte("olleh"))
+	s.Require().NoError(err)
+	s.Equal([]byte("hello"), decompressed)
+}
+
+func (s *CompressionTestSuite) TestOptionsFromConfig_ExpectOptions() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigNatsCompressionCodecKey, compression.CodecZstd)
+	config.Set(common.ConfigNatsCompressionLevelKey, 3)
+	config.Set(common.ConfigNatsCompressionThresholdKey, "512KB")
+
+	// When
+	options := compression.OptionsFromConfig(config)
+
+	// Then
+	s.Equal(compression.Options{Codec: compression.CodecZstd, Level: 3, Threshold: 512 * 1024}, options)
+	s.False(options.ShouldCompress(512*1024 - 1))
+	s.True(options.ShouldCompress(512 * 1024))
+}
+
+func (s *CompressionTestSuite) TestOptionsFromConfig_Unset_ExpectGzipWithoutThreshold() {
+	// When
+	options := compression.OptionsFromConfig(viper.New())
+
+	// Then
+	s.Equal(compression.Options{Codec: compression.CodecGzip}, options)
+	s.False(options.ShouldCompress(1 << 30))
+}
+
+// reverseCodec reverses the bytes of the messages, to check that registered codecs are used.
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string {
+	return "reverse"
+}
+
+func (reverseCodec) Compress(data []byte, _ int) ([]byte, error) {
+	return reverse(data), nil
+}
+
+func (reverseCodec) Decompress(data []byte) ([]byte, error) {
+	return reverse(data), nil
+}
+
+func reverse(data []byte) []byte {
+	reversed := make([]byte, len(data))
+	for i, b := range data {
+		reversed[len(data)-1-i] = b
+	}
+
+	return reversed
+}
+
+func TestCompressionTestSuite(t *testing.T) {
+	suite.Run(t, new(CompressionTestSuite))
+}
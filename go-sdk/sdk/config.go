@@ -0,0 +1,170 @@
+package sdk
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+)
+
+// Config holds the configuration of a process, mirroring the keys of its configuration files.
+// Empty strings and zero numbers are left unset, so they are reported as missing when validated,
+// except for the Redis credentials, which may be empty. Subsystems are enabled unless their
+// Disabled field is set, in which case their keys are not required.
+type Config struct {
+	Metadata                 MetadataConfig
+	Nats                     NatsConfig
+	CentralizedConfiguration CentralizedConfigurationConfig
+	Minio                    MinioConfig
+	Auth                     AuthConfig
+	Predictions              PredictionsConfig
+	ModelRegistry            ModelRegistryConfig
+	Measurements             MeasurementsConfig
+}
+
+type MetadataConfig struct {
+	ProductID    string
+	VersionTag   string
+	WorkflowName string
+	WorkflowType string
+	ProcessName  string
+	ProcessType  string
+}
+
+type NatsConfig struct {
+	URL         string
+	Stream      string
+	Output      string
+	Inputs      []string
+	ObjectStore string
+}
+
+type CentralizedConfigurationConfig struct {
+	GlobalBucket   string
+	ProductBucket  string
+	WorkflowBucket string
+	ProcessBucket  string
+	ProcessConfig  map[string]string
+}
+
+type MinioConfig struct {
+	Disabled       bool
+	Endpoint       string
+	ClientUser     string
+	ClientPassword string
+	SSL            bool
+	Bucket         string
+	InternalFolder string
+}
+
+type AuthConfig struct {
+	Disabled     bool
+	Endpoint     string
+	Client       string
+	ClientSecret string
+	Realm        string
+}
+
+type PredictionsConfig struct {
+	Disabled bool
+	Endpoint string
+	Username string
+	Password string
+	Index    string
+}
+
+type ModelRegistryConfig struct {
+	Disabled   bool
+	FolderName string
+}
+
+type MeasurementsConfig struct {
+	Disabled        bool
+	Exporter        string
+	Endpoint        string
+	Insecure        bool
+	Timeout         int
+	MetricsInterval int
+	// PrometheusAddress is the address the metrics are served on with the prometheus exporter.
+	PrometheusAddress string
+}
+
+// Viper returns a new viper instance holding the configuration.
+func (c Config) Viper() *viper.Viper {
+	config := viper.New()
+
+	setString := func(key, value string) {
+		if value != "" {
+			config.Set(key, value)
+		}
+	}
+
+	setInt := func(key string, value int) {
+		if value != 0 {
+			config.Set(key, value)
+		}
+	}
+
+	setDisabled := func(key string, disabled bool) {
+		if disabled {
+			config.Set(key, false)
+		}
+	}
+
+	setString(common.ConfigMetadataProductIDKey, c.Metadata.ProductID)
+	setString(common.ConfigMetadataVersionIDKey, c.Metadata.VersionTag)
+	setString(common.ConfigMetadataWorkflowIDKey, c.Metadata.WorkflowName)
+	setString(common.ConfigMetadataWorkflowTypeKey, c.Metadata.WorkflowType)
+	setString(common.ConfigMetadataProcessIDKey, c.Metadata.ProcessName)
+	setString(common.ConfigMetadataProcessTypeKey, c.Metadata.ProcessType)
+
+	setString(common.ConfigNatsURLKey, c.Nats.URL)
+	setString(common.ConfigNatsStreamKey, c.Nats.Stream)
+	setString(common.ConfigNatsOutputKey, c.Nats.Output)
+	setString(common.ConfigNatsEphemeralStorage, c.Nats.ObjectStore)
+
+	if len(c.Nats.Inputs) > 0 {
+		config.Set(common.ConfigNatsInputsKey, c.Nats.Inputs)
+	}
+
+	setString(common.ConfigCcGlobalBucketKey, c.CentralizedConfiguration.GlobalBucket)
+	setString(common.ConfigCcProductBucketKey, c.CentralizedConfiguration.ProductBucket)
+	setString(common.ConfigCcWorkflowBucketKey, c.CentralizedConfiguration.WorkflowBucket)
+	setString(common.ConfigCcProcessBucketKey, c.CentralizedConfiguration.ProcessBucket)
+
+	if len(c.CentralizedConfiguration.ProcessConfig) > 0 {
+		config.Set(common.ConfigCcProcessConfigKey, c.CentralizedConfiguration.ProcessConfig)
+	}
+
+	setDisabled(common.ConfigMinioEnabledKey, c.Minio.Disabled)
+	setString(common.ConfigMinioEndpointKey, c.Minio.Endpoint)
+	setString(common.ConfigMinioClientUserKey, c.Minio.ClientUser)
+	setString(common.ConfigMinioClientPasswordKey, c.Minio.ClientPassword)
+	config.Set(common.ConfigMinioUseSslKey, c.Minio.SSL)
+	setString(common.ConfigMinioBucketKey, c.Minio.Bucket)
+	setString(common.ConfigMinioInternalFolderKey, c.Minio.InternalFolder)
+
+	setDisabled(common.ConfigAuthEnabledKey, c.Auth.Disabled)
+	setString(common.ConfigAuthEndpointKey, c.Auth.Endpoint)
+	setString(common.ConfigAuthClientKey, c.Auth.Client)
+	setString(common.ConfigAuthClientSecretKey, c.Auth.ClientSecret)
+	setString(common.ConfigAuthRealmKey, c.Auth.Realm)
+
+	setDisabled(common.ConfigRedisEnabledKey, c.Predictions.Disabled)
+	setString(common.ConfigRedisEndpointKey, c.Predictions.Endpoint)
+	config.Set(common.ConfigRedisUsernameKey, c.Predictions.Username)
+	config.Set(common.ConfigRedisPasswordKey, c.Predictions.Password)
+	setString(common.ConfigRedisIndexKey, c.Predictions.Index)
+
+	setDisabled(common.ConfigModelRegistryEnabledKey, c.ModelRegistry.Disabled)
+	setString(common.ConfigModelFolderNameKey, c.ModelRegistry.FolderName)
+
+	setDisabled(common.ConfigMeasurementsEnabledKey, c.Measurements.Disabled)
+	setString(common.ConfigMeasurementsExporterKey, c.Measurements.Exporter)
+	setString(common.ConfigMeasurementsEndpointKey, c.Measurements.Endpoint)
+	config.Set(common.ConfigMeasurementsInsecureKey, c.Measurements.Insecure)
+	setInt(common.ConfigMeasurementsTimeoutKey, c.Measurements.Timeout)
+	setInt(common.ConfigMeasurementsMetricsIntervalKey, c.Measurements.MetricsInterval)
+	setString(common.ConfigMeasurementsPrometheusAddressKey, c.Measurements.PrometheusAddress)
+
+	return config
+}
@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.opentelemetry.io/otel/trace"
+	traceNoop "go.opentelemetry.io/otel/trace/noop"
+
+	utilErrors "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+	modelregistry "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/model-registry"
+	persistentstorage "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/persistent-storage"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/prediction"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+// ErrSubsystemDisabled is returned by the methods of a subsystem disabled by configuration.
+var ErrSubsystemDisabled = utilErrors.ErrSubsystemDisabled
+
+var (
+	errPersistentStorageDisabled = fmt.Errorf("%w: persistent storage", ErrSubsystemDisabled)
+	errModelRegistryDisabled     = fmt.Errorf("%w: model registry", ErrSubsystemDisabled)
+	errPredictionsDisabled       = fmt.Errorf("%w: predictions", ErrSubsystemDisabled)
+)
+
+type disabledPersistentStorage struct{}
+
+func (disabledPersistentStorage) Save(_ string, _ []byte, _ ...int) (*persistentstorage.ObjectInfo, error) {
+	return nil, errPersistentStorageDisabled
+}
+
+func (disabledPersistentStorage) Get(_ string, _ ...string) (*persistentstorage.Object, error) {
+	return nil, errPersistentStorageDisabled
+}
+
+func (disabledPersistentStorage) List() ([]*persistentstorage.ObjectInfo, error) {
+	return nil, errPersistentStorageDisabled
+}
+
+func (disabledPersistentStorage) ListVersions(_ string) ([]*persistentstorage.ObjectInfo, error) {
+	return nil, errPersistentStorageDisabled
+}
+
+func (disabledPersistentStorage) Delete(_ string, _ ...string) error {
+	return errPersistentStorageDisabled
+}
+
+type disabledModelRegistry struct{}
+
+func (disabledModelRegistry) RegisterModel(_ []byte, _, _, _ string, _ ...string) error {
+	return errModelRegistryDisabled
+}
+
+func (disabledModelRegistry) GetModel(_ string, _ ...string) (*modelregistry.Model, error) {
+	return nil, errModelRegistryDisabled
+}
+
+func (disabledModelRegistry) ListModels() ([]*modelregistry.ModelInfo, error) {
+	return nil, errModelRegistryDisabled
+}
+
+func (disabledModelRegistry) ListModelVersions(_ string) ([]*modelregistry.ModelInfo, error) {
+	return nil, errModelRegistryDisabled
+}
+
+func (disabledModelRegistry) DeleteModel(_ string) error {
+	return errModelRegistryDisabled
+}
+
+type disabledPredictions struct{}
+
+func (disabledPredictions) Save(_ context.Context, _ string, _ prediction.Payload) error {
+	return errPredictionsDisabled
+}
+
+func (disabledPredictions) Get(_ context.Context, _ string) (*prediction.Prediction, error) {
+	return nil, errPredictionsDisabled
+}
+
+func (disabledPredictions) Find(_ context.Context, _ *prediction.Filter) ([]prediction.Prediction, error) {
+	return nil, errPredictionsDisabled
+}
+
+func (disabledPredictions) Update(_ context.Context, _ string, _ prediction.UpdatePayloadFunc) error {
+	return errPredictionsDisabled
+}
+
+func (disabledPredictions) Delete(_ context.Context, _ string) error {
+	return errPredictionsDisabled
+}
+
+// disabledMeasurements hands out a meter that records nothing, so instruments can still be
+// created when measurements are disabled.
+type disabledMeasurements struct{}
+
+func (disabledMeasurements) GetMetricsClient() metric.Meter {
+	return noop.NewMeterProvider().Meter("measurements")
+}
+
+func (disabledMeasurements) Flush(_ context.Context) error {
+	return nil
+}
+
+func (disabledMeasurements) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// disabledTracing hands out a tracer that records nothing. Its spans still carry the trace context
+// of the messages received, so it is propagated to the next processes.
+type disabledTracing struct{}
+
+func (disabledTracing) GetTracer() trace.Tracer {
+	return traceNoop.NewTracerProvider().Tracer(tracing.TracerName)
+}
+
+func (disabledTracing) Flush(_ context.Context) error {
+	return nil
+}
+
+func (disabledTracing) Shutdown(_ context.Context) error {
+	return nil
+}
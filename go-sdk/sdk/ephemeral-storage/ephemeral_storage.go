@@ -3,6 +3,7 @@ package objectstore
 import (
 	"fmt"
 	regexp2 "regexp"
+	"strings"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 
@@ -24,7 +25,12 @@ type EphemeralStorage struct {
 }
 
 func New(logger logr.Logger, jetstream nats.JetStreamContext) (*EphemeralStorage, error) {
-	ephemeralStorageBucket := viper.GetString(common.ConfigNatsEphemeralStorage)
+	return NewWithConfig(logger, jetstream, nil)
+}
+
+// NewWithConfig creates an EphemeralStorage reading from the given viper instance instead of the global one.
+func NewWithConfig(logger logr.Logger, jetstream nats.JetStreamContext, config *viper.Viper) (*EphemeralStorage, error) {
+	ephemeralStorageBucket := common.ConfigOrGlobal(config).GetString(common.ConfigNatsEphemeralStorage)
 
 	ephemeralStorage, err := initEphemeralStorageDeps(logger, jetstream, ephemeralStorageBucket)
 	if err != nil {
@@ -126,6 +132,11 @@ func (es EphemeralStorage) List(regexp ...string) ([]string, error) {
 	var response []string
 
 	for _, objName := range objStoreList {
+		// The claim checks of the messages too big to be sent are not objects of the process.
+		if strings.HasPrefix(objName.Name, common.ClaimCheckObjectPrefix) {
+			continue
+		}
+
 		if pattern == nil || pattern.MatchString(objName.Name) {
 			response = append(response, objName.Name)
 		}
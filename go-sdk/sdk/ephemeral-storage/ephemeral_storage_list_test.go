@@ -9,6 +9,7 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 )
 
@@ -67,6 +68,25 @@ func (s *SdkObjectStoreTestSuite) TestObjectStore_ListObject_ExpectOK() {
 	s.Equal([]string{"key1", "key2"}, keyList)
 }
 
+func (s *SdkObjectStoreTestSuite) TestObjectStore_ListObjectWithClaimChecks_ExpectClaimChecksHidden() {
+	// Given
+	viper.SetDefault(natsObjectStoreField, natsObjectStoreValue)
+	s.jetstream.On("ObjectStore", natsObjectStoreValue).Return(&s.objectStore, nil)
+	objectStore, _ := objectStore2.New(s.logger, &s.jetstream)
+
+	keys := []string{"key1", common.ClaimCheckObjectPrefix + "request-id/claim-check-id"}
+	objects := generateObjectInfoResponse(keys)
+
+	s.objectStore.On("List").Return(objects, nil)
+
+	// When
+	keyList, err := objectStore.List()
+
+	// Then
+	s.NoError(err)
+	s.Equal([]string{"key1"}, keyList)
+}
+
 func (s *SdkObjectStoreTestSuite) TestObjectStore_ListObjectWithFilter_ExpectOK() {
 	// Given
 	viper.SetDefault(natsObjectStoreField, natsObjectStoreValue)
@@ -0,0 +1,190 @@
+// Package health keeps the liveness and readiness checks of a process and serves them over HTTP,
+// so orchestrators such as Kubernetes can tell whether it is running and able to take messages.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	LivenessPath  = "/healthz"
+	ReadinessPath = "/readyz"
+
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+
+	_readyCheckName = "runner"
+)
+
+// ErrNotReady is reported by the readiness endpoint until the runner is marked as ready.
+var ErrNotReady = errors.New("runner not ready")
+
+// Check reports an error when the resource it checks is not healthy. It should return once the
+// given context is done.
+type Check func(ctx context.Context) error
+
+// Report is the result of running the checks of an endpoint, holding the error of each failing one.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// Healthy reports whether every check passed.
+func (r Report) Healthy() bool {
+	return r.Status == StatusOK
+}
+
+// Health holds the registered checks and whether the runner is ready. It is safe for concurrent use.
+type Health struct {
+	mu        sync.RWMutex
+	liveness  map[string]Check
+	readiness map[string]Check
+	ready     atomic.Bool
+	timeout   time.Duration
+}
+
+// New creates a Health whose checks are cancelled after the given timeout, or never when it is zero.
+func New(timeout time.Duration) *Health {
+	return &Health{
+		liveness:  make(map[string]Check),
+		readiness: make(map[string]Check),
+		timeout:   timeout,
+	}
+}
+
+// RegisterLivenessCheck adds a check to the liveness endpoint, replacing the one with the same
+// name. Liveness checks are also part of the readiness endpoint. A failing liveness check usually
+// gets the process restarted, so it should only fail when the process cannot recover by itself.
+func (h *Health) RegisterLivenessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.liveness[name] = check
+}
+
+// RegisterReadinessCheck adds a check to the readiness endpoint, replacing the one with the same
+// name. A failing readiness check only stops the process from getting new work.
+func (h *Health) RegisterReadinessCheck(name string, check Check) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.readiness[name] = check
+}
+
+// SetReady marks the runner as ready or not. Runners are ready once their initializer has run and
+// their subscriptions are active, until they start shutting down.
+func (h *Health) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+func (h *Health) IsReady() bool {
+	return h.ready.Load()
+}
+
+// Liveness runs the liveness checks.
+func (h *Health) Liveness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := copyChecks(h.liveness)
+	h.mu.RUnlock()
+
+	return h.run(ctx, checks, nil)
+}
+
+// Readiness runs the liveness and readiness checks, failing while the runner is not ready.
+func (h *Health) Readiness(ctx context.Context) Report {
+	h.mu.RLock()
+	checks := copyChecks(h.liveness, h.readiness)
+	h.mu.RUnlock()
+
+	failed := make(map[string]string)
+	if !h.IsReady() {
+		failed[_readyCheckName] = ErrNotReady.Error()
+	}
+
+	return h.run(ctx, checks, failed)
+}
+
+// Handler returns an HTTP handler serving the liveness and readiness endpoints. They answer with
+// a JSON Report, with status 200 when every check passes and 503 otherwise.
+func (h *Health) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(LivenessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Liveness(r.Context()))
+	})
+	mux.HandleFunc(ReadinessPath, func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, h.Readiness(r.Context()))
+	})
+
+	return mux
+}
+
+// run runs the given checks concurrently, adding their errors to the failed ones.
+func (h *Health) run(ctx context.Context, checks map[string]Check, failed map[string]string) Report {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+
+	if failed == nil {
+		failed = make(map[string]string)
+	}
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	for name, check := range checks {
+		wg.Add(1)
+
+		go func(name string, check Check) {
+			defer wg.Done()
+
+			if err := check(ctx); err != nil {
+				mu.Lock()
+				failed[name] = err.Error()
+				mu.Unlock()
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	if len(failed) > 0 {
+		return Report{Status: StatusFailing, Checks: failed}
+	}
+
+	return Report{Status: StatusOK}
+}
+
+func copyChecks(groups ...map[string]Check) map[string]Check {
+	checks := make(map[string]Check)
+
+	for _, group := range groups {
+		for name, check := range group {
+			checks[name] = check
+		}
+	}
+
+	return checks
+}
+
+func writeReport(w http.ResponseWriter, report Report) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,140 @@
+//go:build unit
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
+)
+
+var errUnreachable = errors.New("unreachable")
+
+type HealthTestSuite struct {
+	suite.Suite
+	health *health.Health
+	server *httptest.Server
+}
+
+func (s *HealthTestSuite) SetupTest() {
+	s.health = health.New(100 * time.Millisecond)
+	s.server = httptest.NewServer(s.health.Handler())
+}
+
+func (s *HealthTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *HealthTestSuite) TestLiveness_NoChecks_ExpectOK() {
+	// When
+	status, report := s.get(health.LivenessPath)
+
+	// Then
+	s.Equal(http.StatusOK, status)
+	s.Equal(health.Report{Status: health.StatusOK}, report)
+}
+
+func (s *HealthTestSuite) TestLiveness_FailingCheck_ExpectUnavailable() {
+	// Given
+	s.health.RegisterLivenessCheck("ok", func(_ context.Context) error { return nil })
+	s.health.RegisterLivenessCheck("redis", func(_ context.Context) error { return errUnreachable })
+
+	// When
+	status, report := s.get(health.LivenessPath)
+
+	// Then
+	s.Equal(http.StatusServiceUnavailable, status)
+	s.Equal(health.StatusFailing, report.Status)
+	s.Equal(map[string]string{"redis": errUnreachable.Error()}, report.Checks)
+}
+
+func (s *HealthTestSuite) TestLiveness_SlowCheck_ExpectCancelledAfterTimeout() {
+	// Given
+	s.health.RegisterLivenessCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	// When
+	status, report := s.get(health.LivenessPath)
+
+	// Then
+	s.Equal(http.StatusServiceUnavailable, status)
+	s.Equal(context.DeadlineExceeded.Error(), report.Checks["slow"])
+}
+
+func (s *HealthTestSuite) TestReadiness_NotReady_ExpectUnavailable() {
+	// When
+	status, report := s.get(health.ReadinessPath)
+
+	// Then
+	s.Equal(http.StatusServiceUnavailable, status)
+	s.Equal(map[string]string{"runner": health.ErrNotReady.Error()}, report.Checks)
+}
+
+func (s *HealthTestSuite) TestReadiness_Ready_ExpectOK() {
+	// Given
+	s.health.RegisterReadinessCheck("model", func(_ context.Context) error { return nil })
+	s.health.SetReady(true)
+
+	// When
+	status, report := s.get(health.ReadinessPath)
+
+	// Then
+	s.Equal(http.StatusOK, status)
+	s.Equal(health.Report{Status: health.StatusOK}, report)
+}
+
+func (s *HealthTestSuite) TestReadiness_FailingChecks_ExpectLivenessAndReadinessChecksReported() {
+	// Given
+	s.health.RegisterLivenessCheck("nats", func(_ context.Context) error { return errUnreachable })
+	s.health.RegisterReadinessCheck("model", func(_ context.Context) error { return errUnreachable })
+	s.health.SetReady(true)
+
+	// When
+	status, report := s.get(health.ReadinessPath)
+	livenessStatus, _ := s.get(health.LivenessPath)
+
+	// Then
+	s.Equal(http.StatusServiceUnavailable, status)
+	s.Equal(map[string]string{
+		"nats":  errUnreachable.Error(),
+		"model": errUnreachable.Error(),
+	}, report.Checks)
+	s.Equal(http.StatusServiceUnavailable, livenessStatus)
+}
+
+func (s *HealthTestSuite) TestReadiness_FailingReadinessCheck_ExpectLivenessOK() {
+	// Given
+	s.health.RegisterReadinessCheck("model", func(_ context.Context) error { return errUnreachable })
+
+	// When
+	status, _ := s.get(health.LivenessPath)
+
+	// Then
+	s.Equal(http.StatusOK, status)
+}
+
+func (s *HealthTestSuite) get(path string) (int, health.Report) {
+	response, err := http.Get(s.server.URL + path) //nolint:noctx // test server
+	s.Require().NoError(err)
+
+	defer response.Body.Close()
+
+	var report health.Report
+	s.Require().NoError(json.NewDecoder(response.Body).Decode(&report))
+
+	return response.StatusCode, report
+}
+
+func TestHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
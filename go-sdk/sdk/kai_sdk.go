@@ -2,11 +2,17 @@ package sdk
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
 	meta "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/prediction"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 
 	centralizedConfiguration "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/centralized-configuration"
 	objectstore "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/ephemeral-storage"
@@ -18,6 +24,7 @@ import (
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
 	msg "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/messaging"
 	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
@@ -30,9 +37,13 @@ const (
 type messaging interface {
 	SendOutput(response proto.Message, channelOpt ...string) error
 	SendOutputWithRequestID(response proto.Message, requestID string, channelOpt ...string) error
-	SendAny(response *anypb.Any, channelOpt ...string)
-	SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string)
-	SendError(errorMessage string, channelOpt ...string)
+	SendAny(response *anypb.Any, channelOpt ...string) error
+	SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) error
+	SendError(errorMessage string, channelOpt ...string) error
+	SendEndOfStream(channelOpt ...string) error
+	SendOutputAsync(response proto.Message, channelOpt ...string) (nats.PubAckFuture, error)
+	SendAnyAsync(response *anypb.Any, channelOpt ...string) (nats.PubAckFuture, error)
+	Flush(ctx context.Context) error
 	GetErrorMessage() string
 	GetRequestID(msg *nats.Msg) (string, error)
 
@@ -88,6 +99,15 @@ type centralizedConfig interface {
 //go:generate mockery --name measurements --output ../mocks --filename measurements_mock.go --structname MeasurementsMock
 type measurements interface {
 	GetMetricsClient() metric.Meter
+	Flush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
+}
+
+//go:generate mockery --name tracer --output ../mocks --filename tracer_mock.go --structname TracerMock
+type tracer interface {
+	GetTracer() trace.Tracer
+	Flush(ctx context.Context) error
+	Shutdown(ctx context.Context) error
 }
 
 //go:generate mockery --name predictions --output ../mocks --filename predictions_mock.go --structname PredictionsMock
@@ -108,6 +128,12 @@ type modelRegistry interface {
 	DeleteModel(name string) error
 }
 
+//go:generate mockery --name healthChecks --output ../mocks --filename health_checks_mock.go --structname HealthChecksMock
+type healthChecks interface {
+	RegisterLivenessCheck(name string, check health.Check)
+	RegisterReadinessCheck(name string, check health.Check)
+}
+
 type KaiSDK struct {
 	// Metadata
 	ctx context.Context
@@ -116,6 +142,7 @@ type KaiSDK struct {
 	nats           *nats.Conn
 	jetstream      nats.JetStreamContext
 	requestMessage *kai.KaiNatsMessage
+	config         *viper.Viper
 
 	// Main methods
 	Logger            logr.Logger
@@ -124,68 +151,161 @@ type KaiSDK struct {
 	ModelRegistry     modelRegistry
 	CentralizedConfig centralizedConfig
 	Measurements      measurements
+	Tracing           tracer
 	Storage           Storage
 	Predictions       predictions
+	Health            healthChecks
 }
 
+// NewKaiSDK creates a KaiSDK reading the global configuration. It exits the process when a
+// component cannot be initialized, use New to handle the error instead.
 func NewKaiSDK(logger logr.Logger, natsCli *nats.Conn, jetstreamCli nats.JetStreamContext) KaiSDK {
-	metadata := meta.New()
+	return NewKaiSDKWithConfig(logger, natsCli, jetstreamCli, nil)
+}
 
-	centralizedConfigInst, err := centralizedConfiguration.New(logger, jetstreamCli)
+// NewKaiSDKWithConfig creates a KaiSDK whose components read from the given viper instance
+// instead of the global one, so several processes can share the same Go process. It exits the
+// process when a component cannot be initialized, use New to handle the error instead.
+func NewKaiSDKWithConfig(logger logr.Logger, natsCli *nats.Conn, jetstreamCli nats.JetStreamContext,
+	config *viper.Viper,
+) KaiSDK {
+	sdk, err := New(
+		WithLogger(logger),
+		WithNatsConnection(natsCli),
+		WithJetStream(jetstreamCli),
+		WithViper(config),
+	)
 	if err != nil {
-		logger.WithName("[CENTRALIZED CONFIGURATION]").
-			Error(err, "Error initializing Centralized Configuration")
+		logger.Error(err, "Error initializing the SDK")
 		os.Exit(1)
 	}
 
-	ephemeralStg, err := objectstore.New(logger, jetstreamCli)
+	return sdk
+}
+
+// New creates a KaiSDK with the given options. Every component is initialized even when another
+// one fails, and all the errors found are returned joined.
+func New(opts ...Option) (KaiSDK, error) {
+	options := newOptions(opts...)
+
+	logger := options.logger
+	config := options.config
+
+	natsCli, jetstreamCli, err := options.getConnections()
 	if err != nil {
-		logger.WithName("[EPHEMERAL STORAGE]").Error(err, "Error initializing ephemeral storage")
-		os.Exit(1)
+		return KaiSDK{}, err
 	}
 
-	persistentStg, err := persistentstorage.New(logger, metadata)
+	metadata := meta.NewWithConfig(config)
+
+	var errs []error
+
+	centralizedConfigInst, err := centralizedConfiguration.NewWithConfig(logger, jetstreamCli, config)
 	if err != nil {
-		logger.WithName("[PERSISTENT STORAGE]").Error(err, "Error initializing persistent storage")
-		os.Exit(1)
+		errs = append(errs, fmt.Errorf("error initializing centralized configuration: %w", err))
 	}
 
-	storageManager := Storage{
-		Ephemeral:  ephemeralStg,
-		Persistent: persistentStg,
+	ephemeralStg, err := objectstore.NewWithConfig(logger, jetstreamCli, config)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("error initializing ephemeral storage: %w", err))
 	}
 
-	predictionStore := prediction.NewRedisPredictionStore("")
+	var persistentStg persistentStorage = disabledPersistentStorage{}
 
-	messagingInst := msg.New(logger, natsCli, jetstreamCli, nil)
+	if common.IsEnabled(config, common.ConfigMinioEnabledKey) {
+		persistentStg, err = persistentstorage.NewWithConfig(logger, metadata, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error initializing persistent storage: %w", err))
+		}
+	}
 
-	modelRegistryInst, err := modelregistry.New(logger, metadata)
-	if err != nil {
-		logger.WithName("[MODEL REGISTRY]").Error(err, "Error initializing model registry")
-		os.Exit(1)
+	var modelRegistryInst modelRegistry = disabledModelRegistry{}
+
+	if common.IsEnabled(config, common.ConfigModelRegistryEnabledKey) {
+		modelRegistryInst, err = modelregistry.NewWithConfig(logger, metadata, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error initializing model registry: %w", err))
+		}
 	}
 
-	measurementsInst, err := measurement.New(logger, metadata)
-	if err != nil {
-		logger.WithName("[MEASUREMENTS]").Error(err, "Error initializing measurements")
-		os.Exit(1)
+	// The exporters already started are shut down when the SDK cannot be created.
+	var exporters []exporter
+
+	var measurementsInst measurements = disabledMeasurements{}
+
+	if common.IsEnabled(config, common.ConfigMeasurementsEnabledKey) {
+		measurementsInst, err = measurement.NewWithConfig(logger, metadata, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error initializing measurements: %w", err))
+		} else {
+			exporters = append(exporters, measurementsInst)
+		}
+	}
+
+	var tracingInst tracer = disabledTracing{}
+
+	if common.ConfigOrGlobal(config).GetBool(common.ConfigTracingEnabledKey) {
+		tracingInst, err = tracing.NewWithConfig(logger, metadata, config)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error initializing tracing: %w", err))
+		} else {
+			exporters = append(exporters, tracingInst)
+		}
+	}
+
+	if len(errs) > 0 {
+		shutdownExporters(logger, exporters)
+
+		if options.dialsConnection() {
+			natsCli.Close()
+		}
+
+		return KaiSDK{}, errors.Join(errs...)
 	}
 
+	healthInst := health.New(common.ConfigOrGlobal(config).GetDuration(common.ConfigRunnerHealthTimeoutKey))
+	registerHealthChecks(healthInst, config, persistentStg)
+
 	sdk := KaiSDK{
-		ctx:               context.Background(),
-		nats:              natsCli,
-		jetstream:         jetstreamCli,
-		Logger:            logger,
-		Metadata:          metadata,
-		Messaging:         messagingInst,
-		Storage:           storageManager,
+		ctx:       context.Background(),
+		nats:      natsCli,
+		jetstream: jetstreamCli,
+		config:    config,
+		Logger:    logger,
+		Metadata:  metadata,
+		Messaging: msg.NewWithConfig(logger, natsCli, jetstreamCli, nil, config),
+		Storage: Storage{
+			Ephemeral:  ephemeralStg,
+			Persistent: persistentStg,
+		},
 		ModelRegistry:     modelRegistryInst,
 		CentralizedConfig: centralizedConfigInst,
 		Measurements:      measurementsInst,
-		Predictions:       predictionStore,
+		Tracing:           tracingInst,
+		Predictions:       newPredictions("", config),
+		Health:            healthInst,
 	}
 
-	return sdk
+	return sdk, nil
+}
+
+// exporter is a component exporting telemetry, which must be shut down to release it.
+type exporter interface {
+	Shutdown(ctx context.Context) error
+}
+
+// shutdownExporters stops the given exporters, e.g. the Prometheus endpoint of the measurements,
+// so they do not outlive an SDK that could not be created. Nothing was recorded yet, so they are
+// stopped without waiting to export. Errors are only logged.
+func shutdownExporters(logger logr.Logger, exporters []exporter) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, exp := range exporters {
+		if err := exp.Shutdown(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error(err, "Error shutting down exporter")
+		}
+	}
 }
 
 func (sdk *KaiSDK) GetRequestID() string {
@@ -196,12 +316,87 @@ func (sdk *KaiSDK) GetRequestID() string {
 	return sdk.requestMessage.GetRequestId()
 }
 
+// Context returns the context of the message being processed, which is cancelled when its handler
+// times out or the runner shuts down. Outside a handler, it is never cancelled.
+func (sdk *KaiSDK) Context() context.Context {
+	if sdk.ctx == nil {
+		return context.Background()
+	}
+
+	return sdk.ctx
+}
+
+// WithContext returns a copy of the SDK whose context is the given one. The requests of the
+// persistent storage, model registry and predictions are cancelled with it, and the messages sent
+// carry its deadline.
+func (sdk *KaiSDK) WithContext(ctx context.Context) KaiSDK {
+	hSdk := *sdk
+	hSdk.ctx = ctx
+
+	if messagingInst, ok := sdk.Messaging.(*msg.Messaging); ok {
+		hSdk.Messaging = messagingInst.WithContext(ctx)
+	}
+
+	if persistentStg, ok := sdk.Storage.Persistent.(*persistentstorage.PersistentStorage); ok {
+		hSdk.Storage.Persistent = persistentStg.WithContext(ctx)
+	}
+
+	if modelRegistryInst, ok := sdk.ModelRegistry.(*modelregistry.ModelRegistry); ok {
+		hSdk.ModelRegistry = modelRegistryInst.WithContext(ctx)
+	}
+
+	if predictionStore, ok := sdk.Predictions.(*prediction.RedisPredictionStore); ok {
+		hSdk.Predictions = predictionStore.WithContext(ctx)
+	}
+
+	return hSdk
+}
+
+// WithHandledMsgID returns a copy of the SDK whose messages are identified as sent while handling
+// the message with the given ID, so they are only taken for duplicates of the messages sent when
+// that same message is handled again.
+func (sdk *KaiSDK) WithHandledMsgID(msgID string) KaiSDK {
+	hSdk := *sdk
+
+	if messagingInst, ok := sdk.Messaging.(*msg.Messaging); ok {
+		hSdk.Messaging = messagingInst.WithHandledMsgID(msgID)
+	}
+
+	return hSdk
+}
+
 func ShallowCopyWithRequest(sdk *KaiSDK, requestMsg *kai.KaiNatsMessage) KaiSDK {
 	hSdk := *sdk
 	hSdk.requestMessage = requestMsg
 	hSdk.Logger = sdk.Logger.WithValues(LoggerRequestID, requestMsg.GetRequestId())
-	hSdk.Predictions = prediction.NewRedisPredictionStore(requestMsg.RequestId)
-	hSdk.Messaging = msg.New(hSdk.Logger, sdk.nats, sdk.jetstream, requestMsg)
+	hSdk.Predictions = newPredictions(requestMsg.RequestId, sdk.config)
+	hSdk.Messaging = msg.NewWithConfig(hSdk.Logger, sdk.nats, sdk.jetstream, requestMsg, sdk.config)
 
 	return hSdk
 }
+
+// registerHealthChecks adds the liveness checks of the MinIO and Redis subsystems, when they are
+// enabled and their checks are set in the runner.health.checks configuration.
+func registerHealthChecks(healthInst *health.Health, config *viper.Viper, persistentStg persistentStorage) {
+	cfg := common.ConfigOrGlobal(config)
+
+	if cfg.GetBool(common.ConfigRunnerHealthMinioKey) {
+		if persistentStgInst, ok := persistentStg.(*persistentstorage.PersistentStorage); ok {
+			healthInst.RegisterLivenessCheck("minio", persistentStgInst.Ping)
+		}
+	}
+
+	if cfg.GetBool(common.ConfigRunnerHealthRedisKey) {
+		if predictionStore, ok := newPredictions("", config).(*prediction.RedisPredictionStore); ok {
+			healthInst.RegisterLivenessCheck("redis", predictionStore.Ping)
+		}
+	}
+}
+
+func newPredictions(requestID string, config *viper.Viper) predictions {
+	if !common.IsEnabled(config, common.ConfigRedisEnabledKey) {
+		return disabledPredictions{}
+	}
+
+	return prediction.NewRedisPredictionStoreWithConfig(requestID, config)
+}
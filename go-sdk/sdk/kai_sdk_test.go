@@ -0,0 +1,203 @@
+//go:build unit
+
+package sdk_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/mocks"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+)
+
+type KaiSDKTestSuite struct {
+	suite.Suite
+	config *viper.Viper
+	js     *mocks.JetStreamContextMock
+}
+
+func (s *KaiSDKTestSuite) SetupTest() {
+	s.config = viper.New()
+	s.config.SetConfigName("config")
+	s.config.SetConfigType("yaml")
+	s.config.AddConfigPath("../../testdata")
+	s.Require().NoError(s.config.ReadInConfig())
+
+	s.js = mocks.NewJetStreamContextMock(s.T())
+}
+
+func (s *KaiSDKTestSuite) TestNew_ExpectOK() {
+	// Given
+	s.js.On("KeyValue", mock.AnythingOfType("string")).Return(mocks.NewKeyValueMock(s.T()), nil)
+	s.js.On("ObjectStore", mock.AnythingOfType("string")).Return(mocks.NewNatsObjectStoreMock(s.T()), nil)
+
+	// When
+	kaiSDK, err := sdk.New(
+		sdk.WithLogger(testr.New(s.T())),
+		sdk.WithJetStream(s.js),
+		sdk.WithViper(s.config),
+	)
+
+	// Then
+	s.Require().NoError(err)
+	s.NotNil(kaiSDK.CentralizedConfig)
+	s.NotNil(kaiSDK.Storage.Ephemeral)
+	s.Equal(s.config.GetString(common.ConfigMetadataProcessIDKey), kaiSDK.Metadata.GetProcess())
+}
+
+func (s *KaiSDKTestSuite) TestNew_ComponentsFail_ExpectAggregatedError() {
+	// Given
+	s.js.On("KeyValue", mock.AnythingOfType("string")).Return(nil, errors.New("kv error"))
+	s.js.On("ObjectStore", mock.AnythingOfType("string")).Return(nil, errors.New("object store error"))
+
+	// When
+	_, err := sdk.New(
+		sdk.WithLogger(testr.New(s.T())),
+		sdk.WithJetStream(s.js),
+		sdk.WithViper(s.config),
+	)
+
+	// Then
+	s.ErrorContains(err, "error initializing centralized configuration")
+	s.ErrorContains(err, "error initializing ephemeral storage")
+}
+
+func (s *KaiSDKTestSuite) TestNew_ComponentsFail_ExpectPrometheusEndpointReleased() {
+	// Given
+	s.js.On("KeyValue", mock.AnythingOfType("string")).Return(nil, errors.New("kv error"))
+	s.js.On("ObjectStore", mock.AnythingOfType("string")).Return(mocks.NewNatsObjectStoreMock(s.T()), nil)
+
+	address := freeAddress(s.T())
+	s.config.Set(common.ConfigMeasurementsExporterKey, "prometheus")
+	s.config.Set(common.ConfigMeasurementsPrometheusAddressKey, address)
+
+	// When
+	_, err := sdk.New(
+		sdk.WithLogger(testr.New(s.T())),
+		sdk.WithJetStream(s.js),
+		sdk.WithViper(s.config),
+	)
+
+	// Then
+	s.Require().ErrorContains(err, "error initializing centralized configuration")
+
+	s.Eventually(func() bool {
+		listener, err := net.Listen("tcp", address)
+		if err != nil {
+			return false
+		}
+
+		return listener.Close() == nil
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *KaiSDKTestSuite) TestNew_ComponentsFail_ExpectOwnConnectionClosed() {
+	// Given
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      server.RANDOM_PORT,
+		JetStream: true,
+		StoreDir:  s.T().TempDir(),
+	})
+	s.Require().NoError(err)
+
+	go srv.Start()
+	defer srv.Shutdown()
+
+	s.Require().True(srv.ReadyForConnections(5 * time.Second))
+
+	s.config.Set(common.ConfigNatsURLKey, srv.ClientURL())
+
+	// When
+	_, err = sdk.New(sdk.WithLogger(testr.New(s.T())), sdk.WithViper(s.config))
+
+	// Then
+	s.Require().Error(err)
+	s.Eventually(func() bool {
+		return srv.NumClients() == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func (s *KaiSDKTestSuite) TestNew_DisabledSubsystems_ExpectErrSubsystemDisabled() {
+	// Given
+	s.js.On("KeyValue", mock.AnythingOfType("string")).Return(mocks.NewKeyValueMock(s.T()), nil)
+	s.js.On("ObjectStore", mock.AnythingOfType("string")).Return(mocks.NewNatsObjectStoreMock(s.T()), nil)
+
+	s.config.Set(common.ConfigMinioEnabledKey, false)
+	s.config.Set(common.ConfigModelRegistryEnabledKey, false)
+	s.config.Set(common.ConfigRedisEnabledKey, false)
+	s.config.Set(common.ConfigMeasurementsEnabledKey, false)
+
+	kaiSDK, err := sdk.New(
+		sdk.WithLogger(testr.New(s.T())),
+		sdk.WithJetStream(s.js),
+		sdk.WithViper(s.config),
+	)
+	s.Require().NoError(err)
+
+	// When
+	_, persistentErr := kaiSDK.Storage.Persistent.List()
+	_, modelRegistryErr := kaiSDK.ModelRegistry.ListModels()
+	predictionsErr := kaiSDK.Predictions.Delete(context.Background(), "some-prediction")
+
+	// Then
+	s.ErrorIs(persistentErr, sdk.ErrSubsystemDisabled)
+	s.ErrorIs(modelRegistryErr, sdk.ErrSubsystemDisabled)
+	s.ErrorIs(predictionsErr, sdk.ErrSubsystemDisabled)
+	s.NotNil(kaiSDK.Measurements.GetMetricsClient())
+}
+
+func (s *KaiSDKTestSuite) TestNew_UnreachableNats_ExpectError() {
+	// Given
+	s.config.Set(common.ConfigNatsURLKey, "nats://127.0.0.1:1")
+
+	// When
+	_, err := sdk.New(sdk.WithViper(s.config))
+
+	// Then
+	s.ErrorIs(err, nats.ErrNoServers)
+}
+
+func (s *KaiSDKTestSuite) TestWithContext_ExpectContextSet() {
+	// Given
+	kaiSDK := sdk.KaiSDK{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	hSdk := kaiSDK.WithContext(ctx)
+
+	// Then
+	s.Equal(context.Background(), kaiSDK.Context())
+	s.ErrorIs(hSdk.Context().Err(), context.Canceled)
+}
+
+// freeAddress returns a local address no one is listening on.
+func freeAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func TestKaiSDKTestSuite(t *testing.T) {
+	suite.Run(t, new(KaiSDKTestSuite))
+}
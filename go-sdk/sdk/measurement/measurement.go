@@ -1,7 +1,10 @@
 package measurement
 
 import (
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"time"
 
 	"context"
@@ -9,9 +12,14 @@ import (
 	"github.com/go-logr/logr"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
+	prometheusClient "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
 	"go.opentelemetry.io/otel/metric"
 	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
 	"go.opentelemetry.io/otel/sdk/resource"
@@ -20,29 +28,72 @@ import (
 
 const (
 	_persistentStorageLoggerName = "[MEASUREMENTS]"
+
+	_metricsPath        = "/metrics"
+	_metricsReadTimeout = 5 * time.Second
+	_defaultPromAddress = ":9464"
 )
 
+// Exporters of the measurements.exporter configuration.
+const (
+	ExporterOTLPGRPC   = "otlp-grpc"
+	ExporterOTLPHTTP   = "otlp-http"
+	ExporterPrometheus = "prometheus"
+	ExporterStdout     = "stdout"
+	ExporterNone       = "none"
+)
+
+var ErrUnknownExporter = errors.New("unknown metrics exporter")
+
 type Measurement struct {
 	logger        logr.Logger
+	provider      *sdkMetric.MeterProvider
 	metricsClient metric.Meter
 	metadata      *metadata.Metadata
+	server        *http.Server
 }
 
 func New(logger logr.Logger, meta *metadata.Metadata) (*Measurement, error) {
-	endpoint := viper.GetString(common.ConfigMeasurementsEndpointKey)
-	insecure := viper.GetBool(common.ConfigMeasurementsInsecureKey)
-	timeout := viper.GetInt(common.ConfigMeasurementsTimeoutKey)
-	interval := viper.GetInt(common.ConfigMeasurementsMetricsIntervalKey)
+	return NewWithConfig(logger, meta, nil)
+}
+
+// NewWithConfig creates a Measurement reading from the given viper instance instead of the global one.
+// Metrics are pushed to an OTLP collector through gRPC unless another measurements.exporter is set:
+// otlp-http, prometheus, which serves them on measurements.prometheus.address to be scraped,
+// stdout or none.
+func NewWithConfig(logger logr.Logger, meta *metadata.Metadata, config *viper.Viper) (*Measurement, error) {
+	cfg := common.ConfigOrGlobal(config)
 
-	metricsClient, err := initMetrics(logger, endpoint, insecure, timeout, interval, meta)
+	res, err := initResource(meta)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("error initializing metrics: %w", err)
 	}
 
+	exporter := getExporterName(cfg)
+
+	reader, server, err := initReader(exporter, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing metrics: %w", err)
+	}
+
+	if server != nil {
+		if err := startServer(logger, server); err != nil {
+			return nil, fmt.Errorf("error initializing metrics: %w", err)
+		}
+	}
+
+	provider := initProvider(reader, res)
+
+	otel.SetMeterProvider(provider)
+
+	logger.WithName(_persistentStorageLoggerName).Info(fmt.Sprintf("Successfully initialized metrics with the %s exporter", exporter))
+
 	return &Measurement{
 		logger:        logger,
-		metricsClient: metricsClient,
+		provider:      provider,
+		metricsClient: provider.Meter("measurements"),
 		metadata:      meta,
+		server:        server,
 	}, nil
 }
 
@@ -50,24 +101,136 @@ func (m Measurement) GetMetricsClient() metric.Meter {
 	return m.metricsClient
 }
 
-func initMetrics(logger logr.Logger, endpoint string, insecure bool, timeout, interval int, meta *metadata.Metadata) (metric.Meter, error) {
-	res, err := initResource(meta)
-	if err != nil {
-		return nil, fmt.Errorf("error initializing metrics: %w", err)
+// Flush exports the metrics recorded so far without waiting for the next export interval.
+func (m Measurement) Flush(ctx context.Context) error {
+	return m.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the metrics recorded so far and stops the exporter, including the Prometheus
+// endpoint. Metrics recorded afterwards are dropped.
+func (m Measurement) Shutdown(ctx context.Context) error {
+	err := m.provider.Shutdown(ctx)
+
+	if m.server != nil {
+		err = errors.Join(err, m.server.Shutdown(ctx))
 	}
 
-	exporter, err := initExporter(endpoint, insecure, timeout)
+	return err
+}
+
+func getExporterName(cfg *viper.Viper) string {
+	if exporter := cfg.GetString(common.ConfigMeasurementsExporterKey); exporter != "" {
+		return exporter
+	}
+
+	return ExporterOTLPGRPC
+}
+
+// initReader returns the reader of the given exporter and, for the Prometheus one, the server
+// serving the metrics endpoint. The none exporter has no reader.
+func initReader(exporter string, cfg *viper.Viper) (sdkMetric.Reader, *http.Server, error) {
+	endpoint := cfg.GetString(common.ConfigMeasurementsEndpointKey)
+	insecure := cfg.GetBool(common.ConfigMeasurementsInsecureKey)
+	timeout := time.Duration(cfg.GetInt(common.ConfigMeasurementsTimeoutKey)) * time.Second
+	interval := time.Duration(cfg.GetInt(common.ConfigMeasurementsMetricsIntervalKey)) * time.Second
+
+	switch exporter {
+	case ExporterOTLPGRPC:
+		metricExporter, err := initGRPCExporter(endpoint, insecure, timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return initPeriodicReader(metricExporter, interval), nil, nil
+	case ExporterOTLPHTTP:
+		metricExporter, err := initHTTPExporter(endpoint, insecure, timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return initPeriodicReader(metricExporter, interval), nil, nil
+	case ExporterStdout:
+		metricExporter, err := stdoutmetric.New()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return initPeriodicReader(metricExporter, interval), nil, nil
+	case ExporterPrometheus:
+		return initPrometheusReader(cfg.GetString(common.ConfigMeasurementsPrometheusAddressKey))
+	case ExporterNone:
+		return nil, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownExporter, exporter)
+	}
+}
+
+func initGRPCExporter(endpoint string, insecure bool, timeout time.Duration) (sdkMetric.Exporter, error) {
+	opts := []otlpmetricgrpc.Option{
+		otlpmetricgrpc.WithEndpoint(endpoint),
+		otlpmetricgrpc.WithTimeout(timeout),
+	}
+
+	if insecure {
+		opts = append(opts, otlpmetricgrpc.WithInsecure())
+	}
+
+	return otlpmetricgrpc.New(context.Background(), opts...)
+}
+
+func initHTTPExporter(endpoint string, insecure bool, timeout time.Duration) (sdkMetric.Exporter, error) {
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpoint(endpoint),
+		otlpmetrichttp.WithTimeout(timeout),
+	}
+
+	if insecure {
+		opts = append(opts, otlpmetrichttp.WithInsecure())
+	}
+
+	return otlpmetrichttp.New(context.Background(), opts...)
+}
+
+// initPrometheusReader registers the metrics in a registry of their own, so several processes can
+// share the same Go process, and serves them on the given address.
+func initPrometheusReader(address string) (sdkMetric.Reader, *http.Server, error) {
+	registry := prometheusClient.NewRegistry()
+
+	reader, err := prometheus.New(prometheus.WithRegisterer(registry))
 	if err != nil {
-		return nil, fmt.Errorf("error initializing metrics: %w", err)
+		return nil, nil, err
 	}
 
-	provider := initProvider(exporter, res, interval)
+	if address == "" {
+		address = _defaultPromAddress
+	}
 
-	otel.SetMeterProvider(provider)
+	mux := http.NewServeMux()
+	mux.Handle(_metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
 
-	logger.WithName(_persistentStorageLoggerName).Info("Successfully initialized metrics")
+	return reader, &http.Server{
+		Addr:              address,
+		Handler:           mux,
+		ReadHeaderTimeout: _metricsReadTimeout,
+	}, nil
+}
 
-	return provider.Meter("measurements"), nil
+func startServer(logger logr.Logger, server *http.Server) error {
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", server.Addr, err)
+	}
+
+	logger.WithName(_persistentStorageLoggerName).V(1).
+		Info(fmt.Sprintf("Serving metrics on %s%s", listener.Addr(), _metricsPath))
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.WithName(_persistentStorageLoggerName).Error(err, "Error serving metrics")
+		}
+	}()
+
+	return nil
 }
 
 func initResource(meta *metadata.Metadata) (*resource.Resource, error) {
@@ -82,31 +245,16 @@ func initResource(meta *metadata.Metadata) (*resource.Resource, error) {
 	)
 }
 
-func initExporter(endpoint string, insecure bool, timeout int) (*otlpmetricgrpc.Exporter, error) {
-	if insecure {
-		return otlpmetricgrpc.New(
-			context.Background(),
-			otlpmetricgrpc.WithEndpoint(endpoint),
-			otlpmetricgrpc.WithTimeout(time.Duration(timeout)*time.Second),
-			otlpmetricgrpc.WithInsecure(),
-		)
-	}
-
-	return otlpmetricgrpc.New(
-		context.Background(),
-		otlpmetricgrpc.WithEndpoint(endpoint),
-		otlpmetricgrpc.WithTimeout(time.Duration(timeout)*time.Second),
-	)
+func initPeriodicReader(exporter sdkMetric.Exporter, interval time.Duration) sdkMetric.Reader {
+	return sdkMetric.NewPeriodicReader(exporter, sdkMetric.WithInterval(interval))
 }
 
-func initProvider(exporter *otlpmetricgrpc.Exporter, res *resource.Resource, interval int) *sdkMetric.MeterProvider {
-	return sdkMetric.NewMeterProvider(
-		sdkMetric.WithResource(res),
-		sdkMetric.WithReader(
-			sdkMetric.NewPeriodicReader(
-				exporter,
-				sdkMetric.WithInterval(time.Duration(interval)*time.Second),
-			),
-		),
-	)
+func initProvider(reader sdkMetric.Reader, res *resource.Resource) *sdkMetric.MeterProvider {
+	opts := []sdkMetric.Option{sdkMetric.WithResource(res)}
+
+	if reader != nil {
+		opts = append(opts, sdkMetric.WithReader(reader))
+	}
+
+	return sdkMetric.NewMeterProvider(opts...)
 }
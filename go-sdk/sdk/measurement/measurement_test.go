@@ -3,6 +3,10 @@
 package measurement_test
 
 import (
+	"context"
+	"io"
+	"net"
+	"net/http"
 	"testing"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
@@ -56,6 +60,95 @@ func (s *SdkMeasurementTestSuite) TestMetadata_GetMetadata_ExpectOK() {
 	s.Nil(err)
 }
 
+func (s *SdkMeasurementTestSuite) TestNewWithConfig_OTLPHTTPExporter_ExpectOK() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigMeasurementsExporterKey, measurement.ExporterOTLPHTTP)
+	config.Set(common.ConfigMeasurementsEndpointKey, "localhost:4318")
+	config.Set(common.ConfigMeasurementsInsecureKey, true)
+
+	// When
+	sdkMeasurement, err := measurement.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+
+	// Then
+	s.Require().NoError(err)
+	s.NotNil(sdkMeasurement.GetMetricsClient())
+}
+
+func (s *SdkMeasurementTestSuite) TestNewWithConfig_NoneExporter_ExpectMeterWorking() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigMeasurementsExporterKey, measurement.ExporterNone)
+
+	// When
+	sdkMeasurement, err := measurement.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+
+	// Then
+	s.Require().NoError(err)
+
+	counter, err := sdkMeasurement.GetMetricsClient().Int64Counter("test-counter")
+	s.Require().NoError(err)
+	counter.Add(context.Background(), 1)
+
+	s.NoError(sdkMeasurement.Flush(context.Background()))
+	s.NoError(sdkMeasurement.Shutdown(context.Background()))
+}
+
+func (s *SdkMeasurementTestSuite) TestNewWithConfig_UnknownExporter_ExpectError() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigMeasurementsExporterKey, "unknown")
+
+	// When
+	_, err := measurement.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+
+	// Then
+	s.ErrorIs(err, measurement.ErrUnknownExporter)
+}
+
+func (s *SdkMeasurementTestSuite) TestNewWithConfig_PrometheusExporter_ExpectMetricsServed() {
+	// Given
+	address := s.getFreeAddress()
+
+	config := viper.New()
+	config.Set(common.ConfigMeasurementsExporterKey, measurement.ExporterPrometheus)
+	config.Set(common.ConfigMeasurementsPrometheusAddressKey, address)
+
+	sdkMeasurement, err := measurement.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+	s.Require().NoError(err)
+
+	counter, err := sdkMeasurement.GetMetricsClient().Int64Counter("test-counter")
+	s.Require().NoError(err)
+
+	// When
+	counter.Add(context.Background(), 3)
+
+	// Then
+	response, err := http.Get("http://" + address + "/metrics") //nolint:noctx // test server
+	s.Require().NoError(err)
+
+	body, err := io.ReadAll(response.Body)
+	s.Require().NoError(err)
+	s.Require().NoError(response.Body.Close())
+
+	s.Equal(http.StatusOK, response.StatusCode)
+	s.Contains(string(body), "test_counter_total")
+
+	s.Require().NoError(sdkMeasurement.Shutdown(context.Background()))
+
+	_, err = http.Get("http://" + address + "/metrics") //nolint:noctx // test server
+	s.Error(err)
+}
+
+func (s *SdkMeasurementTestSuite) getFreeAddress() string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().NoError(err)
+
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
 func TestSdkMetadataTestSuite(t *testing.T) {
 	suite.Run(t, new(SdkMeasurementTestSuite))
 }
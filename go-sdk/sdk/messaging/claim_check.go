@@ -0,0 +1,70 @@
+package messaging
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+)
+
+// isClaimCheckEnabled tells whether messages too big to be sent are offloaded to the ephemeral
+// storage, which is the case unless nats.claim_check.enabled is false or there is no such storage.
+func (ms Messaging) isClaimCheckEnabled() bool {
+	return common.IsEnabled(ms.config, common.ConfigNatsClaimCheckEnabledKey) &&
+		common.ConfigOrGlobal(ms.config).GetString(common.ConfigNatsEphemeralStorage) != ""
+}
+
+// offloadMessage stores the given serialized message in the ephemeral storage and returns the
+// message to send instead, with its payload left out, and its header, referencing the object.
+// The object expires after nats.claim_check.ttl, if set.
+func (ms Messaging) offloadMessage(responseMsg *kai.KaiNatsMessage, data []byte,
+	header nats.Header,
+) ([]byte, nats.Header, error) {
+	cfg := common.ConfigOrGlobal(ms.config)
+	bucket := cfg.GetString(common.ConfigNatsEphemeralStorage)
+
+	objectStore, err := ms.jetstream.ObjectStore(bucket)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting the ephemeral storage with name %s: %w", bucket, err)
+	}
+
+	meta := &nats.ObjectMeta{
+		Name:    fmt.Sprintf("%s%s/%s", common.ClaimCheckObjectPrefix, responseMsg.GetRequestId(), uuid.New()),
+		Headers: nats.Header{},
+	}
+
+	if ttl := cfg.GetDuration(common.ConfigNatsClaimCheckTTLKey); ttl > 0 {
+		meta.Headers.Set(common.ClaimCheckExpiresHeader, time.Now().Add(ttl).UTC().Format(time.RFC3339Nano))
+	}
+
+	if _, err := objectStore.Put(meta, bytes.NewReader(data)); err != nil {
+		return nil, nil, fmt.Errorf("error storing message to the ephemeral storage with name %s: %w", bucket, err)
+	}
+
+	claimCheckMsg, err := proto.Marshal(&kai.KaiNatsMessage{
+		RequestId:   responseMsg.GetRequestId(),
+		FromNode:    responseMsg.GetFromNode(),
+		MessageType: responseMsg.GetMessageType(),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if header == nil {
+		header = nats.Header{}
+	}
+
+	header.Set(common.ClaimCheckHeader, meta.Name)
+
+	ms.logger.WithName(_messagingLoggerName).
+		Info(fmt.Sprintf("Message of size %s offloaded to the ephemeral storage with name %s as %s",
+			sizeInMB(int64(len(data))), bucket, meta.Name))
+
+	return claimCheckMsg, header, nil
+}
@@ -17,5 +17,10 @@ func NewTestMessaging(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContex
 		js,
 		requestMessage,
 		messagingUtils,
+		nil,
+		nil,
+		&pendingPublishes{},
+		&msgSequences{},
+		"",
 	}
 }
@@ -1,9 +1,16 @@
 package messaging
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
 	"github.com/go-logr/logr"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
 	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 )
@@ -16,20 +23,98 @@ type Messaging struct {
 	jetstream      nats.JetStreamContext
 	requestMessage *kai.KaiNatsMessage
 	messagingUtils messagingUtils
+	config         *viper.Viper
+	ctx            context.Context
+	pending        *pendingPublishes
+	sequences      *msgSequences
+	handledMsgID   string
+}
+
+// pendingPublishes holds the acknowledgements of the messages published asynchronously that were
+// not flushed yet. It is shared by the copies of a Messaging.
+type pendingPublishes struct {
+	mu      sync.Mutex
+	futures []nats.PubAckFuture
+}
+
+func (p *pendingPublishes) add(futures ...nats.PubAckFuture) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.futures = append(p.futures, futures...)
+}
+
+func (p *pendingPublishes) take() []nats.PubAckFuture {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	futures := p.futures
+	p.futures = nil
+
+	return futures
+}
+
+// msgSequences counts the messages sent to each channel, so each of them gets a deterministic
+// message ID. It is shared by the copies of a Messaging, and a new one is used for every message
+// handled, so a handler run again for the same message sends the same IDs.
+type msgSequences struct {
+	mu   sync.Mutex
+	next map[string]int
+}
+
+func (s *msgSequences) nextFor(channel string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next == nil {
+		s.next = make(map[string]int)
+	}
+
+	seq := s.next[channel]
+	s.next[channel] = seq + 1
+
+	return seq
 }
 
 func New(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext,
 	requestMessage *kai.KaiNatsMessage,
+) *Messaging {
+	return NewWithConfig(logger, ns, js, requestMessage, nil)
+}
+
+// NewWithConfig creates a Messaging reading from the given viper instance instead of the global one.
+func NewWithConfig(logger logr.Logger, ns *nats.Conn, js nats.JetStreamContext,
+	requestMessage *kai.KaiNatsMessage, config *viper.Viper,
 ) *Messaging {
 	return &Messaging{
 		logger,
 		ns,
 		js,
 		requestMessage,
-		NewMessagingUtils(ns, js),
+		NewMessagingUtils(ns, js, config),
+		config,
+		nil,
+		&pendingPublishes{},
+		&msgSequences{},
+		"",
 	}
 }
 
+// WithContext returns a copy of the Messaging whose messages carry the deadline of the given
+// context, if any, to the next processes. Once the context is done, messages are no longer sent.
+func (ms Messaging) WithContext(ctx context.Context) *Messaging {
+	ms.ctx = ctx
+	return &ms
+}
+
+// WithHandledMsgID returns a copy of the Messaging whose messages are identified as sent while
+// handling the message with the given ID, so the outputs of different messages of the same
+// request are not taken for duplicates.
+func (ms Messaging) WithHandledMsgID(msgID string) *Messaging {
+	ms.handledMsgID = msgID
+	return &ms
+}
+
 func (ms Messaging) SendOutput(response proto.Message, channelOpt ...string) error {
 	return ms.publishMsg(response, ms.requestMessage.GetRequestId(), kai.MessageType_OK, ms.getOptionalString(channelOpt))
 }
@@ -38,16 +123,63 @@ func (ms Messaging) SendOutputWithRequestID(response proto.Message, requestID st
 	return ms.publishMsg(response, requestID, kai.MessageType_OK, ms.getOptionalString(channelOpt))
 }
 
-func (ms Messaging) SendAny(response *anypb.Any, channelOpt ...string) {
-	ms.publishAny(response, ms.requestMessage.GetRequestId(), kai.MessageType_OK, ms.getOptionalString(channelOpt))
+func (ms Messaging) SendAny(response *anypb.Any, channelOpt ...string) error {
+	return ms.publishAny(response, ms.requestMessage.GetRequestId(), kai.MessageType_OK, ms.getOptionalString(channelOpt))
 }
 
-func (ms Messaging) SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) {
-	ms.publishAny(response, requestID, kai.MessageType_OK, ms.getOptionalString(channelOpt))
+func (ms Messaging) SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) error {
+	return ms.publishAny(response, requestID, kai.MessageType_OK, ms.getOptionalString(channelOpt))
+}
+
+func (ms Messaging) SendError(errorMessage string, channelOpt ...string) error {
+	return ms.publishError(ms.requestMessage.GetRequestId(), errorMessage, ms.getOptionalString(channelOpt))
+}
+
+// SendEndOfStream tells the trigger streaming the messages of the current request that no more
+// messages will follow. It is meant to be sent by exit processes after their last output.
+func (ms Messaging) SendEndOfStream(channelOpt ...string) error {
+	responseMsg := ms.newResponseMsg(nil, ms.requestMessage.GetRequestId(), kai.MessageType_OK)
+
+	return ms.publishResponseWithHeader(responseMsg, ms.getOptionalString(channelOpt), nats.Header{
+		common.EndOfStreamHeader: []string{"true"},
+	})
 }
 
-func (ms Messaging) SendError(errorMessage string, channelOpt ...string) {
-	ms.publishError(ms.requestMessage.GetRequestId(), errorMessage, ms.getOptionalString(channelOpt))
+// SendOutputAsync works like SendOutput without waiting for the acknowledgement of NATS, which is
+// delivered through the returned future. Errors preparing the message are returned right away.
+func (ms Messaging) SendOutputAsync(response proto.Message, channelOpt ...string) (nats.PubAckFuture, error) {
+	return ms.publishMsgAsync(response, ms.requestMessage.GetRequestId(), kai.MessageType_OK,
+		ms.getOptionalString(channelOpt))
+}
+
+// SendAnyAsync works like SendAny without waiting for the acknowledgement of NATS, which is
+// delivered through the returned future. Errors preparing the message are returned right away.
+func (ms Messaging) SendAnyAsync(response *anypb.Any, channelOpt ...string) (nats.PubAckFuture, error) {
+	return ms.publishAnyAsync(response, ms.requestMessage.GetRequestId(), kai.MessageType_OK,
+		ms.getOptionalString(channelOpt))
+}
+
+// Flush waits until every message sent asynchronously since the last flush is acknowledged and
+// returns the errors of those that failed. When the context is done first, its error is returned
+// along with them, and the messages not acknowledged yet are waited for by the next flush.
+func (ms Messaging) Flush(ctx context.Context) error {
+	futures := ms.pending.take()
+
+	var errs []error
+
+	for i, future := range futures {
+		select {
+		case <-future.Ok():
+		case err := <-future.Err():
+			errs = append(errs, fmt.Errorf("error publishing output to subject %s: %w", future.Msg().Subject, err))
+		case <-ctx.Done():
+			ms.pending.add(futures[i:]...)
+
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
 func (ms Messaging) GetErrorMessage() string {
@@ -0,0 +1,129 @@
+//go:build unit
+
+package messaging_test
+
+import (
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/messaging"
+)
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_ExpectMsgIDPerChannel() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsDeduplicationEnabledKey, true)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	var msgIDs []string
+
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).
+		Run(func(args mock.Arguments) {
+			msgIDs = append(msgIDs, args.Get(0).(*nats.Msg).Header.Get(nats.MsgIdHdr))
+		}).
+		Return(&nats.PubAck{}, nil)
+
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
+		&kai.KaiNatsMessage{RequestId: "123"}, &s.messagingUtils)
+	msg := &wrappers.StringValue{Value: stringValueMessage}
+
+	// When
+	s.Require().NoError(messagingInst.SendOutput(msg))
+	s.Require().NoError(messagingInst.SendOutput(msg))
+	s.Require().NoError(messagingInst.SendOutput(msg, "subtopic"))
+	s.Require().NoError(messagingInst.SendOutputWithRequestID(msg, "456"))
+
+	// Then
+	s.Equal([]string{
+		"123/parent-node///0",
+		"123/parent-node///1",
+		"123/parent-node//subtopic/0",
+		"456/parent-node///2",
+	}, msgIDs)
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_SameMessageHandledAgain_ExpectSameMsgID() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsDeduplicationEnabledKey, true)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
+
+	request := &kai.KaiNatsMessage{RequestId: "123"}
+	msg := &wrappers.StringValue{Value: stringValueMessage}
+
+	// When
+	first := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, request, &s.messagingUtils).
+		WithHandledMsgID("stream-1")
+	s.Require().NoError(first.SendOutput(msg))
+
+	redelivered := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, request, &s.messagingUtils).
+		WithHandledMsgID("stream-1")
+	s.Require().NoError(redelivered.SendOutput(msg))
+
+	// Then
+	s.jetstream.AssertNumberOfCalls(s.T(), "PublishMsg", 2)
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(natsMsg *nats.Msg) bool {
+		return natsMsg.Header.Get(nats.MsgIdHdr) == "123/parent-node/stream-1//0"
+	}))
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.MatchedBy(func(natsMsg *nats.Msg) bool {
+		return natsMsg.Header.Get(nats.MsgIdHdr) != "123/parent-node/stream-1//0"
+	}))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_OtherMessagesOfSameRequestHandled_ExpectDifferentMsgIDs() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsDeduplicationEnabledKey, true)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	var msgIDs []string
+
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).
+		Run(func(args mock.Arguments) {
+			msgIDs = append(msgIDs, args.Get(0).(*nats.Msg).Header.Get(nats.MsgIdHdr))
+		}).
+		Return(&nats.PubAck{}, nil)
+
+	request := &kai.KaiNatsMessage{RequestId: "123"}
+	msg := &wrappers.StringValue{Value: stringValueMessage}
+
+	// When
+	for _, handledMsgID := range []string{"stream-1", "stream-2"} {
+		messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, request, &s.messagingUtils).
+			WithHandledMsgID(handledMsgID)
+		s.Require().NoError(messagingInst.SendOutput(msg))
+	}
+
+	// Then
+	s.Equal([]string{"123/parent-node/stream-1//0", "123/parent-node/stream-2//0"}, msgIDs)
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_DeduplicationNotEnabled_ExpectNoMsgID() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+	s.jetstream.On("Publish", mock.AnythingOfType("string"), mock.AnythingOfType(unit8Type)).
+		Return(&nats.PubAck{}, nil)
+
+	msg := &wrappers.StringValue{Value: stringValueMessage}
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
+		&kai.KaiNatsMessage{RequestId: "123"}, &s.messagingUtils)
+
+	// When
+	err := messagingInst.SendOutput(msg)
+
+	// Then
+	s.Require().NoError(err)
+	s.jetstream.AssertCalled(s.T(), "Publish", natsOutputValue,
+		getOutputMessage("123", msg, "", metadataProcessIDValue, kai.MessageType_OK))
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.Anything)
+}
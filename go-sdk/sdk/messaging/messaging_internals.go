@@ -1,18 +1,21 @@
 package messaging
 
 import (
+	"errors"
 	"fmt"
+	"time"
 
-	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+	utilErrors "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	"github.com/nats-io/nats.go"
 
 	"github.com/google/uuid"
-	"github.com/spf13/viper"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 )
 
 const (
@@ -33,34 +36,69 @@ func (ms Messaging) publishMsg(msg proto.Message, requestID string, msgType kai.
 		return fmt.Errorf("the handler result is not a valid protobuf: %s", err) //nolint:goerr113 // error is wrapped
 	}
 
+	return ms.publishAny(payload, requestID, msgType, channel)
+}
+
+func (ms Messaging) publishAny(payload *anypb.Any, requestID string, msgType kai.MessageType, channel string) error {
 	if requestID == "" {
 		requestID = uuid.New().String()
 	}
 
 	responseMsg := ms.newResponseMsg(payload, requestID, msgType)
 
-	ms.publishResponse(responseMsg, channel)
+	return ms.publishResponse(responseMsg, channel)
+}
 
-	return nil
+func (ms Messaging) publishMsgAsync(msg proto.Message, requestID string, msgType kai.MessageType,
+	channel string,
+) (nats.PubAckFuture, error) {
+	payload, err := anypb.New(msg)
+	if err != nil {
+		return nil, fmt.Errorf("the handler result is not a valid protobuf: %s", err) //nolint:goerr113 // error is wrapped
+	}
+
+	return ms.publishAnyAsync(payload, requestID, msgType, channel)
 }
 
-func (ms Messaging) publishAny(payload *anypb.Any, requestID string, msgType kai.MessageType, channel string) {
+func (ms Messaging) publishAnyAsync(payload *anypb.Any, requestID string, msgType kai.MessageType,
+	channel string,
+) (nats.PubAckFuture, error) {
 	if requestID == "" {
 		requestID = uuid.New().String()
 	}
 
 	responseMsg := ms.newResponseMsg(payload, requestID, msgType)
-	ms.publishResponse(responseMsg, channel)
+
+	outputMsg, err := ms.newOutputMsg(responseMsg, channel, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ms.logger.WithName(_messagingLoggerName).Info(fmt.Sprintf("Publishing response asynchronously with "+
+		"subject %s for request id %s", outputMsg.Subject, responseMsg.RequestId))
+
+	future, err := ms.jetstream.PublishMsgAsync(outputMsg)
+	if err != nil {
+		ms.logger.WithName(_messagingLoggerName).
+			Error(err, fmt.Sprintf("Error publishing output for request id %s", responseMsg.RequestId))
+
+		return nil, fmt.Errorf("error publishing output for request id %s: %w", responseMsg.RequestId, err)
+	}
+
+	ms.pending.add(future)
+
+	return future, nil
 }
 
-func (ms Messaging) publishError(requestID, errMsg, channel string) {
+func (ms Messaging) publishError(requestID, errMsg, channel string) error {
 	responseMsg := &kai.KaiNatsMessage{
 		RequestId:   requestID,
 		Error:       errMsg,
-		FromNode:    viper.GetString(common.ConfigMetadataProcessIDKey),
+		FromNode:    common.ConfigOrGlobal(ms.config).GetString(common.ConfigMetadataProcessIDKey),
 		MessageType: kai.MessageType_ERROR,
 	}
-	ms.publishResponse(responseMsg, channel)
+
+	return ms.publishResponse(responseMsg, channel)
 }
 
 func (ms Messaging) newResponseMsg(payload *anypb.Any, requestID string,
@@ -72,13 +110,48 @@ func (ms Messaging) newResponseMsg(payload *anypb.Any, requestID string,
 	return &kai.KaiNatsMessage{
 		RequestId:   requestID,
 		Payload:     payload,
-		FromNode:    viper.GetString(common.ConfigMetadataProcessIDKey),
+		FromNode:    common.ConfigOrGlobal(ms.config).GetString(common.ConfigMetadataProcessIDKey),
 		MessageType: msgType,
 	}
 }
 
-func (ms Messaging) publishResponse(responseMsg *kai.KaiNatsMessage, channel string) {
-	outputSubject := ms.getOutputSubject(channel)
+func (ms Messaging) publishResponse(responseMsg *kai.KaiNatsMessage, channel string) error {
+	return ms.publishResponseWithHeader(responseMsg, channel, nil)
+}
+
+func (ms Messaging) publishResponseWithHeader(responseMsg *kai.KaiNatsMessage, channel string,
+	header nats.Header,
+) error {
+	outputMsg, err := ms.newOutputMsg(responseMsg, channel, header)
+	if err != nil {
+		return err
+	}
+
+	ms.logger.WithName(_messagingLoggerName).Info(fmt.Sprintf("Publishing response with subject %s "+
+		"for request id %s", outputMsg.Subject, responseMsg.RequestId))
+
+	if err := ms.publish(outputMsg); err != nil {
+		ms.logger.WithName(_messagingLoggerName).
+			Error(err, fmt.Sprintf("Error publishing output for"+
+				" request id %s", responseMsg.RequestId))
+
+		return fmt.Errorf("error publishing output for request id %s: %w", responseMsg.RequestId, err)
+	}
+
+	return nil
+}
+
+// newOutputMsg serializes the response, compressing it or offloading it to the ephemeral storage
+// when needed, into a message to the output subject of the given channel. The message carries the
+// deadline and the trace context of the context of the messaging, if any.
+func (ms Messaging) newOutputMsg(responseMsg *kai.KaiNatsMessage, channel string,
+	header nats.Header,
+) (*nats.Msg, error) {
+	// A handler cancelled, e.g. on timeout, no longer sends outputs, since its message may already
+	// have been retried or dead-lettered.
+	if ms.ctx != nil && ms.ctx.Err() != nil {
+		return nil, fmt.Errorf("error sending output for request id %s: %w", responseMsg.RequestId, ms.ctx.Err())
+	}
 
 	outputMsg, err := proto.Marshal(responseMsg)
 	if err != nil {
@@ -86,30 +159,80 @@ func (ms Messaging) publishResponse(responseMsg *kai.KaiNatsMessage, channel str
 			Error(err, fmt.Sprintf("Error generating output result because "+
 				"handler result is not a serializable Protobuf for request id %s", responseMsg.RequestId))
 
-		return
+		return nil, fmt.Errorf("error serializing output for request id %s: %w", responseMsg.RequestId, err)
+	}
+
+	data, codec, err := ms.prepareOutputMessage(outputMsg)
+	if errors.Is(err, utilErrors.ErrMessageToBig) && ms.isClaimCheckEnabled() {
+		data, header, err = ms.offloadMessage(responseMsg, outputMsg, header)
 	}
 
-	outputMsg, err = ms.prepareOutputMessage(outputMsg)
 	if err != nil {
 		ms.logger.WithName(_messagingLoggerName).
 			Error(err, fmt.Sprintf("Error preparing output message for request id %s", responseMsg.RequestId))
 
-		return
+		return nil, fmt.Errorf("error preparing output for request id %s: %w", responseMsg.RequestId, err)
 	}
 
-	ms.logger.WithName(_messagingLoggerName).Info(fmt.Sprintf("Publishing response with subject %s "+
-		"for request id %s", outputSubject, responseMsg.RequestId))
+	header = compression.SetHeader(header, codec)
 
-	_, err = ms.jetstream.Publish(outputSubject, outputMsg)
-	if err != nil {
-		ms.logger.WithName(_messagingLoggerName).
-			Error(err, fmt.Sprintf("Error publishing output for"+
-				" request id %s", responseMsg.RequestId))
+	if common.ConfigOrGlobal(ms.config).GetBool(common.ConfigNatsDeduplicationEnabledKey) {
+		if header == nil {
+			header = nats.Header{}
+		}
+
+		// A handler run again for the same message sends the same IDs, so JetStream drops the
+		// messages already stored within its duplicates window.
+		header.Set(nats.MsgIdHdr, ms.getMsgID(responseMsg, channel))
+	}
+
+	if ms.ctx != nil {
+		if header == nil {
+			header = nats.Header{}
+		}
+
+		if deadline, ok := ms.ctx.Deadline(); ok {
+			header.Set(common.DeadlineHeader, deadline.UTC().Format(time.RFC3339Nano))
+		}
+
+		// The trace context of the span in process, if any, is carried to the next processes.
+		tracing.Inject(ms.ctx, header)
+	}
+
+	msg := nats.NewMsg(ms.getOutputSubject(channel))
+	msg.Data = data
+
+	if len(header) > 0 {
+		msg.Header = header
 	}
+
+	return msg, nil
+}
+
+// publish publishes the message, with its header if any.
+func (ms Messaging) publish(msg *nats.Msg) error {
+	var err error
+
+	if len(msg.Header) == 0 {
+		_, err = ms.jetstream.Publish(msg.Subject, msg.Data)
+	} else {
+		_, err = ms.jetstream.PublishMsg(msg)
+	}
+
+	return err
+}
+
+// getMsgID identifies the message by its request, origin node, the message handled when it was
+// sent, if any, channel and order among the messages sent to the same channel.
+func (ms Messaging) getMsgID(responseMsg *kai.KaiNatsMessage, channel string) string {
+	seq := ms.sequences.nextFor(channel)
+
+	return fmt.Sprintf("%s/%s/%s/%s/%d",
+		responseMsg.GetRequestId(), responseMsg.GetFromNode(), ms.handledMsgID, channel, seq)
 }
 
 func (ms Messaging) getOutputSubject(channel string) string {
-	outputSubject := viper.GetString(common.ConfigNatsOutputKey)
+	outputSubject := common.ConfigOrGlobal(ms.config).GetString(common.ConfigNatsOutputKey)
 	if channel != "" {
 		return fmt.Sprintf("%s.%s", outputSubject, channel)
 	}
@@ -117,55 +240,55 @@ func (ms Messaging) getOutputSubject(channel string) string {
 	return outputSubject
 }
 
-func (ms Messaging) prepareOutputMessage(msg []byte) ([]byte, error) {
+// prepareOutputMessage compresses the message with the configured codec when it exceeds the maximum
+// size of the stream or the compression threshold, and returns it along with the codec used, if any.
+// Fails on compressed messages bigger than the maximum size.
+func (ms Messaging) prepareOutputMessage(msg []byte) ([]byte, string, error) {
 	maxSize, err := ms.messagingUtils.GetMaxMessageSize()
 	if err != nil {
-		return nil, fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
+		return nil, "", fmt.Errorf("error getting max message size: %s", err) //nolint:goerr113 // error is wrapped
 	}
 
+	options := compression.OptionsFromConfig(ms.config)
+
 	lenMsg := int64(len(msg))
-	if lenMsg <= maxSize {
-		return msg, nil
+	if lenMsg <= maxSize && !options.ShouldCompress(lenMsg) {
+		return msg, "", nil
 	}
 
 	ms.logger.WithName(_messagingLoggerName).V(1).
-		Info("Message exceeds maximum size allowed, compressing data")
+		Info(fmt.Sprintf("Compressing message with %s", options.Codec))
 
-	outMsg, err := common.CompressData(msg)
+	outMsg, codec, err := options.Compress(msg)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	lenOutMsg := int64(len(outMsg))
 	if lenOutMsg > maxSize {
 		ms.logger.WithName(_messagingLoggerName).V(1).
-			Info("Compressed message size %s exceeds maximum size allowed %s",
+			Info(fmt.Sprintf("Compressed message size %s exceeds maximum size allowed %s",
 				sizeInMB(lenOutMsg),
 				sizeInMB(maxSize),
-			)
+			))
 
-		return nil, errors.ErrMessageToBig
+		return nil, "", utilErrors.ErrMessageToBig
 	}
 
 	ms.logger.WithName(_messagingLoggerName).
 		Info(fmt.Sprintf("Message prepared with original size %s and compressed size %s", sizeInMB(lenMsg),
 			sizeInMB(lenOutMsg)))
 
-	return outMsg, nil
+	return outMsg, codec, nil
 }
 
 func (ms Messaging) GetRequestID(msg *nats.Msg) (string, error) {
 	requestMsg := &kai.KaiNatsMessage{}
 
-	data := msg.Data
-
-	var err error
-	if common.IsCompressed(data) {
-		data, err = common.UncompressData(data)
-		if err != nil {
-			ms.logger.WithName(_messagingLoggerName).Error(err, "Error reading compressed message")
-			return "", err
-		}
+	data, err := compression.Decompress(msg.Header, msg.Data)
+	if err != nil {
+		ms.logger.WithName(_messagingLoggerName).Error(err, "Error reading compressed message")
+		return "", err
 	}
 
 	err = proto.Unmarshal(data, requestMsg)
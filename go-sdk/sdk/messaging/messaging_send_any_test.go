@@ -6,6 +6,7 @@ import (
 	"fmt"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	utilErrors "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/nats-io/nats.go"
@@ -105,8 +106,7 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendAny_WithCompression_ExpectOk()
 	// Given
 	viper.SetDefault(natsOutputField, natsOutputValue)
 	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
-	s.jetstream.On("Publish", mock.AnythingOfType("string"), mock.AnythingOfType(unit8Type)).
-		Return(&nats.PubAck{}, nil)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
 	s.messagingUtils.On("GetMaxMessageSize").Return(int64(2048), nil)
 
 	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
@@ -122,8 +122,9 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendAny_WithCompression_ExpectOk()
 	s.NoError(err)
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
-	s.jetstream.AssertCalled(s.T(),
-		"Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(msg *nats.Msg) bool {
+		return msg.Subject == natsOutputValue && msg.Header.Get(common.CompressionHeader) == "gzip"
+	}))
 }
 
 func (s *SdkMessagingTestSuite) TestMessaging_SendAny_WithCompression_MessageToBig_ExpectError() {
@@ -141,10 +142,12 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendAny_WithCompression_MessageToB
 	msg, err := anypb.New(&wrappers.StringValue{
 		Value: generateRandomString(15000),
 	})
-	objectStore.SendAny(msg)
+	s.Require().NoError(err)
+
+	err = objectStore.SendAny(msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorIs(err, utilErrors.ErrMessageToBig)
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
 	s.jetstream.AssertNotCalled(s.T(),
@@ -190,10 +193,12 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendAny_ErrorOnMaxMessageSize_Expe
 	msg, err := anypb.New(&wrappers.StringValue{
 		Value: generateRandomString(1024),
 	})
-	objectStore.SendAny(msg)
+	s.Require().NoError(err)
+
+	err = objectStore.SendAny(msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorContains(err, "error getting size")
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
 	s.jetstream.AssertNotCalled(s.T(), "Publish")
@@ -214,11 +219,13 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendAny_ErrorOnPublish_ExpectError
 	msg, err := anypb.New(&wrappers.StringValue{
 		Value: generateRandomString(1024),
 	})
-	objectStore.SendAny(msg)
+	s.Require().NoError(err)
+
+	err = objectStore.SendAny(msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorContains(err, "error publishing")
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
-	s.jetstream.AssertNotCalled(s.T(), "Publish")
+	s.jetstream.AssertCalled(s.T(), "Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
 }
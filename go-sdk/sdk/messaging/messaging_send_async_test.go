@@ -0,0 +1,148 @@
+//go:build unit
+
+package messaging_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/messaging"
+)
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputAsync_ExpectFutureAndFlushed() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	future := newTestFuture(nil)
+	s.jetstream.On("PublishMsgAsync", mock.AnythingOfType("*nats.Msg")).Return(future, nil)
+
+	request := kai.KaiNatsMessage{RequestId: "123"}
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &request, &s.messagingUtils)
+
+	msg := wrappers.StringValue{Value: stringValueMessage}
+
+	// When
+	sent, err := messagingInst.SendOutputAsync(&msg)
+	s.Require().NoError(err)
+
+	err = messagingInst.Flush(context.Background())
+
+	// Then
+	s.NoError(err)
+	s.Same(future, sent)
+	s.jetstream.AssertCalled(s.T(), "PublishMsgAsync", mock.MatchedBy(func(natsMsg *nats.Msg) bool {
+		return natsMsg.Subject == natsOutputValue &&
+			string(natsMsg.Data) == string(getOutputMessage("123", &msg, "", metadataProcessIDValue, kai.MessageType_OK))
+	}))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendAnyAsync_PublishFailed_ExpectFlushError() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	publishErr := errors.New("no responders")
+	s.jetstream.On("PublishMsgAsync", mock.AnythingOfType("*nats.Msg")).Return(newTestFuture(publishErr), nil).Once()
+	s.jetstream.On("PublishMsgAsync", mock.AnythingOfType("*nats.Msg")).Return(newTestFuture(nil), nil).Once()
+
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	payload, err := anypb.New(&wrappers.StringValue{Value: stringValueMessage})
+	s.Require().NoError(err)
+
+	// When
+	_, err = messagingInst.SendAnyAsync(payload)
+	s.Require().NoError(err)
+
+	_, err = messagingInst.SendAnyAsync(payload, "subtopic")
+	s.Require().NoError(err)
+
+	err = messagingInst.Flush(context.Background())
+
+	// Then
+	s.ErrorIs(err, publishErr)
+	s.ErrorContains(err, natsOutputValue)
+	s.NoError(messagingInst.Flush(context.Background()))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputAsync_PublishRejected_ExpectError() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+	s.jetstream.On("PublishMsgAsync", mock.AnythingOfType("*nats.Msg")).Return(nil, nats.ErrConnectionClosed)
+
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	// When
+	future, err := messagingInst.SendOutputAsync(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.ErrorIs(err, nats.ErrConnectionClosed)
+	s.Nil(future)
+	s.NoError(messagingInst.Flush(context.Background()))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_Flush_ContextDone_ExpectPendingKept() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	future := &testFuture{ok: make(chan *nats.PubAck, 1), err: make(chan error, 1)}
+	s.jetstream.On("PublishMsgAsync", mock.AnythingOfType("*nats.Msg")).Return(future, nil)
+
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	_, err := messagingInst.SendOutputAsync(&wrappers.StringValue{Value: stringValueMessage})
+	s.Require().NoError(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	err = messagingInst.Flush(ctx)
+
+	// Then
+	s.ErrorIs(err, context.Canceled)
+
+	future.ok <- &nats.PubAck{}
+	s.NoError(messagingInst.Flush(context.Background()))
+}
+
+// testFuture is the future of a message whose acknowledgement is sent to its channels by the test.
+type testFuture struct {
+	ok  chan *nats.PubAck
+	err chan error
+}
+
+func newTestFuture(err error) *testFuture {
+	future := &testFuture{ok: make(chan *nats.PubAck, 1), err: make(chan error, 1)}
+
+	if err != nil {
+		future.err <- err
+	} else {
+		future.ok <- &nats.PubAck{}
+	}
+
+	return future
+}
+
+func (f *testFuture) Ok() <-chan *nats.PubAck {
+	return f.ok
+}
+
+func (f *testFuture) Err() <-chan error {
+	return f.err
+}
+
+func (f *testFuture) Msg() *nats.Msg {
+	return nats.NewMsg(natsOutputValue)
+}
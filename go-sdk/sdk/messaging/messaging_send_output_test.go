@@ -3,14 +3,22 @@
 package messaging_test
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/nats-io/nats.go"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/mock"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/proto"
 
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	utilErrors "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/messaging"
 )
 
@@ -37,6 +45,98 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_ExpectOk() {
 		"Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
 }
 
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputWithContextDone_ExpectError() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils).
+		WithContext(ctx)
+
+	// When
+	err := messagingInst.SendOutput(&wrappers.StringValue{Value: stringValueMessage})
+	_, asyncErr := messagingInst.SendOutputAsync(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.ErrorIs(err, context.Canceled)
+	s.ErrorIs(asyncErr, context.Canceled)
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.Anything)
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsgAsync", mock.Anything)
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputWithContextDeadline_ExpectDeadlineHeader() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	deadline := time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils).
+		WithContext(ctx)
+
+	// When
+	err := objectStore.SendOutput(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.NoError(err)
+	s.jetstream.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(msg *nats.Msg) bool {
+		return msg.Subject == natsOutputValue && msg.Header.Get("Kai-Deadline") == "2030-01-02T03:04:05.000000006Z"
+	}))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputWithContextSpan_ExpectTraceparentHeader() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01},
+		SpanID:     trace.SpanID{0x02},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils).
+		WithContext(ctx)
+
+	// When
+	err := objectStore.SendOutput(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.NoError(err)
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(msg *nats.Msg) bool {
+		return msg.Header.Get("traceparent") == fmt.Sprintf("00-%s-%s-01", spanContext.TraceID(), spanContext.SpanID())
+	}))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutputWithContextWithoutSpan_ExpectNoHeader() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	s.jetstream.On("Publish", mock.AnythingOfType("string"), mock.AnythingOfType(unit8Type)).
+		Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &kai.KaiNatsMessage{}, &s.messagingUtils).
+		WithContext(context.Background())
+
+	// When
+	err := objectStore.SendOutput(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.NoError(err)
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.Anything)
+	s.jetstream.AssertCalled(s.T(), "Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
+}
+
 func (s *SdkMessagingTestSuite) TestMessaging_SendOutputWithExistingRequestMessage_ExpectOk() {
 	// Given
 	viper.SetDefault(natsOutputField, natsOutputValue)
@@ -92,8 +192,7 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_WithCompression_ExpectO
 	// Given
 	viper.SetDefault(natsOutputField, natsOutputValue)
 	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
-	s.jetstream.On("Publish", mock.AnythingOfType("string"), mock.AnythingOfType(unit8Type)).
-		Return(&nats.PubAck{}, nil)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
 	s.messagingUtils.On("GetMaxMessageSize").Return(int64(2048), nil)
 
 	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
@@ -109,8 +208,83 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_WithCompression_ExpectO
 	s.NoError(err)
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
-	s.jetstream.AssertCalled(s.T(),
-		"Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(msg *nats.Msg) bool {
+		return msg.Subject == natsOutputValue && msg.Header.Get(common.CompressionHeader) == "gzip"
+	}))
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_AboveCompressionThreshold_ExpectConfiguredCodec() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsCompressionCodecKey, compression.CodecZstd)
+	viper.Set(common.ConfigNatsCompressionThresholdKey, 1024)
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
+		&kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	msg := wrappers.StringValue{Value: strings.Repeat("a", 2048)}
+
+	// When
+	err := objectStore.SendOutput(&msg)
+
+	// Then
+	s.NoError(err)
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(natsMsg *nats.Msg) bool {
+		return natsMsg.Header.Get(common.CompressionHeader) == compression.CodecZstd
+	}))
+
+	natsMsg := s.jetstream.Calls[0].Arguments.Get(0).(*nats.Msg) //nolint:forcetypeassert // matched above
+	data, err := compression.Decompress(natsMsg.Header, natsMsg.Data)
+	s.Require().NoError(err)
+
+	responseMsg := &kai.KaiNatsMessage{}
+	s.Require().NoError(proto.Unmarshal(data, responseMsg))
+
+	payload := &wrappers.StringValue{}
+	s.Require().NoError(responseMsg.GetPayload().UnmarshalTo(payload))
+	s.Equal(msg.GetValue(), payload.GetValue())
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_BelowCompressionThreshold_ExpectNotCompressed() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsCompressionThresholdKey, 1024)
+	s.jetstream.On("Publish", mock.AnythingOfType("string"), mock.AnythingOfType(unit8Type)).
+		Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(1024*1024*1024), nil)
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
+		&kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	// When
+	err := objectStore.SendOutput(&wrappers.StringValue{Value: stringValueMessage})
+
+	// Then
+	s.NoError(err)
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.Anything)
+}
+
+func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_NoneCodec_MessageToBig_ExpectError() {
+	// Given
+	viper.SetDefault(natsOutputField, natsOutputValue)
+	viper.SetDefault(metadataProcessIDField, metadataProcessIDValue)
+	viper.Set(common.ConfigNatsCompressionCodecKey, compression.CodecNone)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(128), nil)
+
+	objectStore := messaging.NewTestMessaging(s.logger, nil, &s.jetstream,
+		&kai.KaiNatsMessage{}, &s.messagingUtils)
+
+	// When
+	err := objectStore.SendOutput(&wrappers.StringValue{Value: strings.Repeat("a", 2048)})
+
+	// Then
+	s.ErrorIs(err, utilErrors.ErrMessageToBig)
+	s.jetstream.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+	s.jetstream.AssertNotCalled(s.T(), "PublishMsg", mock.Anything)
 }
 
 func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_WithCompression_MessageToBig_ExpectError() {
@@ -131,7 +305,7 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_WithCompression_Message
 	err := objectStore.SendOutput(&msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorIs(err, utilErrors.ErrMessageToBig)
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
 	s.jetstream.AssertNotCalled(s.T(),
@@ -180,7 +354,7 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_ErrorOnMaxMessageSize_E
 	err := objectStore.SendOutput(&msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorContains(err, "error getting size")
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
 	s.jetstream.AssertNotCalled(s.T(), "Publish")
@@ -204,8 +378,8 @@ func (s *SdkMessagingTestSuite) TestMessaging_SendOutput_ErrorOnPublish_ExpectEr
 	err := objectStore.SendOutput(&msg)
 
 	// Then
-	s.NoError(err)
+	s.ErrorContains(err, "error publishing")
 	s.NotNil(objectStore)
 	s.messagingUtils.AssertNumberOfCalls(s.T(), "GetMaxMessageSize", 1)
-	s.jetstream.AssertNotCalled(s.T(), "Publish")
+	s.jetstream.AssertCalled(s.T(), "Publish", natsOutputValue, mock.AnythingOfType(unit8Type))
 }
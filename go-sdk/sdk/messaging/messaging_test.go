@@ -3,6 +3,7 @@
 package messaging_test
 
 import (
+	"bytes"
 	"errors"
 	"math/rand"
 	"testing"
@@ -92,6 +93,28 @@ func (s *SdkMessagingTestSuite) TestMessaging_PublishError_WithChannel_ExpectOk(
 		getOutputMessage("123", nil, "some-error", "parent-node", kai.MessageType_ERROR))
 }
 
+func (s *SdkMessagingTestSuite) TestMessaging_SendEndOfStream_ExpectEndOfStreamHeader() {
+	// Given
+	viper.SetDefault(common.ConfigNatsOutputKey, "test-parent")
+	viper.SetDefault(common.ConfigMetadataProcessIDKey, "parent-node")
+	s.jetstream.On("PublishMsg", mock.AnythingOfType("*nats.Msg")).Return(&nats.PubAck{}, nil)
+	s.messagingUtils.On("GetMaxMessageSize").Return(int64(2048), nil)
+
+	request := kai.KaiNatsMessage{RequestId: "123"}
+	messagingInst := messaging.NewTestMessaging(s.logger, nil, &s.jetstream, &request, &s.messagingUtils)
+
+	// When
+	messagingInst.SendEndOfStream("some-channel")
+
+	// Then
+	s.jetstream.AssertNotCalled(s.T(), "Publish", mock.Anything, mock.Anything)
+	s.jetstream.AssertCalled(s.T(), "PublishMsg", mock.MatchedBy(func(msg *nats.Msg) bool {
+		return msg.Subject == "test-parent.some-channel" &&
+			msg.Header.Get(common.EndOfStreamHeader) != "" &&
+			bytes.Equal(msg.Data, getOutputMessage("123", nil, "", "parent-node", kai.MessageType_OK))
+	}))
+}
+
 func (s *SdkMessagingTestSuite) TestMessaging_GetRequestID_ExpectOk() {
 	// Given
 	msg := &kai.KaiNatsMessage{
@@ -17,17 +17,19 @@ type messagingUtils interface {
 type MessagingUtilsImpl struct {
 	jetstream nats.JetStreamContext
 	nats      *nats.Conn
+	config    *viper.Viper
 }
 
-func NewMessagingUtils(ns *nats.Conn, js nats.JetStreamContext) MessagingUtilsImpl {
+func NewMessagingUtils(ns *nats.Conn, js nats.JetStreamContext, config *viper.Viper) MessagingUtilsImpl {
 	return MessagingUtilsImpl{
 		nats:      ns,
 		jetstream: js,
+		config:    config,
 	}
 }
 
 func (mu MessagingUtilsImpl) GetMaxMessageSize() (int64, error) {
-	streamInfo, err := mu.jetstream.StreamInfo(viper.GetString(common.ConfigNatsStreamKey))
+	streamInfo, err := mu.jetstream.StreamInfo(common.ConfigOrGlobal(mu.config).GetString(common.ConfigNatsStreamKey))
 	if err != nil {
 		return 0, fmt.Errorf("error getting stream's max message size: %w", err)
 	}
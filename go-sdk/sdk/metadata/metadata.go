@@ -6,52 +6,64 @@ import (
 )
 
 type Metadata struct {
+	config *viper.Viper
 }
 
 func New() *Metadata {
-	return &Metadata{}
+	return NewWithConfig(nil)
+}
+
+// NewWithConfig creates a Metadata reading from the given viper instance instead of the global one.
+func NewWithConfig(config *viper.Viper) *Metadata {
+	return &Metadata{
+		config: config,
+	}
 }
 
 func (md Metadata) GetProduct() string {
-	return viper.GetString(common.ConfigMetadataProductIDKey)
+	return md.getString(common.ConfigMetadataProductIDKey)
 }
 
 func (md Metadata) GetWorkflow() string {
-	return viper.GetString(common.ConfigMetadataWorkflowIDKey)
+	return md.getString(common.ConfigMetadataWorkflowIDKey)
 }
 
 func (md Metadata) GetWorkflowType() string {
-	return viper.GetString(common.ConfigMetadataWorkflowTypeKey)
+	return md.getString(common.ConfigMetadataWorkflowTypeKey)
 }
 
 func (md Metadata) GetProcess() string {
-	return viper.GetString(common.ConfigMetadataProcessIDKey)
+	return md.getString(common.ConfigMetadataProcessIDKey)
 }
 
 func (md Metadata) GetProcessType() string {
-	return viper.GetString(common.ConfigMetadataProcessTypeKey)
+	return md.getString(common.ConfigMetadataProcessTypeKey)
 }
 
 func (md Metadata) GetVersion() string {
-	return viper.GetString(common.ConfigMetadataVersionIDKey)
+	return md.getString(common.ConfigMetadataVersionIDKey)
 }
 
 func (md Metadata) GetEphemeralStorageName() string {
-	return viper.GetString(common.ConfigNatsEphemeralStorage)
+	return md.getString(common.ConfigNatsEphemeralStorage)
 }
 
 func (md Metadata) GetGlobalCentralizedConfigurationName() string {
-	return viper.GetString(common.ConfigCcGlobalBucketKey)
+	return md.getString(common.ConfigCcGlobalBucketKey)
 }
 
 func (md Metadata) GetProductCentralizedConfigurationName() string {
-	return viper.GetString(common.ConfigCcProductBucketKey)
+	return md.getString(common.ConfigCcProductBucketKey)
 }
 
 func (md Metadata) GetWorkflowCentralizedConfigurationName() string {
-	return viper.GetString(common.ConfigCcWorkflowBucketKey)
+	return md.getString(common.ConfigCcWorkflowBucketKey)
 }
 
 func (md Metadata) GetProcessCentralizedConfigurationName() string {
-	return viper.GetString(common.ConfigCcProcessBucketKey)
+	return md.getString(common.ConfigCcProcessBucketKey)
+}
+
+func (md Metadata) getString(key string) string {
+	return common.ConfigOrGlobal(md.config).GetString(key)
 }
@@ -15,6 +15,10 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
 	"github.com/minio/minio-go/v7"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 )
 
 const (
@@ -36,6 +40,7 @@ type ModelRegistry struct {
 	storageBucket   string
 	metadata        *metadata.Metadata
 	modelFolderName string
+	ctx             context.Context
 }
 
 type ModelInfo struct {
@@ -51,14 +56,21 @@ type Model struct {
 }
 
 func New(logger logr.Logger, meta *metadata.Metadata) (*ModelRegistry, error) {
-	persistentStorageBucket := viper.GetString(common.ConfigMinioBucketKey)
+	return NewWithConfig(logger, meta, nil)
+}
+
+// NewWithConfig creates a ModelRegistry reading from the given viper instance instead of the global one.
+func NewWithConfig(logger logr.Logger, meta *metadata.Metadata, config *viper.Viper) (*ModelRegistry, error) {
+	cfg := common.ConfigOrGlobal(config)
+
+	persistentStorageBucket := cfg.GetString(common.ConfigMinioBucketKey)
 
 	modelFolder := path.Join(
-		viper.GetString(common.ConfigMinioInternalFolderKey),
-		viper.GetString(common.ConfigModelFolderNameKey),
+		cfg.GetString(common.ConfigMinioInternalFolderKey),
+		cfg.GetString(common.ConfigModelFolderNameKey),
 	)
 
-	storageClient, err := storage.New(logger).GetStorageClient()
+	storageClient, err := storage.New(logger, config).GetStorageClient()
 	if err != nil {
 		return nil, err
 	}
@@ -72,8 +84,35 @@ func New(logger logr.Logger, meta *metadata.Metadata) (*ModelRegistry, error) {
 	}, nil
 }
 
+// WithContext returns a copy of the model registry whose requests are cancelled with the given context.
+func (mr *ModelRegistry) WithContext(ctx context.Context) *ModelRegistry {
+	registry := *mr
+	registry.ctx = ctx
+
+	return &registry
+}
+
+func (mr *ModelRegistry) getContext() context.Context {
+	if mr.ctx == nil {
+		return context.Background()
+	}
+
+	return mr.ctx
+}
+
+// startSpan starts the span of an operation of the registry, returning a copy of it whose requests
+// belong to the span.
+func (mr *ModelRegistry) startSpan(operation string, attrs ...attribute.KeyValue) (*ModelRegistry, trace.Span) {
+	ctx, span := tracing.Tracer().Start(mr.getContext(), "model_registry."+operation, trace.WithAttributes(attrs...))
+
+	return mr.WithContext(ctx), span
+}
+
 func (mr *ModelRegistry) RegisterModel(model []byte, name, version, modelFormat string, description ...string) error {
-	ctx := context.Background()
+	mr, span := mr.startSpan("RegisterModel", attribute.String("kai.model.name", name))
+	defer span.End()
+
+	ctx := mr.getContext()
 
 	if name == "" {
 		return errors.ErrEmptyName
@@ -130,6 +169,9 @@ func (mr *ModelRegistry) RegisterModel(model []byte, name, version, modelFormat
 }
 
 func (mr *ModelRegistry) GetModel(name string, version ...string) (*Model, error) {
+	mr, span := mr.startSpan("GetModel", attribute.String("kai.model.name", name))
+	defer span.End()
+
 	if name == "" {
 		return nil, errors.ErrEmptyName
 	}
@@ -148,10 +190,13 @@ func (mr *ModelRegistry) GetModel(name string, version ...string) (*Model, error
 }
 
 func (mr *ModelRegistry) ListModels() ([]*ModelInfo, error) {
+	mr, span := mr.startSpan("ListModels")
+	defer span.End()
+
 	var modelInfoList []*ModelInfo
 
 	objects := mr.storageClient.ListObjects(
-		context.Background(),
+		mr.getContext(),
 		mr.storageBucket,
 		minio.ListObjectsOptions{
 			WithMetadata: true,
@@ -165,7 +210,7 @@ func (mr *ModelRegistry) ListModels() ([]*ModelInfo, error) {
 
 	for object := range objects {
 		if object.Key != "" {
-			stats, err := mr.storageClient.StatObject(context.Background(), mr.storageBucket, object.Key, minio.StatObjectOptions{})
+			stats, err := mr.storageClient.StatObject(mr.getContext(), mr.storageBucket, object.Key, minio.StatObjectOptions{})
 			if err != nil {
 				return nil, fmt.Errorf("error getting model stats from the model registry: %w", err)
 			}
@@ -186,6 +231,9 @@ func (mr *ModelRegistry) ListModels() ([]*ModelInfo, error) {
 }
 
 func (mr *ModelRegistry) ListModelVersions(name string) ([]*ModelInfo, error) {
+	mr, span := mr.startSpan("ListModelVersions", attribute.String("kai.model.name", name))
+	defer span.End()
+
 	var modelInfoList []*ModelInfo
 
 	if name == "" {
@@ -193,7 +241,7 @@ func (mr *ModelRegistry) ListModelVersions(name string) ([]*ModelInfo, error) {
 	}
 
 	objects := mr.storageClient.ListObjects(
-		context.Background(),
+		mr.getContext(),
 		mr.storageBucket,
 		minio.ListObjectsOptions{
 			WithVersions: true,
@@ -208,7 +256,7 @@ func (mr *ModelRegistry) ListModelVersions(name string) ([]*ModelInfo, error) {
 
 	for object := range objects {
 		if object.VersionID != "" {
-			stats, err := mr.storageClient.StatObject(context.Background(), mr.storageBucket, object.Key, minio.StatObjectOptions{
+			stats, err := mr.storageClient.StatObject(mr.getContext(), mr.storageBucket, object.Key, minio.StatObjectOptions{
 				VersionID: object.VersionID,
 			})
 			if err != nil {
@@ -231,6 +279,9 @@ func (mr *ModelRegistry) ListModelVersions(name string) ([]*ModelInfo, error) {
 }
 
 func (mr *ModelRegistry) DeleteModel(name string) error {
+	mr, span := mr.startSpan("DeleteModel", attribute.String("kai.model.name", name))
+	defer span.End()
+
 	if name == "" {
 		return errors.ErrEmptyName
 	}
@@ -240,7 +291,7 @@ func (mr *ModelRegistry) DeleteModel(name string) error {
 	}
 
 	err := mr.storageClient.RemoveObject(
-		context.Background(),
+		mr.getContext(),
 		mr.storageBucket,
 		mr.getModelPath(name),
 		opts,
@@ -268,7 +319,7 @@ func (mr *ModelRegistry) getModelName(fullPath string) string {
 func (mr *ModelRegistry) getModelVersion(name string, opts minio.GetObjectOptions) (*Model, error) {
 	// Retrieve latest model version
 	object, err := mr.storageClient.GetObject(
-		context.Background(),
+		mr.getContext(),
 		mr.storageBucket,
 		mr.getModelPath(name),
 		opts,
@@ -302,7 +353,7 @@ func (mr *ModelRegistry) getModelVersion(name string, opts minio.GetObjectOption
 
 func (mr *ModelRegistry) getModelVersionFromList(name, version string) (*Model, error) {
 	objectList := mr.storageClient.ListObjects(
-		context.Background(),
+		mr.getContext(),
 		mr.storageBucket,
 		minio.ListObjectsOptions{
 			Prefix:       mr.getModelPath(name),
@@ -317,7 +368,7 @@ func (mr *ModelRegistry) getModelVersionFromList(name, version string) (*Model,
 		}
 
 		stats, err := mr.storageClient.StatObject(
-			context.Background(),
+			mr.getContext(),
 			mr.storageBucket,
 			object.Key,
 			minio.StatObjectOptions{
@@ -331,7 +382,7 @@ func (mr *ModelRegistry) getModelVersionFromList(name, version string) (*Model,
 		// Check if the object is the one we are looking for and not a directory
 		if object.Key == mr.getModelPath(name) && stats.UserMetadata[_modelVersionMetadata] == version {
 			objectData, err := mr.storageClient.GetObject(
-				context.Background(),
+				mr.getContext(),
 				mr.storageBucket,
 				object.Key,
 				minio.GetObjectOptions{
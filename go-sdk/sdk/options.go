@@ -0,0 +1,100 @@
+package sdk
+
+import (
+	"fmt"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+)
+
+// Option configures how New creates a KaiSDK.
+type Option func(*options)
+
+type options struct {
+	logger    logr.Logger
+	nats      *nats.Conn
+	jetstream nats.JetStreamContext
+	config    *viper.Viper
+}
+
+func newOptions(opts ...Option) *options {
+	o := &options{
+		logger: logr.Discard(),
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return o
+}
+
+// WithLogger sets the logger used by the SDK and its components. Logs are discarded by default.
+func WithLogger(logger logr.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithNatsConnection sets an existing NATS connection. Without it, the SDK connects to the
+// configured NATS URL.
+func WithNatsConnection(nc *nats.Conn) Option {
+	return func(o *options) {
+		o.nats = nc
+	}
+}
+
+// WithJetStream sets an existing JetStream context. Without it, one is created from the NATS connection.
+func WithJetStream(js nats.JetStreamContext) Option {
+	return func(o *options) {
+		o.jetstream = js
+	}
+}
+
+// WithViper sets the viper instance the SDK reads its configuration from. The global one is used by default.
+func WithViper(config *viper.Viper) Option {
+	return func(o *options) {
+		o.config = config
+	}
+}
+
+// WithConfig sets the configuration of the SDK from a Config.
+func WithConfig(config Config) Option {
+	return WithViper(config.Viper())
+}
+
+// dialsConnection tells whether getConnections dials a NATS connection of its own, which must be
+// closed by the SDK when it cannot be created.
+func (o *options) dialsConnection() bool {
+	return o.nats == nil && o.jetstream == nil
+}
+
+func (o *options) getConnections() (*nats.Conn, nats.JetStreamContext, error) {
+	if o.jetstream != nil {
+		return o.nats, o.jetstream, nil
+	}
+
+	nc := o.nats
+	if nc == nil {
+		var err error
+
+		nc, err = nats.Connect(common.ConfigOrGlobal(o.config).GetString(common.ConfigNatsURLKey))
+		if err != nil {
+			return nil, nil, fmt.Errorf("error connecting to NATS: %w", err)
+		}
+	}
+
+	js, err := nc.JetStream()
+	if err != nil {
+		if o.nats == nil {
+			nc.Close()
+		}
+
+		return nil, nil, fmt.Errorf("error connecting to JetStream: %w", err)
+	}
+
+	return nc, js, nil
+}
@@ -14,13 +14,6 @@ import (
 	"github.com/spf13/viper"
 )
 
-func NewObject(info ObjectInfo, data []byte) Object {
-	return Object{
-		ObjectInfo: info,
-		data:       data,
-	}
-}
-
 func NewPersistentStorageIntegration(logger logr.Logger) (*PersistentStorage, error) {
 	persistentStorageBucket := viper.GetString(common.ConfigMinioBucketKey)
 
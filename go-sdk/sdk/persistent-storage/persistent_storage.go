@@ -18,6 +18,10 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
 	"github.com/minio/minio-go/v7"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 )
 
 const (
@@ -34,6 +38,8 @@ type PersistentStorage struct {
 	storageClient *minio.Client
 	storageBucket string
 	metadata      *metadata.Metadata
+	config        *viper.Viper
+	ctx           context.Context
 }
 
 type ObjectInfo struct {
@@ -47,6 +53,15 @@ type Object struct {
 	data []byte
 }
 
+// NewObject creates an Object holding the given data, mainly for alternative
+// persistent storage implementations such as the in-memory one in sdktest.
+func NewObject(info ObjectInfo, data []byte) *Object {
+	return &Object{
+		ObjectInfo: info,
+		data:       data,
+	}
+}
+
 func (o Object) GetAsString() string {
 	return string(o.data)
 }
@@ -56,9 +71,14 @@ func (o Object) GetBytes() []byte {
 }
 
 func New(logger logr.Logger, meta *metadata.Metadata) (*PersistentStorage, error) {
-	persistentStorageBucket := viper.GetString(common.ConfigMinioBucketKey)
+	return NewWithConfig(logger, meta, nil)
+}
+
+// NewWithConfig creates a PersistentStorage reading from the given viper instance instead of the global one.
+func NewWithConfig(logger logr.Logger, meta *metadata.Metadata, config *viper.Viper) (*PersistentStorage, error) {
+	persistentStorageBucket := common.ConfigOrGlobal(config).GetString(common.ConfigMinioBucketKey)
 
-	storageClient, err := storage.New(logger).GetStorageClient()
+	storageClient, err := storage.New(logger, config).GetStorageClient()
 	if err != nil {
 		return nil, err
 	}
@@ -68,17 +88,44 @@ func New(logger logr.Logger, meta *metadata.Metadata) (*PersistentStorage, error
 		storageClient: storageClient,
 		storageBucket: persistentStorageBucket,
 		metadata:      meta,
+		config:        config,
 	}, nil
 }
 
+// WithContext returns a copy of the persistent storage whose requests are cancelled with the given context.
+func (ps PersistentStorage) WithContext(ctx context.Context) *PersistentStorage {
+	ps.ctx = ctx
+	return &ps
+}
+
+func (ps PersistentStorage) getContext() context.Context {
+	if ps.ctx == nil {
+		return context.Background()
+	}
+
+	return ps.ctx
+}
+
+// startSpan starts the span of an operation of the storage, returning a copy of it whose requests
+// belong to the span.
+func (ps PersistentStorage) startSpan(operation string, attrs ...attribute.KeyValue) (PersistentStorage, trace.Span) {
+	ctx, span := tracing.Tracer().Start(ps.getContext(), "persistent_storage."+operation, trace.WithAttributes(attrs...))
+	ps.ctx = ctx
+
+	return ps, span
+}
+
 func (ps PersistentStorage) Save(key string, payload []byte, ttlDays ...int) (*ObjectInfo, error) {
-	ctx := context.Background()
+	ps, span := ps.startSpan("Save", attribute.String("kai.storage.key", key))
+	defer span.End()
+
+	ctx := ps.getContext()
 
 	if key == "" {
 		return nil, errors.ErrEmptyKey
 	}
 
-	if strings.HasPrefix(key, viper.GetString(common.ConfigMinioInternalFolderKey)) {
+	if strings.HasPrefix(key, ps.getInternalFolder()) {
 		return nil, errors.ErrInvalidKey
 	}
 
@@ -128,11 +175,14 @@ func (ps PersistentStorage) Save(key string, payload []byte, ttlDays ...int) (*O
 }
 
 func (ps PersistentStorage) Get(key string, version ...string) (*Object, error) {
+	ps, span := ps.startSpan("Get", attribute.String("kai.storage.key", key))
+	defer span.End()
+
 	if key == "" {
 		return nil, errors.ErrEmptyKey
 	}
 
-	if strings.HasPrefix(key, viper.GetString(common.ConfigMinioInternalFolderKey)) {
+	if strings.HasPrefix(key, ps.getInternalFolder()) {
 		return nil, errors.ErrInvalidKey
 	}
 
@@ -145,7 +195,7 @@ func (ps PersistentStorage) Get(key string, version ...string) (*Object, error)
 	}
 
 	object, err := ps.storageClient.GetObject(
-		context.Background(),
+		ps.getContext(),
 		ps.storageBucket,
 		key,
 		opts,
@@ -182,10 +232,13 @@ func (ps PersistentStorage) Get(key string, version ...string) (*Object, error)
 }
 
 func (ps PersistentStorage) List() ([]*ObjectInfo, error) {
+	ps, span := ps.startSpan("List")
+	defer span.End()
+
 	var objectList []*ObjectInfo
 
 	objects := ps.storageClient.ListObjects(
-		context.Background(),
+		ps.getContext(),
 		ps.storageBucket,
 		minio.ListObjectsOptions{
 			WithMetadata: true,
@@ -197,8 +250,8 @@ func (ps PersistentStorage) List() ([]*ObjectInfo, error) {
 		Info("Objects successfully retrieved from persistent storage")
 
 	for object := range objects {
-		if object.Key != "" && !strings.HasPrefix(object.Key, viper.GetString(common.ConfigMinioInternalFolderKey)) {
-			stats, err := ps.storageClient.StatObject(context.Background(), ps.storageBucket, object.Key, minio.StatObjectOptions{})
+		if object.Key != "" && !strings.HasPrefix(object.Key, ps.getInternalFolder()) {
+			stats, err := ps.storageClient.StatObject(ps.getContext(), ps.storageBucket, object.Key, minio.StatObjectOptions{})
 			if err != nil {
 				return nil, fmt.Errorf("error getting object stats from the persistent storage: %w", err)
 			}
@@ -218,6 +271,9 @@ func (ps PersistentStorage) List() ([]*ObjectInfo, error) {
 }
 
 func (ps PersistentStorage) ListVersions(key string) ([]*ObjectInfo, error) {
+	ps, span := ps.startSpan("ListVersions", attribute.String("kai.storage.key", key))
+	defer span.End()
+
 	var objectList []*ObjectInfo
 
 	if key == "" {
@@ -225,7 +281,7 @@ func (ps PersistentStorage) ListVersions(key string) ([]*ObjectInfo, error) {
 	}
 
 	objects := ps.storageClient.ListObjects(
-		context.Background(),
+		ps.getContext(),
 		ps.storageBucket,
 		minio.ListObjectsOptions{
 			WithVersions: true,
@@ -239,7 +295,7 @@ func (ps PersistentStorage) ListVersions(key string) ([]*ObjectInfo, error) {
 		Info(fmt.Sprintf("Object versions successfully retrieved for prefix %s from persistent storage", key))
 
 	for object := range objects {
-		if object.VersionID != "" && !strings.HasPrefix(object.Key, viper.GetString(common.ConfigMinioInternalFolderKey)) {
+		if object.VersionID != "" && !strings.HasPrefix(object.Key, ps.getInternalFolder()) {
 			objectList = append(
 				objectList,
 				&ObjectInfo{
@@ -255,11 +311,14 @@ func (ps PersistentStorage) ListVersions(key string) ([]*ObjectInfo, error) {
 }
 
 func (ps PersistentStorage) Delete(key string, version ...string) error {
+	ps, span := ps.startSpan("Delete", attribute.String("kai.storage.key", key))
+	defer span.End()
+
 	if key == "" {
 		return errors.ErrEmptyKey
 	}
 
-	if strings.HasPrefix(key, viper.GetString(common.ConfigMinioInternalFolderKey)) {
+	if strings.HasPrefix(key, ps.getInternalFolder()) {
 		return errors.ErrInvalidKey
 	}
 
@@ -272,7 +331,7 @@ func (ps PersistentStorage) Delete(key string, version ...string) error {
 	}
 
 	err := ps.storageClient.RemoveObject(
-		context.Background(),
+		ps.getContext(),
 		ps.storageBucket,
 		key,
 		opts,
@@ -287,6 +346,20 @@ func (ps PersistentStorage) Delete(key string, version ...string) error {
 	return nil
 }
 
+// Ping checks that MinIO is reachable and the persistent storage bucket exists.
+func (ps PersistentStorage) Ping(ctx context.Context) error {
+	exists, err := ps.storageClient.BucketExists(ctx, ps.storageBucket)
+	if err != nil {
+		return fmt.Errorf("error reaching the persistent storage: %w", err)
+	}
+
+	if !exists {
+		return fmt.Errorf("persistent storage bucket %s not found", ps.storageBucket)
+	}
+
+	return nil
+}
+
 func (ps PersistentStorage) addLifecycleDeletionRule(key string, ttlDays []int, ctx context.Context) error {
 	if len(ttlDays) > 0 && ttlDays[0] > 0 {
 		lc, err := ps.storageClient.GetBucketLifecycle(ctx, ps.storageBucket)
@@ -326,3 +399,7 @@ func (ps PersistentStorage) addLifecycleDeletionRule(key string, ttlDays []int,
 
 	return nil
 }
+
+func (ps PersistentStorage) getInternalFolder() string {
+	return common.ConfigOrGlobal(ps.config).GetString(common.ConfigMinioInternalFolderKey)
+}
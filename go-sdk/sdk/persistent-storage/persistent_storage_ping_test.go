@@ -0,0 +1,33 @@
+//go:build integration
+
+package persistentstorage_test
+
+import (
+	"context"
+
+	"github.com/minio/minio-go/v7"
+)
+
+func (s *SdkPersistentStorageTestSuite) TestPersistentStorage_Ping_ExpectOK() {
+	// WHEN
+	err := s.persistentStorage.Ping(context.Background())
+
+	// THEN
+	s.Assert().NoError(err)
+}
+
+func (s *SdkPersistentStorageTestSuite) TestPersistentStorage_Ping_BucketNotFound_ExpectError() {
+	// GIVEN
+	err := s.client.RemoveBucketWithOptions(
+		context.Background(),
+		s.persistentStorageBucket,
+		minio.RemoveBucketOptions{ForceDelete: true},
+	)
+	s.Require().NoError(err)
+
+	// WHEN
+	err = s.persistentStorage.Ping(context.Background())
+
+	// THEN
+	s.Assert().Error(err)
+}
@@ -2,9 +2,17 @@ package prediction
 
 import (
 	"context"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func (r *RedisPredictionStore) Delete(ctx context.Context, predictionID string) error {
+	ctx, span := r.startSpan(ctx, "Delete", attribute.String("kai.prediction.id", predictionID))
+	defer span.End()
+
+	ctx, cancel := r.withStoreContext(ctx)
+	defer cancel()
+
 	if predictionID == "" {
 		return ErrInvalidPredictionID
 	}
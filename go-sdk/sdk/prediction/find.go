@@ -8,17 +8,24 @@ import (
 	"time"
 
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
-	"github.com/spf13/viper"
 )
 
 func (r *RedisPredictionStore) Find(ctx context.Context, filter *Filter) ([]Prediction, error) {
+	ctx, span := r.startSpan(ctx, "Find")
+	defer span.End()
+
+	ctx, cancel := r.withStoreContext(ctx)
+	defer cancel()
+
 	var predictions []Prediction
 
 	if err := filter.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid filter: %w", err)
 	}
 
-	result, err := r.client.Do(ctx, "FT.SEARCH", viper.GetString(common.ConfigRedisIndexKey), r.buildQueryWithFilters(filter)).Result()
+	index := common.ConfigOrGlobal(r.config).GetString(common.ConfigRedisIndexKey)
+
+	result, err := r.client.Do(ctx, "FT.SEARCH", index, r.buildQueryWithFilters(filter)).Result()
 	if err != nil {
 		return nil, err
 	}
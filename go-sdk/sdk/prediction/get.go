@@ -4,9 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 func (r *RedisPredictionStore) Get(ctx context.Context, predictionID string) (*Prediction, error) {
+	ctx, span := r.startSpan(ctx, "Get", attribute.String("kai.prediction.id", predictionID))
+	defer span.End()
+
+	ctx, cancel := r.withStoreContext(ctx)
+	defer cancel()
+
 	result, err := r.client.JSONGet(ctx, r.getKeyWithProductPrefix(predictionID), "$").Result()
 	if err != nil {
 		return nil, err
@@ -71,3 +71,18 @@ func (s *PredictionStoreSuite) TestPredictionStore_Get_FailsInvalidKey() {
 	s.ErrorIs(err, prediction.ErrPredictionNotFound)
 	s.Nil(actualPrediction)
 }
+
+func (s *PredictionStoreSuite) TestPredictionStore_Get_StoreContextCancelled_ExpectError() {
+	var (
+		predictionID     = "test-prediction"
+		storeCtx, cancel = context.WithCancel(context.Background())
+	)
+
+	cancel()
+
+	// WHEN
+	_, err := s.predictionStore.WithContext(storeCtx).Get(context.Background(), predictionID)
+
+	// THEN
+	s.ErrorIs(err, context.Canceled)
+}
@@ -0,0 +1,15 @@
+//go:build integration
+
+package prediction_test
+
+import (
+	"context"
+)
+
+func (s *PredictionStoreSuite) TestPredictionStore_Ping_ExpectOK() {
+	// WHEN
+	err := s.predictionStore.Ping(context.Background())
+
+	// THEN
+	s.Assert().NoError(err)
+}
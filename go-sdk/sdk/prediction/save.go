@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var (
@@ -12,6 +14,12 @@ var (
 )
 
 func (r *RedisPredictionStore) Save(ctx context.Context, predictionID string, payload Payload) error {
+	ctx, span := r.startSpan(ctx, "Save", attribute.String("kai.prediction.id", predictionID))
+	defer span.End()
+
+	ctx, cancel := r.withStoreContext(ctx)
+	defer cancel()
+
 	if predictionID == "" {
 		return ErrInvalidPredictionID
 	}
@@ -1,6 +1,7 @@
 package prediction
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -8,6 +9,10 @@ import (
 	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
 	"github.com/redis/go-redis/v9"
 	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
 )
 
 var (
@@ -19,20 +24,80 @@ type RedisPredictionStore struct {
 	requestID string
 	client    *redis.Client
 	metadata  *metadata.Metadata
+	config    *viper.Viper
+	ctx       context.Context
 }
 
 func NewRedisPredictionStore(requestID string) *RedisPredictionStore {
+	return NewRedisPredictionStoreWithConfig(requestID, nil)
+}
+
+// NewRedisPredictionStoreWithConfig creates a RedisPredictionStore reading from the given viper
+// instance instead of the global one.
+func NewRedisPredictionStoreWithConfig(requestID string, config *viper.Viper) *RedisPredictionStore {
+	cfg := common.ConfigOrGlobal(config)
+
 	opts := &redis.Options{
-		Addr:     viper.GetString(common.ConfigRedisEndpointKey),
-		Username: viper.GetString(common.ConfigRedisUsernameKey),
-		Password: viper.GetString(common.ConfigRedisPasswordKey),
+		Addr:     cfg.GetString(common.ConfigRedisEndpointKey),
+		Username: cfg.GetString(common.ConfigRedisUsernameKey),
+		Password: cfg.GetString(common.ConfigRedisPasswordKey),
 	}
 
 	return &RedisPredictionStore{
 		client:    redis.NewClient(opts),
-		metadata:  metadata.New(),
+		metadata:  metadata.NewWithConfig(config),
 		requestID: requestID,
+		config:    config,
+	}
+}
+
+// WithContext returns a copy of the store whose requests are also cancelled with the given context,
+// besides the one passed to each method.
+func (r *RedisPredictionStore) WithContext(ctx context.Context) *RedisPredictionStore {
+	store := *r
+	store.ctx = ctx
+
+	return &store
+}
+
+// withStoreContext returns a context cancelled when either the given context or the store one is done.
+func (r *RedisPredictionStore) withStoreContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.ctx == nil {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(r.ctx, func() {
+		cancel(context.Cause(r.ctx))
+	})
+
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
+// startSpan starts the span of an operation of the store. It is a child of the span of the given
+// context or, if it has none, of the store one.
+func (r *RedisPredictionStore) startSpan(ctx context.Context, operation string,
+	attrs ...attribute.KeyValue,
+) (context.Context, trace.Span) {
+	if !trace.SpanContextFromContext(ctx).IsValid() && r.ctx != nil {
+		ctx = trace.ContextWithSpanContext(ctx, trace.SpanContextFromContext(r.ctx))
 	}
+
+	attrs = append(attrs, attribute.String("db.system", "redis"), attribute.String("kai.request_id", r.requestID))
+
+	return tracing.Tracer().Start(ctx, "prediction."+operation, trace.WithAttributes(attrs...))
+}
+
+// Ping checks that Redis is reachable.
+func (r *RedisPredictionStore) Ping(ctx context.Context) error {
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		return fmt.Errorf("error reaching the prediction store: %w", err)
+	}
+
+	return nil
 }
 
 func (r *RedisPredictionStore) getKeyWithProductPrefix(key string) string {
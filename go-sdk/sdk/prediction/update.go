@@ -3,11 +3,19 @@ package prediction
 import (
 	"context"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type UpdatePayloadFunc func(Payload) Payload
 
 func (r *RedisPredictionStore) Update(ctx context.Context, predictionID string, updatePayload UpdatePayloadFunc) error {
+	ctx, span := r.startSpan(ctx, "Update", attribute.String("kai.prediction.id", predictionID))
+	defer span.End()
+
+	ctx, cancel := r.withStoreContext(ctx)
+	defer cancel()
+
 	prediction, err := r.Get(ctx, predictionID)
 	if err != nil {
 		return err
@@ -0,0 +1,31 @@
+package sdk
+
+import "errors"
+
+type retryableError struct {
+	err error
+}
+
+func (e retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e retryableError) Unwrap() error {
+	return e.err
+}
+
+// RetryableError marks an error returned by a handler as transient, so the runner redelivers
+// the message according to its retry policy instead of failing it right away.
+func RetryableError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return retryableError{err: err}
+}
+
+// IsRetryable reports whether the given error, or any error it wraps, was marked as retryable.
+func IsRetryable(err error) bool {
+	var retryable retryableError
+	return errors.As(err, &retryable)
+}
@@ -0,0 +1,80 @@
+package sdktest
+
+import (
+	"fmt"
+	"sync"
+
+	centralizedConfiguration "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/centralized-configuration"
+)
+
+// CentralizedConfig keeps one in-memory key-value store per scope.
+type CentralizedConfig struct {
+	mu     sync.RWMutex
+	scopes map[centralizedConfiguration.Scope]map[string]string
+}
+
+func NewCentralizedConfig() *CentralizedConfig {
+	return &CentralizedConfig{
+		scopes: map[centralizedConfiguration.Scope]map[string]string{
+			centralizedConfiguration.GlobalScope:   {},
+			centralizedConfiguration.ProductScope:  {},
+			centralizedConfiguration.WorkflowScope: {},
+			centralizedConfiguration.ProcessScope:  {},
+		},
+	}
+}
+
+func (cc *CentralizedConfig) GetConfig(key string, scopeOpt ...centralizedConfiguration.Scope) (string, error) {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+
+	scopes := []centralizedConfiguration.Scope{
+		centralizedConfiguration.ProcessScope,
+		centralizedConfiguration.WorkflowScope,
+		centralizedConfiguration.ProductScope,
+		centralizedConfiguration.GlobalScope,
+	}
+
+	if len(scopeOpt) > 0 {
+		scopes = scopeOpt[:1]
+	}
+
+	for _, scope := range scopes {
+		if value, ok := cc.scopedConfig(scope)[key]; ok {
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("configuration get: %w: %q", centralizedConfiguration.ErrKeyNotFound, key)
+}
+
+func (cc *CentralizedConfig) SetConfig(key, value string, scopeOpt ...centralizedConfiguration.Scope) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	scope := centralizedConfiguration.ProcessScope
+	if len(scopeOpt) > 0 {
+		scope = scopeOpt[0]
+	}
+
+	cc.scopedConfig(scope)[key] = value
+
+	return nil
+}
+
+func (cc *CentralizedConfig) DeleteConfig(key string, scope centralizedConfiguration.Scope) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	delete(cc.scopedConfig(scope), key)
+
+	return nil
+}
+
+func (cc *CentralizedConfig) scopedConfig(scope centralizedConfiguration.Scope) map[string]string {
+	if config, ok := cc.scopes[scope]; ok {
+		return config
+	}
+
+	return cc.scopes[centralizedConfiguration.ProcessScope]
+}
@@ -0,0 +1,124 @@
+package sdktest
+
+import (
+	"fmt"
+	regexp2 "regexp"
+	"sort"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+)
+
+// EphemeralStorage keeps objects in memory instead of the NATS object store.
+type EphemeralStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]byte
+}
+
+func NewEphemeralStorage() *EphemeralStorage {
+	return &EphemeralStorage{
+		objects: make(map[string][]byte),
+	}
+}
+
+func (es *EphemeralStorage) Save(key string, payload []byte, overwrite ...bool) error {
+	overwriteValue := false
+	if len(overwrite) > 0 {
+		overwriteValue = overwrite[0]
+	}
+
+	if len(payload) == 0 {
+		return errors.ErrEmptyPayload
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if _, ok := es.objects[key]; ok && !overwriteValue {
+		return errors.ErrObjectAlreadyExists
+	}
+
+	es.objects[key] = append([]byte(nil), payload...)
+
+	return nil
+}
+
+func (es *EphemeralStorage) Get(key string) ([]byte, error) {
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	payload, ok := es.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("error retrieving object for key %s from the ephemeral storage: %w", key, nats.ErrObjectNotFound)
+	}
+
+	return append([]byte(nil), payload...), nil
+}
+
+func (es *EphemeralStorage) List(regexp ...string) ([]string, error) {
+	pattern, err := compilePattern(regexp)
+	if err != nil {
+		return nil, err
+	}
+
+	es.mu.RLock()
+	defer es.mu.RUnlock()
+
+	var response []string
+
+	for key := range es.objects {
+		if pattern == nil || pattern.MatchString(key) {
+			response = append(response, key)
+		}
+	}
+
+	sort.Strings(response)
+
+	return response, nil
+}
+
+func (es *EphemeralStorage) Delete(key string) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	if _, ok := es.objects[key]; !ok {
+		return fmt.Errorf("error deleting object with key %s from the ephemeral storage: %w", key, nats.ErrObjectNotFound)
+	}
+
+	delete(es.objects, key)
+
+	return nil
+}
+
+func (es *EphemeralStorage) Purge(regexp ...string) error {
+	pattern, err := compilePattern(regexp)
+	if err != nil {
+		return err
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+
+	for key := range es.objects {
+		if pattern == nil || pattern.MatchString(key) {
+			delete(es.objects, key)
+		}
+	}
+
+	return nil
+}
+
+func compilePattern(regexp []string) (*regexp2.Regexp, error) {
+	if len(regexp) == 0 || regexp[0] == "" {
+		return nil, nil
+	}
+
+	pattern, err := regexp2.Compile(regexp[0])
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regexp: %w", err)
+	}
+
+	return pattern, nil
+}
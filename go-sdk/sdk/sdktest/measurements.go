@@ -0,0 +1,48 @@
+package sdktest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/metric"
+	sdkMetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// Measurements exposes a meter backed by a manual reader, so the metrics
+// recorded by a handler can be collected and asserted in tests.
+type Measurements struct {
+	reader   *sdkMetric.ManualReader
+	provider *sdkMetric.MeterProvider
+}
+
+func NewMeasurements() *Measurements {
+	reader := sdkMetric.NewManualReader()
+
+	return &Measurements{
+		reader:   reader,
+		provider: sdkMetric.NewMeterProvider(sdkMetric.WithReader(reader)),
+	}
+}
+
+func (m *Measurements) GetMetricsClient() metric.Meter {
+	return m.provider.Meter("measurements")
+}
+
+// Flush does nothing, since metrics are only gathered when collected.
+func (m *Measurements) Flush(_ context.Context) error {
+	return nil
+}
+
+// Shutdown does nothing, so the metrics recorded can still be collected once the runner stops.
+func (m *Measurements) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// Collect gathers every metric recorded so far.
+func (m *Measurements) Collect(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+
+	err := m.reader.Collect(ctx, &rm)
+
+	return rm, err
+}
@@ -0,0 +1,282 @@
+package sdktest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/compression"
+)
+
+// SentMessage is a message published through the fake messaging, together with
+// the channel it was sent to ("" for the default output).
+type SentMessage struct {
+	Channel     string
+	Message     *kai.KaiNatsMessage
+	EndOfStream bool
+}
+
+type messageRecorder struct {
+	mu       sync.Mutex
+	messages []SentMessage
+	sendErr  error
+	// asyncErrs holds the errors of the messages sent asynchronously since the last flush.
+	asyncErrs []error
+}
+
+// Messaging records every message sent instead of publishing it to NATS. Messages sent
+// asynchronously are acknowledged right away.
+type Messaging struct {
+	recorder       *messageRecorder
+	metadata       *Metadata
+	requestMessage *kai.KaiNatsMessage
+}
+
+func NewMessaging(metadata *Metadata) *Messaging {
+	return &Messaging{
+		recorder: &messageRecorder{},
+		metadata: metadata,
+	}
+}
+
+func (ms *Messaging) withRequest(requestMsg *kai.KaiNatsMessage) *Messaging {
+	return &Messaging{
+		recorder:       ms.recorder,
+		metadata:       ms.metadata,
+		requestMessage: requestMsg,
+	}
+}
+
+func (ms *Messaging) SendOutput(response proto.Message, channelOpt ...string) error {
+	return ms.SendOutputWithRequestID(response, ms.requestMessage.GetRequestId(), channelOpt...)
+}
+
+func (ms *Messaging) SendOutputWithRequestID(response proto.Message, requestID string, channelOpt ...string) error {
+	payload, err := anypb.New(response)
+	if err != nil {
+		return fmt.Errorf("the handler result is not a valid protobuf: %w", err)
+	}
+
+	return ms.SendAnyWithRequestID(payload, requestID, channelOpt...)
+}
+
+func (ms *Messaging) SendAny(response *anypb.Any, channelOpt ...string) error {
+	return ms.SendAnyWithRequestID(response, ms.requestMessage.GetRequestId(), channelOpt...)
+}
+
+func (ms *Messaging) SendAnyWithRequestID(response *anypb.Any, requestID string, channelOpt ...string) error {
+	if requestID == "" {
+		requestID = uuid.New().String()
+	}
+
+	return ms.record(&kai.KaiNatsMessage{
+		RequestId:   requestID,
+		Payload:     response,
+		FromNode:    ms.metadata.GetProcess(),
+		MessageType: kai.MessageType_OK,
+	}, channelOpt)
+}
+
+func (ms *Messaging) SendError(errorMessage string, channelOpt ...string) error {
+	return ms.record(&kai.KaiNatsMessage{
+		RequestId:   ms.requestMessage.GetRequestId(),
+		Error:       errorMessage,
+		FromNode:    ms.metadata.GetProcess(),
+		MessageType: kai.MessageType_ERROR,
+	}, channelOpt)
+}
+
+func (ms *Messaging) SendEndOfStream(channelOpt ...string) error {
+	return ms.recordSent(SentMessage{
+		Message: &kai.KaiNatsMessage{
+			RequestId:   ms.requestMessage.GetRequestId(),
+			FromNode:    ms.metadata.GetProcess(),
+			MessageType: kai.MessageType_OK,
+		},
+		EndOfStream: true,
+	}, channelOpt)
+}
+
+func (ms *Messaging) SendOutputAsync(response proto.Message, channelOpt ...string) (nats.PubAckFuture, error) {
+	payload, err := anypb.New(response)
+	if err != nil {
+		return nil, fmt.Errorf("the handler result is not a valid protobuf: %w", err)
+	}
+
+	return ms.SendAnyAsync(payload, channelOpt...)
+}
+
+// SendAnyAsync records the message right away and returns a future already acknowledged or, if
+// the sends fail, already failed with the error given to FailSends.
+func (ms *Messaging) SendAnyAsync(response *anypb.Any, channelOpt ...string) (nats.PubAckFuture, error) {
+	err := ms.SendAny(response, channelOpt...)
+	if err != nil {
+		ms.recorder.mu.Lock()
+		ms.recorder.asyncErrs = append(ms.recorder.asyncErrs, err)
+		ms.recorder.mu.Unlock()
+	}
+
+	msg := nats.NewMsg("")
+	if len(channelOpt) > 0 {
+		msg.Subject = channelOpt[0]
+	}
+
+	return newPubAckFuture(msg, err), nil
+}
+
+// Flush returns the errors of the messages sent asynchronously since the last flush, which are
+// acknowledged, or failed, as soon as they are sent.
+func (ms *Messaging) Flush(_ context.Context) error {
+	ms.recorder.mu.Lock()
+	defer ms.recorder.mu.Unlock()
+
+	errs := ms.recorder.asyncErrs
+	ms.recorder.asyncErrs = nil
+
+	return errors.Join(errs...)
+}
+
+func (ms *Messaging) GetErrorMessage() string {
+	if ms.IsMessageError() {
+		return ms.requestMessage.GetError()
+	}
+
+	return ""
+}
+
+func (ms *Messaging) GetRequestID(msg *nats.Msg) (string, error) {
+	data, err := compression.Decompress(msg.Header, msg.Data)
+	if err != nil {
+		return "", err
+	}
+
+	requestMsg := &kai.KaiNatsMessage{}
+
+	err = proto.Unmarshal(data, requestMsg)
+	if err != nil {
+		return "", err
+	}
+
+	return requestMsg.GetRequestId(), nil
+}
+
+func (ms *Messaging) IsMessageOK() bool {
+	return ms.requestMessage.GetMessageType() == kai.MessageType_OK
+}
+
+func (ms *Messaging) IsMessageError() bool {
+	return ms.requestMessage.GetMessageType() == kai.MessageType_ERROR
+}
+
+// Sent returns every message sent so far, in order.
+func (ms *Messaging) Sent() []SentMessage {
+	ms.recorder.mu.Lock()
+	defer ms.recorder.mu.Unlock()
+
+	sent := make([]SentMessage, len(ms.recorder.messages))
+	copy(sent, ms.recorder.messages)
+
+	return sent
+}
+
+// Outputs returns the messages sent with SendOutput or SendAny.
+func (ms *Messaging) Outputs() []SentMessage {
+	return ms.filter(kai.MessageType_OK)
+}
+
+// Errors returns the messages sent with SendError.
+func (ms *Messaging) Errors() []SentMessage {
+	return ms.filter(kai.MessageType_ERROR)
+}
+
+// Reset discards every recorded message.
+func (ms *Messaging) Reset() {
+	ms.recorder.mu.Lock()
+	defer ms.recorder.mu.Unlock()
+
+	ms.recorder.messages = nil
+}
+
+// FailSends makes every following send fail with the given error without recording the message,
+// until it is called with nil.
+func (ms *Messaging) FailSends(err error) {
+	ms.recorder.mu.Lock()
+	defer ms.recorder.mu.Unlock()
+
+	ms.recorder.sendErr = err
+}
+
+func (ms *Messaging) filter(messageType kai.MessageType) []SentMessage {
+	var filtered []SentMessage
+
+	for _, sent := range ms.Sent() {
+		if sent.Message.GetMessageType() == messageType && !sent.EndOfStream {
+			filtered = append(filtered, sent)
+		}
+	}
+
+	return filtered
+}
+
+func (ms *Messaging) record(msg *kai.KaiNatsMessage, channelOpt []string) error {
+	return ms.recordSent(SentMessage{Message: msg}, channelOpt)
+}
+
+func (ms *Messaging) recordSent(sent SentMessage, channelOpt []string) error {
+	if len(channelOpt) > 0 {
+		sent.Channel = channelOpt[0]
+	}
+
+	ms.recorder.mu.Lock()
+	defer ms.recorder.mu.Unlock()
+
+	if ms.recorder.sendErr != nil {
+		return ms.recorder.sendErr
+	}
+
+	ms.recorder.messages = append(ms.recorder.messages, sent)
+
+	return nil
+}
+
+// pubAckFuture is the future of a message whose acknowledgement, or error, was already received.
+type pubAckFuture struct {
+	msg *nats.Msg
+	ok  chan *nats.PubAck
+	err chan error
+}
+
+func newPubAckFuture(msg *nats.Msg, err error) *pubAckFuture {
+	future := &pubAckFuture{
+		msg: msg,
+		ok:  make(chan *nats.PubAck, 1),
+		err: make(chan error, 1),
+	}
+
+	if err != nil {
+		future.err <- err
+	} else {
+		future.ok <- &nats.PubAck{}
+	}
+
+	return future
+}
+
+func (f *pubAckFuture) Ok() <-chan *nats.PubAck {
+	return f.ok
+}
+
+func (f *pubAckFuture) Err() <-chan error {
+	return f.err
+}
+
+func (f *pubAckFuture) Msg() *nats.Msg {
+	return f.msg
+}
@@ -0,0 +1,75 @@
+package sdktest
+
+type Metadata struct {
+	Product                              string
+	Workflow                             string
+	WorkflowType                         string
+	Process                              string
+	ProcessType                          string
+	Version                              string
+	EphemeralStorageName                 string
+	GlobalCentralizedConfigurationName   string
+	ProductCentralizedConfigurationName  string
+	WorkflowCentralizedConfigurationName string
+	ProcessCentralizedConfigurationName  string
+}
+
+func NewMetadata() *Metadata {
+	return &Metadata{
+		Product:                              "test-product",
+		Workflow:                             "test-workflow",
+		WorkflowType:                         "data",
+		Process:                              "test-process",
+		ProcessType:                          "task",
+		Version:                              "v1.0.0",
+		EphemeralStorageName:                 "test-ephemeral-storage",
+		GlobalCentralizedConfigurationName:   "test-global",
+		ProductCentralizedConfigurationName:  "test-product",
+		WorkflowCentralizedConfigurationName: "test-workflow",
+		ProcessCentralizedConfigurationName:  "test-process",
+	}
+}
+
+func (md *Metadata) GetProduct() string {
+	return md.Product
+}
+
+func (md *Metadata) GetWorkflow() string {
+	return md.Workflow
+}
+
+func (md *Metadata) GetWorkflowType() string {
+	return md.WorkflowType
+}
+
+func (md *Metadata) GetProcess() string {
+	return md.Process
+}
+
+func (md *Metadata) GetProcessType() string {
+	return md.ProcessType
+}
+
+func (md *Metadata) GetVersion() string {
+	return md.Version
+}
+
+func (md *Metadata) GetEphemeralStorageName() string {
+	return md.EphemeralStorageName
+}
+
+func (md *Metadata) GetGlobalCentralizedConfigurationName() string {
+	return md.GlobalCentralizedConfigurationName
+}
+
+func (md *Metadata) GetProductCentralizedConfigurationName() string {
+	return md.ProductCentralizedConfigurationName
+}
+
+func (md *Metadata) GetWorkflowCentralizedConfigurationName() string {
+	return md.WorkflowCentralizedConfigurationName
+}
+
+func (md *Metadata) GetProcessCentralizedConfigurationName() string {
+	return md.ProcessCentralizedConfigurationName
+}
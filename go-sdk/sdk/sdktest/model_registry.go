@@ -0,0 +1,140 @@
+package sdktest
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+	modelregistry "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/model-registry"
+)
+
+// ModelRegistry keeps every registered model version in memory instead of MinIO.
+type ModelRegistry struct {
+	mu     sync.RWMutex
+	models map[string][]*modelregistry.Model
+}
+
+func NewModelRegistry() *ModelRegistry {
+	return &ModelRegistry{
+		models: make(map[string][]*modelregistry.Model),
+	}
+}
+
+func (mr *ModelRegistry) RegisterModel(model []byte, name, version, modelFormat string, description ...string) error {
+	if name == "" {
+		return errors.ErrEmptyName
+	}
+
+	modelDescription := ""
+	if len(description) > 0 {
+		modelDescription = description[0]
+	}
+
+	if _, err := semver.NewVersion(version); err != nil {
+		return errors.ErrInvalidVersion
+	}
+
+	if len(model) == 0 {
+		return errors.ErrEmptyModel
+	}
+
+	if _, err := mr.GetModel(name, version); err == nil {
+		return errors.ErrModelAlreadyExists
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	mr.models[name] = append(mr.models[name], &modelregistry.Model{
+		ModelInfo: modelregistry.ModelInfo{
+			Name:        name,
+			Version:     version,
+			Description: modelDescription,
+			Format:      modelFormat,
+		},
+		Model: append([]byte(nil), model...),
+	})
+
+	return nil
+}
+
+func (mr *ModelRegistry) GetModel(name string, version ...string) (*modelregistry.Model, error) {
+	if name == "" {
+		return nil, errors.ErrEmptyName
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	versions := mr.models[name]
+	if len(versions) == 0 {
+		return nil, errors.ErrModelNotFound
+	}
+
+	if len(version) == 0 {
+		return versions[len(versions)-1], nil
+	}
+
+	if _, err := semver.NewVersion(version[0]); err != nil {
+		return nil, errors.ErrInvalidVersion
+	}
+
+	for _, model := range versions {
+		if model.Version == version[0] {
+			return model, nil
+		}
+	}
+
+	return nil, errors.ErrModelNotFound
+}
+
+func (mr *ModelRegistry) ListModels() ([]*modelregistry.ModelInfo, error) {
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	modelInfoList := make([]*modelregistry.ModelInfo, 0, len(mr.models))
+
+	for _, versions := range mr.models {
+		info := versions[len(versions)-1].ModelInfo
+		modelInfoList = append(modelInfoList, &info)
+	}
+
+	sort.Slice(modelInfoList, func(i, j int) bool {
+		return modelInfoList[i].Name < modelInfoList[j].Name
+	})
+
+	return modelInfoList, nil
+}
+
+func (mr *ModelRegistry) ListModelVersions(name string) ([]*modelregistry.ModelInfo, error) {
+	if name == "" {
+		return nil, errors.ErrEmptyName
+	}
+
+	mr.mu.RLock()
+	defer mr.mu.RUnlock()
+
+	var modelInfoList []*modelregistry.ModelInfo
+
+	for _, model := range mr.models[name] {
+		info := model.ModelInfo
+		modelInfoList = append(modelInfoList, &info)
+	}
+
+	return modelInfoList, nil
+}
+
+func (mr *ModelRegistry) DeleteModel(name string) error {
+	if name == "" {
+		return errors.ErrEmptyName
+	}
+
+	mr.mu.Lock()
+	defer mr.mu.Unlock()
+
+	delete(mr.models, name)
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package sdktest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	utilErrors "github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+	persistentstorage "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/persistent-storage"
+)
+
+var ErrObjectNotFound = errors.New("object not found in the persistent storage")
+
+const _internalFolder = ".kai"
+
+type objectVersion struct {
+	info persistentstorage.ObjectInfo
+	data []byte
+}
+
+// PersistentStorage keeps versioned objects in memory instead of MinIO.
+type PersistentStorage struct {
+	mu      sync.RWMutex
+	objects map[string][]objectVersion
+}
+
+func NewPersistentStorage() *PersistentStorage {
+	return &PersistentStorage{
+		objects: make(map[string][]objectVersion),
+	}
+}
+
+func (ps *PersistentStorage) Save(key string, payload []byte, ttlDays ...int) (*persistentstorage.ObjectInfo, error) {
+	if key == "" {
+		return nil, utilErrors.ErrEmptyKey
+	}
+
+	if strings.HasPrefix(key, _internalFolder) {
+		return nil, utilErrors.ErrInvalidKey
+	}
+
+	if len(payload) == 0 {
+		return nil, utilErrors.ErrEmptyPayload
+	}
+
+	info := persistentstorage.ObjectInfo{
+		Key:       key,
+		VersionID: uuid.New().String(),
+	}
+
+	if len(ttlDays) > 0 && ttlDays[0] > 0 {
+		info.ExpiresIn = time.Now().AddDate(0, 0, ttlDays[0])
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.objects[key] = append(ps.objects[key], objectVersion{
+		info: info,
+		data: append([]byte(nil), payload...),
+	})
+
+	return &info, nil
+}
+
+func (ps *PersistentStorage) Get(key string, version ...string) (*persistentstorage.Object, error) {
+	if key == "" {
+		return nil, utilErrors.ErrEmptyKey
+	}
+
+	if strings.HasPrefix(key, _internalFolder) {
+		return nil, utilErrors.ErrInvalidKey
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	versions := ps.objects[key]
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("error retrieving object from the persistent storage: %w", ErrObjectNotFound)
+	}
+
+	if len(version) == 0 || version[0] == "" {
+		latest := versions[len(versions)-1]
+		return persistentstorage.NewObject(latest.info, latest.data), nil
+	}
+
+	for _, v := range versions {
+		if v.info.VersionID == version[0] {
+			return persistentstorage.NewObject(v.info, v.data), nil
+		}
+	}
+
+	return nil, fmt.Errorf("error retrieving object from the persistent storage: %w", ErrObjectNotFound)
+}
+
+func (ps *PersistentStorage) List() ([]*persistentstorage.ObjectInfo, error) {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	objectList := make([]*persistentstorage.ObjectInfo, 0, len(ps.objects))
+
+	for _, versions := range ps.objects {
+		latest := versions[len(versions)-1].info
+		objectList = append(objectList, &latest)
+	}
+
+	sort.Slice(objectList, func(i, j int) bool {
+		return objectList[i].Key < objectList[j].Key
+	})
+
+	return objectList, nil
+}
+
+func (ps *PersistentStorage) ListVersions(key string) ([]*persistentstorage.ObjectInfo, error) {
+	if key == "" {
+		return nil, utilErrors.ErrEmptyKey
+	}
+
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	var objectList []*persistentstorage.ObjectInfo
+
+	for _, v := range ps.objects[key] {
+		info := v.info
+		objectList = append(objectList, &info)
+	}
+
+	return objectList, nil
+}
+
+func (ps *PersistentStorage) Delete(key string, version ...string) error {
+	if key == "" {
+		return utilErrors.ErrEmptyKey
+	}
+
+	if strings.HasPrefix(key, _internalFolder) {
+		return utilErrors.ErrInvalidKey
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if len(version) == 0 || version[0] == "" {
+		delete(ps.objects, key)
+		return nil
+	}
+
+	versions := ps.objects[key]
+	for i, v := range versions {
+		if v.info.VersionID == version[0] {
+			ps.objects[key] = append(versions[:i:i], versions[i+1:]...)
+			break
+		}
+	}
+
+	if len(ps.objects[key]) == 0 {
+		delete(ps.objects, key)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package sdktest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/prediction"
+)
+
+type predictionRecords struct {
+	mu          sync.RWMutex
+	predictions map[string]prediction.Prediction
+}
+
+// Predictions keeps predictions in memory instead of Redis. Like the Redis
+// store, every prediction is stamped with the process metadata and the id of
+// the request being handled.
+type Predictions struct {
+	records   *predictionRecords
+	metadata  *Metadata
+	requestID string
+}
+
+func NewPredictions(metadata *Metadata) *Predictions {
+	return &Predictions{
+		records: &predictionRecords{
+			predictions: make(map[string]prediction.Prediction),
+		},
+		metadata: metadata,
+	}
+}
+
+func (p *Predictions) withRequestID(requestID string) *Predictions {
+	return &Predictions{
+		records:   p.records,
+		metadata:  p.metadata,
+		requestID: requestID,
+	}
+}
+
+func (p *Predictions) Save(_ context.Context, predictionID string, payload prediction.Payload) error {
+	if predictionID == "" {
+		return prediction.ErrInvalidPredictionID
+	}
+
+	if payload == nil {
+		return prediction.ErrEmptyPayload
+	}
+
+	p.records.mu.Lock()
+	defer p.records.mu.Unlock()
+
+	p.records.predictions[predictionID] = prediction.Prediction{
+		CreationDate: time.Now().UnixMilli(),
+		LastModified: time.Now().UnixMilli(),
+		Payload:      payload,
+		Metadata: prediction.Metadata{
+			Product:      p.metadata.GetProduct(),
+			Version:      p.metadata.GetVersion(),
+			Workflow:     p.metadata.GetWorkflow(),
+			WorkflowType: p.metadata.GetWorkflowType(),
+			Process:      p.metadata.GetProcess(),
+			RequestID:    p.requestID,
+		},
+	}
+
+	return nil
+}
+
+func (p *Predictions) Get(_ context.Context, predictionID string) (*prediction.Prediction, error) {
+	p.records.mu.RLock()
+	defer p.records.mu.RUnlock()
+
+	pred, ok := p.records.predictions[predictionID]
+	if !ok || pred.Metadata.Product != p.metadata.GetProduct() {
+		return nil, prediction.ErrPredictionNotFound
+	}
+
+	return &pred, nil
+}
+
+func (p *Predictions) Find(_ context.Context, filter *prediction.Filter) ([]prediction.Prediction, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	p.records.mu.RLock()
+	defer p.records.mu.RUnlock()
+
+	predictions := make([]prediction.Prediction, 0)
+
+	for _, pred := range p.records.predictions {
+		if p.matches(pred, filter) {
+			predictions = append(predictions, pred)
+		}
+	}
+
+	sort.Slice(predictions, func(i, j int) bool {
+		return predictions[i].CreationDate < predictions[j].CreationDate
+	})
+
+	return predictions, nil
+}
+
+func (p *Predictions) Update(ctx context.Context, predictionID string, updatePayload prediction.UpdatePayloadFunc) error {
+	pred, err := p.Get(ctx, predictionID)
+	if err != nil {
+		return err
+	}
+
+	updatedPayload := updatePayload(pred.Payload)
+	if updatedPayload == nil {
+		return prediction.ErrEmptyPayload
+	}
+
+	pred.Payload = updatedPayload
+	pred.LastModified = time.Now().UnixMilli()
+
+	p.records.mu.Lock()
+	defer p.records.mu.Unlock()
+
+	p.records.predictions[predictionID] = *pred
+
+	return nil
+}
+
+func (p *Predictions) Delete(_ context.Context, predictionID string) error {
+	if predictionID == "" {
+		return prediction.ErrInvalidPredictionID
+	}
+
+	p.records.mu.Lock()
+	defer p.records.mu.Unlock()
+
+	if _, ok := p.records.predictions[predictionID]; !ok {
+		return prediction.ErrPredictionNotFound
+	}
+
+	delete(p.records.predictions, predictionID)
+
+	return nil
+}
+
+func (p *Predictions) matches(pred prediction.Prediction, filter *prediction.Filter) bool {
+	version := filter.Version
+	if version == "" {
+		version = p.metadata.GetVersion()
+	}
+
+	switch {
+	case pred.Metadata.Product != p.metadata.GetProduct(),
+		pred.Metadata.Version != version,
+		pred.CreationDate < filter.CreationDate.StartDate.UnixMilli(),
+		pred.CreationDate > filter.CreationDate.EndDate.UnixMilli(),
+		filter.Workflow != "" && pred.Metadata.Workflow != filter.Workflow,
+		filter.WorkflowType != "" && pred.Metadata.WorkflowType != filter.WorkflowType,
+		filter.Process != "" && pred.Metadata.Process != filter.Process,
+		filter.RequestID != "" && pred.Metadata.RequestID != filter.RequestID:
+		return false
+	default:
+		return true
+	}
+}
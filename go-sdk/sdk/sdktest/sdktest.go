@@ -0,0 +1,89 @@
+// Package sdktest provides an in-memory KaiSDK for unit testing handlers
+// without NATS, MinIO or Redis.
+//
+// A typical handler test looks like:
+//
+//	fake := sdktest.New()
+//	err := myHandler(fake.KaiSDK(), payload)
+//	outputs := fake.Messaging.Outputs()
+package sdktest
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/google/uuid"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/health"
+)
+
+// SDK groups the in-memory implementations backing a KaiSDK. Every
+// KaiSDK built from the same SDK shares its state, so the values stored or
+// sent by a handler can be asserted after it returns.
+type SDK struct {
+	Logger            logr.Logger
+	Metadata          *Metadata
+	Messaging         *Messaging
+	EphemeralStorage  *EphemeralStorage
+	PersistentStorage *PersistentStorage
+	CentralizedConfig *CentralizedConfig
+	ModelRegistry     *ModelRegistry
+	Predictions       *Predictions
+	Measurements      *Measurements
+	Tracing           *Tracing
+	// Health holds the checks registered by the code under test, which can be run with its
+	// Liveness and Readiness methods.
+	Health *health.Health
+}
+
+func New() *SDK {
+	metadata := NewMetadata()
+
+	return &SDK{
+		Logger:            logr.Discard(),
+		Metadata:          metadata,
+		Messaging:         NewMessaging(metadata),
+		EphemeralStorage:  NewEphemeralStorage(),
+		PersistentStorage: NewPersistentStorage(),
+		CentralizedConfig: NewCentralizedConfig(),
+		ModelRegistry:     NewModelRegistry(),
+		Predictions:       NewPredictions(metadata),
+		Measurements:      NewMeasurements(),
+		Tracing:           NewTracing(),
+		Health:            health.New(0),
+	}
+}
+
+// KaiSDK returns a KaiSDK handling a new request with a random request id.
+func (s *SDK) KaiSDK() sdk.KaiSDK {
+	return s.KaiSDKWithRequest(&kai.KaiNatsMessage{
+		RequestId:   uuid.New().String(),
+		MessageType: kai.MessageType_OK,
+	})
+}
+
+// KaiSDKWithRequest returns a KaiSDK handling the given request message, as the
+// runners do before calling a handler.
+func (s *SDK) KaiSDKWithRequest(requestMsg *kai.KaiNatsMessage) sdk.KaiSDK {
+	baseSdk := sdk.KaiSDK{
+		Logger:            s.Logger,
+		Metadata:          s.Metadata,
+		Messaging:         s.Messaging,
+		ModelRegistry:     s.ModelRegistry,
+		CentralizedConfig: s.CentralizedConfig,
+		Measurements:      s.Measurements,
+		Tracing:           s.Tracing,
+		Storage: sdk.Storage{
+			Ephemeral:  s.EphemeralStorage,
+			Persistent: s.PersistentStorage,
+		},
+		Predictions: s.Predictions,
+		Health:      s.Health,
+	}
+
+	hSdk := sdk.ShallowCopyWithRequest(&baseSdk, requestMsg)
+	hSdk.Messaging = s.Messaging.withRequest(requestMsg)
+	hSdk.Predictions = s.Predictions.withRequestID(requestMsg.GetRequestId())
+
+	return hSdk
+}
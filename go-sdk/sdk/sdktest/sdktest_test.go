@@ -0,0 +1,189 @@
+//go:build unit
+
+package sdktest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/protobuf/ptypes/wrappers"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	kai "github.com/konstellation-io/kai-sdk/go-sdk/v2/protos"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk"
+	centralizedConfiguration "github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/centralized-configuration"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+)
+
+type SdkTestTestSuite struct {
+	suite.Suite
+	fake *sdktest.SDK
+}
+
+func (s *SdkTestTestSuite) SetupTest() {
+	s.fake = sdktest.New()
+}
+
+func (s *SdkTestTestSuite) TestKaiSDK_SendOutput_RecordsMessage() {
+	// Given
+	request := &kai.KaiNatsMessage{RequestId: "some-request", MessageType: kai.MessageType_OK}
+	kaiSDK := s.fake.KaiSDKWithRequest(request)
+
+	// When
+	err := kaiSDK.Messaging.SendOutput(&wrappers.StringValue{Value: "some-output"}, "some-channel")
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal("some-request", kaiSDK.GetRequestID())
+
+	outputs := s.fake.Messaging.Outputs()
+	s.Require().Len(outputs, 1)
+	s.Equal("some-channel", outputs[0].Channel)
+	s.Equal("some-request", outputs[0].Message.GetRequestId())
+	s.Equal(s.fake.Metadata.Process, outputs[0].Message.GetFromNode())
+
+	value := &wrappers.StringValue{}
+	s.Require().NoError(outputs[0].Message.GetPayload().UnmarshalTo(value))
+	s.Equal("some-output", value.GetValue())
+}
+
+func (s *SdkTestTestSuite) TestKaiSDK_SendError_RecordsErrorMessage() {
+	// Given
+	kaiSDK := s.fake.KaiSDK()
+
+	// When
+	kaiSDK.Messaging.SendError("some-error")
+
+	// Then
+	s.Empty(s.fake.Messaging.Outputs())
+
+	errs := s.fake.Messaging.Errors()
+	s.Require().Len(errs, 1)
+	s.Equal("some-error", errs[0].Message.GetError())
+	s.Equal(kaiSDK.GetRequestID(), errs[0].Message.GetRequestId())
+	s.Equal(kai.MessageType_ERROR, errs[0].Message.GetMessageType())
+}
+
+func (s *SdkTestTestSuite) TestKaiSDK_SendOutputAsync_ExpectRecordedAndAcknowledged() {
+	// Given
+	kaiSDK := s.fake.KaiSDK()
+
+	// When
+	future, err := kaiSDK.Messaging.SendOutputAsync(&wrappers.StringValue{Value: "some-output"})
+	s.Require().NoError(err)
+
+	// Then
+	s.NotNil(<-future.Ok())
+	s.NoError(kaiSDK.Messaging.Flush(context.Background()))
+	s.Len(s.fake.Messaging.Outputs(), 1)
+}
+
+func (s *SdkTestTestSuite) TestKaiSDK_FailSends_ExpectErrorsAndNothingRecorded() {
+	// Given
+	sendErr := errors.New("publish failed")
+	s.fake.Messaging.FailSends(sendErr)
+
+	kaiSDK := s.fake.KaiSDK()
+
+	// When
+	err := kaiSDK.Messaging.SendOutput(&wrappers.StringValue{Value: "some-output"})
+	_, asyncErr := kaiSDK.Messaging.SendOutputAsync(&wrappers.StringValue{Value: "some-output"})
+
+	// Then
+	s.ErrorIs(err, sendErr)
+	s.NoError(asyncErr)
+	s.ErrorIs(kaiSDK.Messaging.Flush(context.Background()), sendErr)
+	s.Empty(s.fake.Messaging.Sent())
+
+	s.fake.Messaging.FailSends(nil)
+	s.NoError(kaiSDK.Messaging.SendError("some-error"))
+	s.Len(s.fake.Messaging.Errors(), 1)
+}
+
+func (s *SdkTestTestSuite) TestKaiSDK_HandlerUsingSeveralSubsystems_ExpectOK() {
+	// Given
+	handler := func(kaiSDK sdk.KaiSDK, payload *anypb.Any) error {
+		value := &wrappers.StringValue{}
+		if err := payload.UnmarshalTo(value); err != nil {
+			return err
+		}
+
+		if err := kaiSDK.Storage.Ephemeral.Save("last-value", []byte(value.GetValue())); err != nil {
+			return err
+		}
+
+		if err := kaiSDK.Predictions.Save(context.Background(), "some-prediction",
+			map[string]any{"value": value.GetValue()}); err != nil {
+			return err
+		}
+
+		return kaiSDK.Messaging.SendOutput(value)
+	}
+	payload, err := anypb.New(&wrappers.StringValue{Value: "hello"})
+	s.Require().NoError(err)
+
+	kaiSDK := s.fake.KaiSDK()
+
+	// When
+	err = handler(kaiSDK, payload)
+
+	// Then
+	s.Require().NoError(err)
+	s.Len(s.fake.Messaging.Outputs(), 1)
+
+	stored, err := s.fake.EphemeralStorage.Get("last-value")
+	s.Require().NoError(err)
+	s.Equal([]byte("hello"), stored)
+
+	pred, err := s.fake.Predictions.Get(context.Background(), "some-prediction")
+	s.Require().NoError(err)
+	s.Equal(kaiSDK.GetRequestID(), pred.Metadata.RequestID)
+	s.Equal(s.fake.Metadata.Product, pred.Metadata.Product)
+}
+
+func (s *SdkTestTestSuite) TestMessaging_Reset_ExpectEmpty() {
+	// Given
+	s.fake.KaiSDK().Messaging.SendError("some-error")
+
+	// When
+	s.fake.Messaging.Reset()
+
+	// Then
+	s.Empty(s.fake.Messaging.Sent())
+}
+
+func (s *SdkTestTestSuite) TestMeasurements_Collect_ExpectRecordedMetrics() {
+	// Given
+	counter, err := s.fake.KaiSDK().Measurements.GetMetricsClient().Int64Counter("some-counter")
+	s.Require().NoError(err)
+
+	// When
+	counter.Add(context.Background(), 3)
+
+	// Then
+	rm, err := s.fake.Measurements.Collect(context.Background())
+	s.Require().NoError(err)
+	s.Require().Len(rm.ScopeMetrics, 1)
+	s.Equal("some-counter", rm.ScopeMetrics[0].Metrics[0].Name)
+}
+
+func (s *SdkTestTestSuite) TestCentralizedConfig_GetConfig_FallbacksThroughScopes() {
+	// Given
+	kaiSDK := s.fake.KaiSDK()
+	s.Require().NoError(kaiSDK.CentralizedConfig.SetConfig("key", "global-value", centralizedConfiguration.GlobalScope))
+
+	// When
+	value, err := kaiSDK.CentralizedConfig.GetConfig("key")
+	_, missingErr := kaiSDK.CentralizedConfig.GetConfig("missing-key")
+
+	// Then
+	s.Require().NoError(err)
+	s.Equal("global-value", value)
+	s.ErrorIs(missingErr, centralizedConfiguration.ErrKeyNotFound)
+}
+
+func TestSdkTestTestSuite(t *testing.T) {
+	suite.Run(t, new(SdkTestTestSuite))
+}
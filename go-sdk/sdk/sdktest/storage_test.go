@@ -0,0 +1,88 @@
+//go:build unit
+
+package sdktest_test
+
+import (
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/errors"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/sdktest"
+)
+
+func (s *SdkTestTestSuite) TestEphemeralStorage_SaveWithoutOverwrite_ExpectError() {
+	// Given
+	storage := s.fake.KaiSDK().Storage.Ephemeral
+	s.Require().NoError(storage.Save("key", []byte("value")))
+
+	// When
+	err := storage.Save("key", []byte("other-value"))
+
+	// Then
+	s.ErrorIs(err, errors.ErrObjectAlreadyExists)
+}
+
+func (s *SdkTestTestSuite) TestEphemeralStorage_ListAndPurge_ExpectOK() {
+	// Given
+	storage := s.fake.KaiSDK().Storage.Ephemeral
+	s.Require().NoError(storage.Save("image-1", []byte("value")))
+	s.Require().NoError(storage.Save("image-2", []byte("value")))
+	s.Require().NoError(storage.Save("text-1", []byte("value")))
+
+	// When
+	images, listErr := storage.List("^image")
+	purgeErr := storage.Purge("^image")
+	remaining, _ := storage.List()
+
+	// Then
+	s.Require().NoError(listErr)
+	s.Require().NoError(purgeErr)
+	s.Equal([]string{"image-1", "image-2"}, images)
+	s.Equal([]string{"text-1"}, remaining)
+}
+
+func (s *SdkTestTestSuite) TestPersistentStorage_GetVersions_ExpectOK() {
+	// Given
+	storage := s.fake.KaiSDK().Storage.Persistent
+	first, err := storage.Save("key", []byte("first"))
+	s.Require().NoError(err)
+	_, err = storage.Save("key", []byte("second"))
+	s.Require().NoError(err)
+
+	// When
+	latest, latestErr := storage.Get("key")
+	previous, previousErr := storage.Get("key", first.VersionID)
+	versions, versionsErr := storage.ListVersions("key")
+
+	// Then
+	s.Require().NoError(latestErr)
+	s.Require().NoError(previousErr)
+	s.Require().NoError(versionsErr)
+	s.Equal("second", latest.GetAsString())
+	s.Equal("first", previous.GetAsString())
+	s.Len(versions, 2)
+}
+
+func (s *SdkTestTestSuite) TestPersistentStorage_GetMissingObject_ExpectError() {
+	// When
+	_, err := s.fake.KaiSDK().Storage.Persistent.Get("missing-key")
+
+	// Then
+	s.ErrorIs(err, sdktest.ErrObjectNotFound)
+}
+
+func (s *SdkTestTestSuite) TestModelRegistry_RegisterAndGetModel_ExpectOK() {
+	// Given
+	registry := s.fake.KaiSDK().ModelRegistry
+	s.Require().NoError(registry.RegisterModel([]byte("model-v1"), "model", "1.0.0", "onnx"))
+	s.Require().NoError(registry.RegisterModel([]byte("model-v2"), "model", "2.0.0", "onnx"))
+
+	// When
+	latest, latestErr := registry.GetModel("model")
+	previous, previousErr := registry.GetModel("model", "1.0.0")
+	duplicatedErr := registry.RegisterModel([]byte("model-v1"), "model", "1.0.0", "onnx")
+
+	// Then
+	s.Require().NoError(latestErr)
+	s.Require().NoError(previousErr)
+	s.Equal("2.0.0", latest.Version)
+	s.Equal([]byte("model-v1"), previous.Model)
+	s.ErrorIs(duplicatedErr, errors.ErrModelAlreadyExists)
+}
@@ -0,0 +1,46 @@
+package sdktest
+
+import (
+	"context"
+
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+// Tracing exposes a tracer whose spans are kept in memory as soon as they end, so the spans
+// started by a handler can be asserted in tests.
+type Tracing struct {
+	exporter *tracetest.InMemoryExporter
+	provider *sdkTrace.TracerProvider
+}
+
+func NewTracing() *Tracing {
+	exporter := tracetest.NewInMemoryExporter()
+
+	return &Tracing{
+		exporter: exporter,
+		provider: sdkTrace.NewTracerProvider(sdkTrace.WithSyncer(exporter)),
+	}
+}
+
+func (t *Tracing) GetTracer() trace.Tracer {
+	return t.provider.Tracer(tracing.TracerName)
+}
+
+// Flush does nothing, since spans are kept as soon as they end.
+func (t *Tracing) Flush(_ context.Context) error {
+	return nil
+}
+
+// Shutdown does nothing, so the spans kept can still be read once the runner stops.
+func (t *Tracing) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// Spans returns the spans ended so far.
+func (t *Tracing) Spans() tracetest.SpanStubs {
+	return t.exporter.GetSpans()
+}
@@ -0,0 +1,256 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdkTrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
+)
+
+const (
+	_tracingLoggerName = "[TRACING]"
+
+	// TracerName is the instrumentation name of the spans created by the runners and the SDK.
+	TracerName = "github.com/konstellation-io/kai-sdk/go-sdk"
+)
+
+// Exporters of the tracing.exporter configuration.
+const (
+	ExporterOTLPGRPC = "otlp-grpc"
+	ExporterOTLPHTTP = "otlp-http"
+	ExporterStdout   = "stdout"
+	ExporterMemory   = "memory"
+	ExporterNone     = "none"
+)
+
+var ErrUnknownExporter = errors.New("unknown tracing exporter")
+
+// _memoryExporter is shared by every Tracing using the memory exporter, so the spans of all the
+// processes of a workflow run in the same Go process end up together.
+var _memoryExporter = tracetest.NewInMemoryExporter()
+
+// _propagator carries the W3C trace context in the headers of the messages.
+var _propagator = propagation.TraceContext{}
+
+type Tracing struct {
+	logger   logr.Logger
+	provider *sdkTrace.TracerProvider
+	tracer   trace.Tracer
+}
+
+func New(logger logr.Logger, meta *metadata.Metadata) (*Tracing, error) {
+	return NewWithConfig(logger, meta, nil)
+}
+
+// NewWithConfig creates a Tracing reading from the given viper instance instead of the global one.
+// Spans are pushed to an OTLP collector through gRPC unless another tracing.exporter is set:
+// otlp-http, stdout, memory, which keeps them to be read with MemoryExporter, or none. Its
+// provider becomes the global one, used by the storage, model registry and predictions.
+func NewWithConfig(logger logr.Logger, meta *metadata.Metadata, config *viper.Viper) (*Tracing, error) {
+	cfg := common.ConfigOrGlobal(config)
+
+	res, err := initResource(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing tracing resource: %w", err)
+	}
+
+	exporter := cfg.GetString(common.ConfigTracingExporterKey)
+	if exporter == "" {
+		exporter = ExporterOTLPGRPC
+	}
+
+	opt, err := initSpanProcessor(exporter, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("error initializing %s exporter: %w", exporter, err)
+	}
+
+	opts := []sdkTrace.TracerProviderOption{sdkTrace.WithResource(res)}
+	if opt != nil {
+		opts = append(opts, opt)
+	}
+
+	provider := sdkTrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(provider)
+
+	logger.WithName(_tracingLoggerName).Info(fmt.Sprintf("Successfully initialized tracing with the %s exporter", exporter))
+
+	return &Tracing{
+		logger:   logger,
+		provider: provider,
+		tracer:   provider.Tracer(TracerName),
+	}, nil
+}
+
+func (t Tracing) GetTracer() trace.Tracer {
+	return t.tracer
+}
+
+// Flush exports the spans ended so far.
+func (t Tracing) Flush(ctx context.Context) error {
+	return t.provider.ForceFlush(ctx)
+}
+
+// Shutdown exports the spans ended so far and stops the exporter. Spans started afterwards are dropped.
+func (t Tracing) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
+
+// MemoryExporter returns the exporter keeping the spans of the processes using the memory
+// exporter, meant for tests. Its spans are kept until it is reset.
+func MemoryExporter() *tracetest.InMemoryExporter {
+	return _memoryExporter
+}
+
+// Tracer returns the tracer of the global provider, which does nothing unless tracing is enabled.
+func Tracer() trace.Tracer {
+	return otel.Tracer(TracerName)
+}
+
+// Inject adds the trace context of the given context, if any, to the header of a message.
+func Inject(ctx context.Context, header nats.Header) {
+	_propagator.Inject(ctx, headerCarrier(header))
+}
+
+// Extract returns a copy of the given context holding the trace context carried by the header of
+// a message, if any.
+func Extract(ctx context.Context, header nats.Header) context.Context {
+	if header == nil {
+		return ctx
+	}
+
+	return _propagator.Extract(ctx, headerCarrier(header))
+}
+
+// ExtractHTTP works like Extract with the header of an HTTP request, so the requests of an HTTP
+// trigger continue the trace of their caller.
+func ExtractHTTP(ctx context.Context, header http.Header) context.Context {
+	return _propagator.Extract(ctx, propagation.HeaderCarrier(header))
+}
+
+// headerCarrier adapts the header of a message to the propagation.TextMapCarrier interface.
+type headerCarrier nats.Header
+
+func (c headerCarrier) Get(key string) string {
+	return nats.Header(c).Get(key)
+}
+
+func (c headerCarrier) Set(key, value string) {
+	nats.Header(c).Set(key, value)
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// memoryExporter keeps its spans when the provider shuts down, so they can be asserted once the
+// runners stop.
+type memoryExporter struct {
+	*tracetest.InMemoryExporter
+}
+
+func (memoryExporter) Shutdown(_ context.Context) error {
+	return nil
+}
+
+// initSpanProcessor returns the option registering the exporter with the given name. Spans are
+// sent in batches to the OTLP exporters and right away to the other ones. The none exporter has
+// no option.
+func initSpanProcessor(exporter string, cfg *viper.Viper) (sdkTrace.TracerProviderOption, error) {
+	endpoint := cfg.GetString(common.ConfigTracingEndpointKey)
+	insecure := cfg.GetBool(common.ConfigTracingInsecureKey)
+	timeout := time.Duration(cfg.GetInt(common.ConfigTracingTimeoutKey)) * time.Second
+
+	switch exporter {
+	case ExporterOTLPGRPC:
+		spanExporter, err := initGRPCExporter(endpoint, insecure, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return sdkTrace.WithBatcher(spanExporter), nil
+	case ExporterOTLPHTTP:
+		spanExporter, err := initHTTPExporter(endpoint, insecure, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return sdkTrace.WithBatcher(spanExporter), nil
+	case ExporterStdout:
+		spanExporter, err := stdouttrace.New()
+		if err != nil {
+			return nil, err
+		}
+
+		return sdkTrace.WithSyncer(spanExporter), nil
+	case ExporterMemory:
+		return sdkTrace.WithSyncer(memoryExporter{_memoryExporter}), nil
+	case ExporterNone:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, exporter)
+	}
+}
+
+func initGRPCExporter(endpoint string, insecure bool, timeout time.Duration) (sdkTrace.SpanExporter, error) {
+	opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(endpoint)}
+
+	if timeout > 0 {
+		opts = append(opts, otlptracegrpc.WithTimeout(timeout))
+	}
+
+	if insecure {
+		opts = append(opts, otlptracegrpc.WithInsecure())
+	}
+
+	return otlptracegrpc.New(context.Background(), opts...)
+}
+
+func initHTTPExporter(endpoint string, insecure bool, timeout time.Duration) (sdkTrace.SpanExporter, error) {
+	opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+
+	if timeout > 0 {
+		opts = append(opts, otlptracehttp.WithTimeout(timeout))
+	}
+
+	if insecure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	}
+
+	return otlptracehttp.New(context.Background(), opts...)
+}
+
+func initResource(meta *metadata.Metadata) (*resource.Resource, error) {
+	return resource.Merge(resource.Default(),
+		resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(meta.GetProduct()),
+			semconv.ServiceVersion(meta.GetVersion()),
+			semconv.ServiceNamespace(meta.GetWorkflow()),
+			semconv.ServiceInstanceID(meta.GetProcess()),
+		),
+	)
+}
@@ -0,0 +1,157 @@
+//go:build unit
+
+package tracing_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	"github.com/nats-io/nats.go"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/internal/common"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/metadata"
+	"github.com/konstellation-io/kai-sdk/go-sdk/v2/sdk/tracing"
+)
+
+type SdkTracingTestSuite struct {
+	suite.Suite
+	logger logr.Logger
+}
+
+func (s *SdkTracingTestSuite) SetupSuite() {
+	s.logger = testr.NewWithOptions(s.T(), testr.Options{Verbosity: 1, LogTimestamp: true})
+}
+
+func (s *SdkTracingTestSuite) SetupTest() {
+	tracing.MemoryExporter().Reset()
+}
+
+func (s *SdkTracingTestSuite) TestNewWithConfig_OTLPExporters_ExpectOK() {
+	for _, exporter := range []string{tracing.ExporterOTLPGRPC, tracing.ExporterOTLPHTTP} {
+		// Given
+		config := viper.New()
+		config.Set(common.ConfigTracingExporterKey, exporter)
+		config.Set(common.ConfigTracingEndpointKey, "localhost:4317")
+		config.Set(common.ConfigTracingInsecureKey, true)
+
+		// When
+		sdkTracing, err := tracing.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+
+		// Then
+		s.Require().NoError(err, exporter)
+		s.NotNil(sdkTracing.GetTracer())
+	}
+}
+
+func (s *SdkTracingTestSuite) TestNewWithConfig_UnknownExporter_ExpectError() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigTracingExporterKey, "zipkin")
+
+	// When
+	_, err := tracing.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+
+	// Then
+	s.ErrorIs(err, tracing.ErrUnknownExporter)
+}
+
+func (s *SdkTracingTestSuite) TestNewWithConfig_MemoryExporter_ExpectSpansKeptAfterShutdown() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigTracingExporterKey, tracing.ExporterMemory)
+
+	sdkTracing, err := tracing.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+	s.Require().NoError(err)
+
+	// When
+	ctx, parent := sdkTracing.GetTracer().Start(context.Background(), "parent")
+	_, child := tracing.Tracer().Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	s.Require().NoError(sdkTracing.Shutdown(context.Background()))
+
+	// Then
+	spans := tracing.MemoryExporter().GetSpans()
+	s.Require().Len(spans, 2)
+	s.Equal("child", spans[0].Name)
+	s.Equal(parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+	s.Equal(parent.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+}
+
+func (s *SdkTracingTestSuite) TestNewWithConfig_NoneExporter_ExpectNoSpansExported() {
+	// Given
+	config := viper.New()
+	config.Set(common.ConfigTracingExporterKey, tracing.ExporterNone)
+
+	sdkTracing, err := tracing.NewWithConfig(s.logger, metadata.NewWithConfig(config), config)
+	s.Require().NoError(err)
+
+	// When
+	_, span := sdkTracing.GetTracer().Start(context.Background(), "span")
+	span.End()
+
+	// Then
+	s.NoError(sdkTracing.Flush(context.Background()))
+	s.Empty(tracing.MemoryExporter().GetSpans())
+}
+
+func (s *SdkTracingTestSuite) TestInjectExtract_ExpectSameSpanContext() {
+	// Given
+	spanContext := newSpanContext()
+	header := nats.Header{}
+
+	// When
+	tracing.Inject(trace.ContextWithSpanContext(context.Background(), spanContext), header)
+	extracted := trace.SpanContextFromContext(tracing.Extract(context.Background(), header))
+
+	// Then
+	s.NotEmpty(header.Get("traceparent"))
+	s.Equal(spanContext.TraceID(), extracted.TraceID())
+	s.Equal(spanContext.SpanID(), extracted.SpanID())
+	s.True(extracted.IsRemote())
+}
+
+func (s *SdkTracingTestSuite) TestExtract_NoHeader_ExpectSameContext() {
+	// Given
+	ctx := context.Background()
+
+	// When
+	extracted := tracing.Extract(ctx, nil)
+
+	// Then
+	s.Equal(ctx, extracted)
+	s.False(trace.SpanContextFromContext(extracted).IsValid())
+}
+
+func (s *SdkTracingTestSuite) TestExtractHTTP_ExpectSpanContext() {
+	// Given
+	spanContext := newSpanContext()
+	header := http.Header{}
+	header.Set("Traceparent", fmt.Sprintf("00-%s-%s-01", spanContext.TraceID(), spanContext.SpanID()))
+
+	// When
+	extracted := trace.SpanContextFromContext(tracing.ExtractHTTP(context.Background(), header))
+
+	// Then
+	s.Equal(spanContext.TraceID(), extracted.TraceID())
+}
+
+func newSpanContext() trace.SpanContext {
+	return trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x01, 0x02, 0x03},
+		SpanID:     trace.SpanID{0x04, 0x05},
+		TraceFlags: trace.FlagsSampled,
+	})
+}
+
+func TestSdkTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(SdkTracingTestSuite))
+}